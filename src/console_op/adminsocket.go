@@ -0,0 +1,90 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to serve the admin and debug routes on a
+//  unix socket only reachable from inside the operator pod
+
+package main
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Path of the unix socket the admin routes are served on - when set the
+// admin routes are no longer served on the tcp port
+var adminSocket string = ""
+
+// Router for the admin routes when they are served on the socket
+var adminRouter = chi.NewRouter()
+
+// Get the router the admin routes are put on
+func getAdminRouter() chi.Router {
+	if adminSocket == "" {
+		return router
+	}
+	return adminRouter
+}
+
+// Listen on the admin socket, replacing one left behind by an earlier run
+func listenAdminSocket(path string) (net.Listener, error) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	// NOTE: only the user the operator runs as may connect
+	if err = os.Chmod(path, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// Serve the admin routes on the socket if it is configured
+// NOTE: returns nil when there is no admin socket
+func startAdminServer() *http.Server {
+	if adminSocket == "" {
+		return nil
+	}
+	l, err := listenAdminSocket(adminSocket)
+	if err != nil {
+		// NOTE: the admin routes are not on the tcp port either, but that is
+		//  safer than exposing them
+		logError(errInternal, "Unable to listen on the admin socket %s: %s", adminSocket, err)
+		return nil
+	}
+	srv := &http.Server{Handler: adminRouter}
+	go func() {
+		log.Printf("Info: Admin server %s\n", srv.Serve(l))
+	}()
+	log.Printf("Info: console-operator admin API listening on: %s\n", adminSocket)
+	return srv
+}
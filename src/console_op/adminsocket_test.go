@@ -0,0 +1,88 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAdminSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "adminsocket")
+	if err != nil {
+		t.Fatalf("Unable to make a temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "admin.sock")
+
+	// a socket file left behind is replaced
+	if err := ioutil.WriteFile(path, nil, 0600); err != nil {
+		t.Fatalf("Unable to write the stale socket: %s", err)
+	}
+	l, err := listenAdminSocket(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil || fi.Mode().Perm() != 0600 {
+		t.Errorf("Expected the socket to be mode 0600, got: %v %v", fi, err)
+	}
+
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SendResponseJSON(w, http.StatusOK, nil)
+	})}
+	go srv.Serve(l)
+	defer srv.Close()
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return net.Dial("unix", path)
+		},
+	}}
+	resp, err := client.Get("http://localhost/console-operator/info")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected: %d. Got: %d.", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestGetAdminRouter(t *testing.T) {
+	defer func(s string) { adminSocket = s }(adminSocket)
+	adminSocket = ""
+	if getAdminRouter() != router {
+		t.Errorf("Expected the admin routes on the main router")
+	}
+	adminSocket = "/tmp/admin.sock"
+	if getAdminRouter() != adminRouter {
+		t.Errorf("Expected the admin routes on the admin router")
+	}
+}
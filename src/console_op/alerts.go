@@ -0,0 +1,165 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code needed to send alerts when the health of the
+//  processes being watched degrades
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Url to post alerts to - alerts are only sent when this is set
+var alertWebhookURL string = ""
+
+// Global vars for the thresholds that trigger an alert
+var alertCheckPeriodSec int = 60
+var alertZombieWindowMinutes int = 10
+var alertZombieThreshold int = 20
+var alertStuckSessionThreshold int = 10
+var alertExecFailureThreshold int = 3
+
+// Name of this service as reported in alerts
+const alertSource string = "cray-console-operator"
+
+// Struct to hold an alert sent to the webhook
+type processAlert struct {
+	Source    string `json:"source"`
+	Alert     string `json:"alert"`
+	Status    string `json:"status"`
+	Value     int    `json:"value"`
+	Threshold int    `json:"threshold"`
+	Message   string `json:"message"`
+	Time      string `json:"time"`
+}
+
+// The alerts that are currently firing - used so an alert is only sent when
+// the threshold is crossed and again when it is resolved
+var activeAlerts = make(map[string]bool)
+
+// Read the alert configuration from the environment
+func readAlertEnvVars() {
+	readSingleEnvVarString("ALERT_WEBHOOK_URL", &alertWebhookURL)
+	readSingleEnvVarInt("ALERT_CHECK_SEC_FREQ", &alertCheckPeriodSec, 10, 3600)          // 10 sec -> 1 hr
+	readSingleEnvVarInt("ALERT_ZOMBIE_WINDOW_MINUTES", &alertZombieWindowMinutes, 1, 60) // 1 min -> 1 hr
+	readSingleEnvVarInt("ALERT_ZOMBIE_THRESHOLD", &alertZombieThreshold, 1, maxZombieHistory)
+	readSingleEnvVarInt("ALERT_STUCK_SESSION_THRESHOLD", &alertStuckSessionThreshold, 1, 10000)
+	readSingleEnvVarInt("ALERT_EXEC_FAILURE_THRESHOLD", &alertExecFailureThreshold, 1, 100)
+}
+
+// Main loop to periodically check the process health against the thresholds
+func watchProcessHealth(ctx context.Context) {
+	if alertWebhookURL == "" {
+		log.Printf("No alert webhook configured, process health alerts disabled")
+		return
+	}
+
+	for {
+		// count the zombies reaped within the window
+		numZombies := 0
+		windowStart := time.Now().Add(-time.Duration(alertZombieWindowMinutes) * time.Minute)
+		for _, zp := range getZombieHistory() {
+			if reaped, err := time.Parse(time.RFC3339, zp.Reaped); err == nil && reaped.After(windowStart) {
+				numZombies++
+			}
+		}
+		checkAlertThreshold("zombies", numZombies, alertZombieThreshold,
+			fmt.Sprintf("%d zombie processes reaped in the last %d minutes", numZombies, alertZombieWindowMinutes))
+
+		// count the current console session problems
+		sessionsMutex.Lock()
+		numStuck := len(sessionProblems)
+		numExec := numExecFailures
+		sessionsMutex.Unlock()
+		checkAlertThreshold("stucksessions", numStuck, alertStuckSessionThreshold,
+			fmt.Sprintf("%d stuck or duplicate console sessions in console-node pods", numStuck))
+		checkAlertThreshold("execfailures", numExec, alertExecFailureThreshold,
+			fmt.Sprintf("%d consecutive failures to exec into console-node pods", numExec))
+
+		// check the age of the mountain console key against the policy
+		if created := getKeyCreated(); keyMaxAgeDays > 0 && !created.IsZero() {
+			ageDays := keyAgeDays(created, time.Now())
+			checkAlertThreshold("keyage", ageDays, keyMaxAgeDays,
+				fmt.Sprintf("The mountain console key is %d days old", ageDays))
+		}
+
+		if !sleepCtx(ctx, time.Duration(alertCheckPeriodSec)*time.Second) {
+			log.Printf("Stopping process health alert checks")
+			return
+		}
+	}
+}
+
+// Send an alert if a value crossed its threshold in either direction
+func checkAlertThreshold(name string, value, threshold int, msg string) {
+	firing := value >= threshold
+	if firing == activeAlerts[name] {
+		// nothing changed
+		return
+	}
+
+	status := "resolved"
+	if firing {
+		status = "firing"
+	}
+	alert := processAlert{
+		Source:    alertSource,
+		Alert:     name,
+		Status:    status,
+		Value:     value,
+		Threshold: threshold,
+		Message:   msg,
+		Time:      formatTime(time.Now()),
+	}
+	log.Printf("Process health alert %s %s: %s", name, status, msg)
+	if sendAlert(alert) {
+		// NOTE: only record the new state if the alert was delivered so a
+		//  failed send will be retried on the next check
+		activeAlerts[name] = firing
+	}
+}
+
+// Post an alert to the webhook
+func sendAlert(alert processAlert) bool {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		logError(errAlert, "Error marshalling alert: %s", err)
+		return false
+	}
+	_, rc, err := postURL(alertWebhookURL, data, nil)
+	if err != nil {
+		logError(errAlert, "Error sending alert to %s: %s", alertWebhookURL, err)
+		return false
+	}
+	if rc >= 300 {
+		log.Printf("Alert webhook %s returned unexpected response code: %d", alertWebhookURL, rc)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// Webhook that records the alerts posted to it
+type alertWebhookMock struct {
+	mu     sync.Mutex
+	alerts []processAlert
+	status int
+}
+
+func (m *alertWebhookMock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var alert processAlert
+	if err := json.NewDecoder(r.Body).Decode(&alert); err == nil {
+		m.alerts = append(m.alerts, alert)
+	}
+	w.WriteHeader(m.status)
+}
+
+func (m *alertWebhookMock) received() []processAlert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]processAlert{}, m.alerts...)
+}
+
+func TestCheckAlertThreshold(t *testing.T) {
+	hook := &alertWebhookMock{status: http.StatusOK}
+	srv := httptest.NewServer(hook)
+	defer srv.Close()
+	oldURL, oldActive := alertWebhookURL, activeAlerts
+	defer func() { alertWebhookURL, activeAlerts = oldURL, oldActive }()
+	alertWebhookURL = srv.URL
+	activeAlerts = make(map[string]bool)
+
+	// below the threshold nothing is sent
+	checkAlertThreshold("zombies", 5, 10, "5 zombies")
+	if n := len(hook.received()); n != 0 {
+		t.Errorf("Expected no alerts below the threshold. Got: %d.", n)
+	}
+
+	// crossing the threshold fires once
+	checkAlertThreshold("zombies", 10, 10, "10 zombies")
+	checkAlertThreshold("zombies", 12, 10, "12 zombies")
+	alerts := hook.received()
+	if len(alerts) != 1 {
+		t.Fatalf("Expected 1 alert while firing. Got: %d.", len(alerts))
+	}
+	if a := alerts[0]; a.Alert != "zombies" || a.Status != "firing" || a.Value != 10 ||
+		a.Threshold != 10 || a.Source != alertSource || a.Message != "10 zombies" {
+		t.Errorf("Unexpected alert: %+v", a)
+	}
+
+	// dropping back below resolves once
+	checkAlertThreshold("zombies", 3, 10, "3 zombies")
+	checkAlertThreshold("zombies", 2, 10, "2 zombies")
+	alerts = hook.received()
+	if len(alerts) != 2 || alerts[1].Status != "resolved" || alerts[1].Value != 3 {
+		t.Errorf("Expected a single resolved alert. Got: %+v", alerts)
+	}
+	if activeAlerts["zombies"] {
+		t.Errorf("Expected the zombies alert to no longer be active")
+	}
+}
+
+func TestCheckAlertThresholdRetry(t *testing.T) {
+	hook := &alertWebhookMock{status: http.StatusInternalServerError}
+	srv := httptest.NewServer(hook)
+	defer srv.Close()
+	oldURL, oldActive := alertWebhookURL, activeAlerts
+	defer func() { alertWebhookURL, activeAlerts = oldURL, oldActive }()
+	alertWebhookURL = srv.URL
+	activeAlerts = make(map[string]bool)
+
+	// a failed delivery is sent again on the next check
+	checkAlertThreshold("execfailures", 3, 3, "3 exec failures")
+	if activeAlerts["execfailures"] {
+		t.Errorf("Expected the alert not to be recorded as firing after a failed send")
+	}
+	hook.mu.Lock()
+	hook.status = http.StatusOK
+	hook.mu.Unlock()
+	checkAlertThreshold("execfailures", 3, 3, "3 exec failures")
+	if n := len(hook.received()); n != 2 || !activeAlerts["execfailures"] {
+		t.Errorf("Expected the alert to be retried and recorded. Got %d sends.", n)
+	}
+	checkAlertThreshold("execfailures", 4, 3, "4 exec failures")
+	if n := len(hook.received()); n != 2 {
+		t.Errorf("Expected no more alerts once delivered. Got %d sends.", n)
+	}
+}
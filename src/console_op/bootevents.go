@@ -0,0 +1,133 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to pull structured boot events out of the
+//  console log of a node
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Boot event types
+const bootEventPostCode string = "postcode"
+const bootEventKernel string = "kernel"
+const bootEventDracutFailure string = "dracutfailure"
+const bootEventSystemdTarget string = "systemdtarget"
+
+// Console output that marks a boot event, the first group is the detail
+var bootEventPatterns = []struct {
+	eventType string
+	regex     *regexp.Regexp
+}{
+	{bootEventPostCode, regexp.MustCompile(`(?i)\bpost code\s*[:=]?\s*((?:0x)?[0-9a-f]{2,4})\b`)},
+	{bootEventKernel, regexp.MustCompile(`Linux version (\S+)`)},
+	{bootEventDracutFailure, regexp.MustCompile(`(?i)(dracut.*(?:warning|error|fail).*|.*entering emergency mode.*)`)},
+	{bootEventSystemdTarget, regexp.MustCompile(`Reached target (.+?)\.?\s*$`)},
+}
+
+// BootEvent - a boot event found in a console log
+type BootEvent struct {
+	Time   string `json:"time"`
+	Type   string `json:"type"`
+	Detail string `json:"detail"`
+}
+
+// GetNodeEventsResponse - the boot events of a node
+type GetNodeEventsResponse struct {
+	Xname  string      `json:"xname"`
+	Events []BootEvent `json:"events"`
+}
+
+// Find the boot events in a console log, optionally only of one type
+func parseBootEvents(in io.Reader, noTime time.Time, eventType string) ([]BootEvent, error) {
+	events := []BootEvent{}
+	err := scanConsoleLog(in, noTime, func(t time.Time, line string) {
+		for _, p := range bootEventPatterns {
+			if eventType != "" && p.eventType != eventType {
+				continue
+			}
+			if m := p.regex.FindStringSubmatch(line); m != nil {
+				events = append(events, BootEvent{
+					Time:   formatTime(t),
+					Type:   p.eventType,
+					Detail: redactLine(strings.TrimSpace(m[1])),
+				})
+				return
+			}
+		}
+	})
+	return events, err
+}
+
+// Report the boot events in the console log of a node, `?type=` limits
+// them to one type of event
+func (LogManager) doGetNodeEvents(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	eventType := r.URL.Query().Get("type")
+	switch eventType {
+	case "", bootEventPostCode, bootEventKernel, bootEventDracutFailure, bootEventSystemdTarget:
+	default:
+		sendJSONError(w, http.StatusBadRequest,
+			fmt.Sprintf("Invalid type %s, expected %s, %s, %s, or %s", eventType,
+				bootEventPostCode, bootEventKernel, bootEventDracutFailure, bootEventSystemdTarget))
+		return
+	}
+
+	xname := chi.URLParam(r, "xname")
+	f, err := os.Open(consoleLogPath(xname))
+	if err != nil {
+		sendJSONError(w, http.StatusNotFound,
+			fmt.Sprintf("No console log for %s", xname))
+		return
+	}
+	defer f.Close()
+	noTime := time.Now()
+	if fi, err := f.Stat(); err == nil {
+		noTime = fi.ModTime()
+	}
+	events, err := parseBootEvents(f, noTime, eventType)
+	if err != nil {
+		sendJSONError(w, http.StatusInternalServerError,
+			fmt.Sprintf("Unable to read the console log for %s: %s", xname, err))
+		return
+	}
+	SendResponseJSON(w, http.StatusOK, GetNodeEventsResponse{Xname: xname, Events: events})
+}
@@ -0,0 +1,67 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseBootEvents(t *testing.T) {
+	in := strings.Join([]string{
+		"2026-01-02 03:04:05 POST Code: 0xA2",
+		"[    0.000000] Linux version 5.14.21-150400.24.46-default (geeko@buildhost) (gcc)",
+		"[   12.345678] dracut-initqueue[812]: Warning: dracut-initqueue: timeout, still waiting for following initqueue hooks:",
+		"Generating \"/run/initramfs/rdsosreport.txt\"",
+		"[   13.000000] systemd[1]: Entering emergency mode. Exit the shell to continue.",
+		"[  OK  ] Reached target Basic System.",
+		"nid000001 login:",
+	}, "\n")
+	events, err := parseBootEvents(strings.NewReader(in), time.Now(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []BootEvent{
+		{"2026-01-02T03:04:05Z", bootEventPostCode, "0xA2"},
+		{"2026-01-02T03:04:05Z", bootEventKernel, "5.14.21-150400.24.46-default"},
+		{"2026-01-02T03:04:05Z", bootEventDracutFailure, "dracut-initqueue[812]: Warning: dracut-initqueue: timeout, still waiting for following initqueue hooks:"},
+		{"2026-01-02T03:04:05Z", bootEventDracutFailure, "[   13.000000] systemd[1]: Entering emergency mode. Exit the shell to continue."},
+		{"2026-01-02T03:04:05Z", bootEventSystemdTarget, "Basic System"},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected: %d. Got: %d: %v.", len(expected), len(events), events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("Expected: %v. Got: %v.", expected[i], events[i])
+		}
+	}
+
+	targets, _ := parseBootEvents(strings.NewReader(in), time.Now(), bootEventSystemdTarget)
+	if len(targets) != 1 {
+		t.Errorf("Expected: 1. Got: %d.", len(targets))
+	}
+}
@@ -0,0 +1,200 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to roll out large changes of the per pod
+//  targets to a single canary pod before the rest of the console-node pods
+
+package main
+
+import (
+	"log"
+)
+
+// Global vars to control the staged roll out of per pod targets
+var canaryTargets bool = false
+var canaryChangePercent int = 50
+var canaryStableChecks int = 2
+var canaryMaxChecks int = 10
+
+// State of a canary roll out in progress
+// NOTE: only accessed from the hardware update thread
+type canaryState struct {
+	PodName   string      // pod trying out the new targets
+	Desired   []podTarget // targets to roll out to all pods
+	LastMtn   int         // mountain nodes held by the canary at the last check
+	LastRvr   int         // river nodes held by the canary at the last check
+	NumStable int         // consecutive checks the canary has been stable
+	NumChecks int         // total checks of the canary
+}
+
+var canary canaryState
+
+// Check if the change between two targets is larger than the given percent
+func bigTargetChange(curr, next, pct int) bool {
+	diff := next - curr
+	if diff < 0 {
+		diff = -diff
+	}
+	if curr <= 0 {
+		return diff > 0
+	}
+	return diff*100 > curr*pct
+}
+
+// Work out the targets to apply when moving from the current to the desired
+// targets.  If any existing pod has a large change, only the first of those
+// pods is given its new targets and the others keep their current targets.
+// New pods always get the desired targets.  Returns the name of the canary
+// pod or "" if the desired targets can be applied to all pods.
+func stageCanaryTargets(current, desired []podTarget, pct int) ([]podTarget, string) {
+	currMap := make(map[string]podTarget)
+	for _, pt := range current {
+		currMap[pt.PodName] = pt
+	}
+
+	canaryPod := ""
+	applied := make([]podTarget, 0, len(desired))
+	for _, pt := range desired {
+		curr, found := currMap[pt.PodName]
+		if !found {
+			applied = append(applied, pt)
+			continue
+		}
+		isBig := bigTargetChange(curr.TargetNumMtnNodes, pt.TargetNumMtnNodes, pct) ||
+			bigTargetChange(curr.TargetNumRvrNodes, pt.TargetNumRvrNodes, pct)
+		if isBig && canaryPod == "" {
+			canaryPod = pt.PodName
+			applied = append(applied, pt)
+		} else if isBig {
+			// hold this pod at the current targets
+			curr.Capacity = pt.Capacity
+			applied = append(applied, curr)
+		} else {
+			applied = append(applied, pt)
+		}
+	}
+	if canaryPod == "" {
+		return desired, ""
+	}
+	return applied, canaryPod
+}
+
+// Check if two sets of targets are the same
+func sameTargets(a, b []podTarget) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Decide which targets to apply given the newly calculated ones, running a
+// canary roll out when the change is large
+func (nm NodeManager) canaryPodTargets(desired []podTarget) []podTarget {
+	current := getPodTargets()
+	if len(current) == 0 {
+		// nothing running on per pod targets yet
+		return desired
+	}
+
+	// start over if the targets moved since the canary started
+	if canary.PodName != "" && !sameTargets(canary.Desired, desired) {
+		log.Printf("Targets changed during canary on pod %s, restarting the canary", canary.PodName)
+		canary = canaryState{}
+	}
+
+	// check on the canary in progress
+	if canary.PodName != "" {
+		canary.NumChecks++
+		if nm.canaryStable() {
+			canary.NumStable++
+		} else {
+			canary.NumStable = 0
+		}
+		if canary.NumStable >= canaryStableChecks {
+			log.Printf("Canary pod %s is stable, applying new targets to all pods", canary.PodName)
+			canary = canaryState{}
+			return desired
+		}
+		if canary.NumChecks >= canaryMaxChecks {
+			// give up and put the canary back where it was
+			log.Printf("Warning: canary pod %s did not stabilize on the new targets, rolling back", canary.PodName)
+			canary = canaryState{}
+			var applied []podTarget = nil
+			for _, pt := range desired {
+				for _, curr := range current {
+					if curr.PodName == pt.PodName {
+						pt = curr
+						break
+					}
+				}
+				applied = append(applied, pt)
+			}
+			return applied
+		}
+		log.Printf("Waiting on canary pod %s, stable checks: %d of %d", canary.PodName, canary.NumStable, canaryStableChecks)
+		applied, _ := stageCanaryTargets(current, desired, canaryChangePercent)
+		return applied
+	}
+
+	// start a new canary if this is a large change
+	applied, canaryPod := stageCanaryTargets(current, desired, canaryChangePercent)
+	if canaryPod != "" {
+		log.Printf("Large target change, trying the new targets on canary pod %s first", canaryPod)
+		canary = canaryState{PodName: canaryPod, Desired: desired, LastMtn: -1, LastRvr: -1}
+	}
+	return applied
+}
+
+// Check if the canary pod is running and the nodes it holds have settled
+// inside the targets it was given
+func (nm NodeManager) canaryStable() bool {
+	podNames, err := nm.k8Service.getConsoleNodePods()
+	if err != nil {
+		return false
+	}
+	inv, err := getDataInventory("")
+	if err != nil {
+		return false
+	}
+	resp := calcPodAssignments(podNames, canary.Desired, inv)
+	for _, pa := range resp.Pods {
+		if pa.PodName != canary.PodName {
+			continue
+		}
+		stable := pa.Running &&
+			pa.AcquiredMtnNodes <= pa.TargetNumMtnNodes &&
+			pa.AcquiredRvrNodes <= pa.TargetNumRvrNodes &&
+			pa.AcquiredMtnNodes == canary.LastMtn &&
+			pa.AcquiredRvrNodes == canary.LastRvr
+		canary.LastMtn = pa.AcquiredMtnNodes
+		canary.LastRvr = pa.AcquiredRvrNodes
+		return stable
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"testing"
+)
+
+func TestStageCanaryTargets(t *testing.T) {
+	current := []podTarget{
+		{PodName: "p0", TargetNumMtnNodes: 100, TargetNumRvrNodes: 10},
+		{PodName: "p1", TargetNumMtnNodes: 100, TargetNumRvrNodes: 10},
+	}
+
+	// small changes go straight through
+	desired := []podTarget{
+		{PodName: "p0", TargetNumMtnNodes: 120, TargetNumRvrNodes: 12},
+		{PodName: "p1", TargetNumMtnNodes: 80, TargetNumRvrNodes: 8},
+	}
+	applied, canaryPod := stageCanaryTargets(current, desired, 50)
+	if canaryPod != "" || !sameTargets(applied, desired) {
+		t.Errorf("Expected: %v with no canary. Got: %v with canary %s.", desired, applied, canaryPod)
+	}
+
+	// a large change is only applied to the first pod, new pods get the new targets
+	desired = []podTarget{
+		{PodName: "p0", TargetNumMtnNodes: 200, TargetNumRvrNodes: 10},
+		{PodName: "p1", TargetNumMtnNodes: 200, TargetNumRvrNodes: 10},
+		{PodName: "p2", TargetNumMtnNodes: 200, TargetNumRvrNodes: 10},
+	}
+	expected := []podTarget{
+		{PodName: "p0", TargetNumMtnNodes: 200, TargetNumRvrNodes: 10},
+		{PodName: "p1", TargetNumMtnNodes: 100, TargetNumRvrNodes: 10},
+		{PodName: "p2", TargetNumMtnNodes: 200, TargetNumRvrNodes: 10},
+	}
+	applied, canaryPod = stageCanaryTargets(current, desired, 50)
+	if canaryPod != "p0" {
+		t.Errorf("Expected: p0. Got: %s.", canaryPod)
+	}
+	if !sameTargets(applied, expected) {
+		t.Errorf("Expected: %v. Got: %v.", expected, applied)
+	}
+}
@@ -0,0 +1,171 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the admin commands built into the operator binary
+//  that talk to the running service through its api
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// Base url of the running service the admin commands talk to
+var cliBaseURL string = "http://localhost:26777/console-operator"
+
+const cliUsage string = `Usage: console_operator <command> [args]
+
+Commands:
+  status                 show the health of the running service
+  nodes                  list the nodes with console logs and their console-node pods
+  tail [-n N] [-f] XNAME show the end of the console log of a node
+`
+
+// Run an admin command, returning the exit code
+func runCLI(args []string, out io.Writer) int {
+	if v := os.Getenv("CONSOLE_OPERATOR_URL"); v != "" {
+		cliBaseURL = v
+	}
+	// NOTE: the http helpers log every call, that is only noise here
+	log.SetOutput(ioutil.Discard)
+
+	var err error
+	switch args[0] {
+	case "status":
+		err = cliStatus(out)
+	case "nodes":
+		err = cliNodes(out)
+	case "tail":
+		err = cliTail(args[1:], out)
+	case "help", "-h", "--help":
+		fmt.Fprint(out, cliUsage)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %s\n\n%s", args[0], cliUsage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	return 0
+}
+
+// Get a path from the running service, failing on anything but a 200
+func cliGet(path string) ([]byte, http.Header, error) {
+	resp, err := http.Get(cliBaseURL + path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("%s returned %d: %s", path, resp.StatusCode, body)
+	}
+	return body, resp.Header, nil
+}
+
+// Show the health of the service one setting per line
+func cliStatus(out io.Writer) error {
+	body, _, err := cliGet("/health")
+	if err != nil {
+		return err
+	}
+	var health map[string]interface{}
+	if err = json.Unmarshal(body, &health); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(health))
+	for k := range health {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	tw := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	for _, k := range keys {
+		fmt.Fprintf(tw, "%s\t%v\n", k, health[k])
+	}
+	return tw.Flush()
+}
+
+// List the nodes with console logs and the pods watching them
+func cliNodes(out io.Writer) error {
+	body, _, err := cliGet("/v1/logs/locations")
+	if err != nil {
+		return err
+	}
+	var locs LogLocationsResponse
+	if err = json.Unmarshal(body, &locs); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "XNAME\tPOD\tSIZE\tMODIFIED\n")
+	for _, n := range locs.Nodes {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", n.Xname, n.PodName, n.Size, n.Modified)
+	}
+	return tw.Flush()
+}
+
+// Show the end of the console log of a node, following it with -f
+func cliTail(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("tail", flag.ContinueOnError)
+	lines := fs.Int("n", 20, "number of lines to show")
+	follow := fs.Bool("f", false, "keep showing new output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("tail needs the xname of a node")
+	}
+	xname := fs.Arg(0)
+
+	body, hdr, err := cliGet(fmt.Sprintf("/v1/logs/%s/tail?lines=%d", xname, *lines))
+	for {
+		if err != nil {
+			return err
+		}
+		out.Write(body)
+		if !*follow {
+			return nil
+		}
+		offset, perr := strconv.ParseInt(hdr.Get(logOffsetHeader), 10, 64)
+		if perr != nil {
+			return fmt.Errorf("No log offset returned by the service")
+		}
+		time.Sleep(time.Second)
+		body, hdr, err = cliGet(fmt.Sprintf("/v1/logs/%s/tail?offset=%d", xname, offset))
+	}
+}
@@ -0,0 +1,84 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLastLinesStart(t *testing.T) {
+	data := []byte("one\ntwo\nthree\n")
+	if got := string(data[lastLinesStart(data, 2):]); got != "two\nthree\n" {
+		t.Errorf("Expected: two three. Got: %s.", got)
+	}
+	if got := lastLinesStart(data, 10); got != 0 {
+		t.Errorf("Expected: 0. Got: %d.", got)
+	}
+}
+
+func TestRunCLI(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/console-operator/health":
+			w.Write([]byte(`{"consoles":"12","isleader":"true"}`))
+		case "/console-operator/v1/logs/locations":
+			w.Write([]byte(`{"dir":"/var/log/conman","nodes":[{"xname":"x1000c0s0b0n0","podname":"cray-console-node-0","size":10}]}`))
+		case "/console-operator/v1/logs/x1000c0s0b0n0/tail":
+			w.Header().Set(logOffsetHeader, "10")
+			w.Write([]byte("login:\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	os.Setenv("CONSOLE_OPERATOR_URL", srv.URL+"/console-operator")
+	defer os.Unsetenv("CONSOLE_OPERATOR_URL")
+	defer log.SetOutput(os.Stderr)
+
+	var out bytes.Buffer
+	if rc := runCLI([]string{"status"}, &out); rc != 0 || !strings.Contains(out.String(), "consoles") {
+		t.Errorf("Expected the health. Got: %d %s.", rc, out.String())
+	}
+	out.Reset()
+	if rc := runCLI([]string{"nodes"}, &out); rc != 0 || !strings.Contains(out.String(), "cray-console-node-0") {
+		t.Errorf("Expected the nodes. Got: %d %s.", rc, out.String())
+	}
+	out.Reset()
+	if rc := runCLI([]string{"tail", "-n", "5", "x1000c0s0b0n0"}, &out); rc != 0 || out.String() != "login:\n" {
+		t.Errorf("Expected the tail. Got: %d %s.", rc, out.String())
+	}
+	if rc := runCLI([]string{"tail", "x9"}, &out); rc != 1 {
+		t.Errorf("Expected: 1. Got: %d.", rc)
+	}
+	if rc := runCLI([]string{"bogus"}, &out); rc != 2 {
+		t.Errorf("Expected: 2. Got: %d.", rc)
+	}
+}
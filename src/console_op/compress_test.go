@@ -0,0 +1,108 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGzipResponses(t *testing.T) {
+	oldCompression := responseCompression
+	defer func() { responseCompression = oldCompression }()
+	responseCompression = true
+	h := gzipResponses(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/json" {
+			SendResponseJSON(w, http.StatusOK, map[string]string{"podname": "cray-console-node-1"})
+		} else {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("console text\n"))
+		}
+	}))
+
+	// json is compressed when gzip is accepted
+	req := httptest.NewRequest(http.MethodGet, "/json", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Expected: gzip. Got: %s.", w.Header().Get("Content-Encoding"))
+	}
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("Unable to read the gzip body: %s", err)
+	}
+	body, _ := ioutil.ReadAll(gz)
+	if string(body) != "{\"podname\":\"cray-console-node-1\"}\n" {
+		t.Errorf("Unexpected body: %s", body)
+	}
+
+	// not compressed when the client does not accept it
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/json", nil))
+	if w.Header().Get("Content-Encoding") != "" {
+		t.Errorf("Expected no encoding, got: %s", w.Header().Get("Content-Encoding"))
+	}
+
+	// text is never compressed
+	req = httptest.NewRequest(http.MethodGet, "/text", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Header().Get("Content-Encoding") != "" || w.Body.String() != "console text\n" {
+		t.Errorf("Expected the text uncompressed, got: %s", w.Body.String())
+	}
+
+	// nothing is compressed when turned off
+	responseCompression = false
+	req = httptest.NewRequest(http.MethodGet, "/json", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Header().Get("Content-Encoding") != "" {
+		t.Errorf("Expected no encoding when disabled, got: %s", w.Header().Get("Content-Encoding"))
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := map[string]bool{
+		"":              false,
+		"gzip":          true,
+		"deflate, gzip": true,
+		"gzip;q=0.5":    true,
+		"gzip;q=0":      false,
+		"br":            false,
+	}
+	for enc, want := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", enc)
+		if got := acceptsGzip(req); got != want {
+			t.Errorf("Accept-Encoding %q Expected: %t. Got: %t.", enc, want, got)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to keep track of the effective value of every
+//  setting and where it came from
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Where a setting came from
+const configSourceDefault string = "default"
+const configSourceEnv string = "env"
+const configSourceAPI string = "api"
+
+// ConfigSetting - the effective value of a setting
+type ConfigSetting struct {
+	Name    string   `json:"name"`
+	Value   string   `json:"value"`
+	Default string   `json:"default"`
+	Source  string   `json:"source"`
+	Min     *int     `json:"min,omitempty"`
+	Max     *int     `json:"max,omitempty"`
+	Allowed []string `json:"allowed,omitempty"`
+
+	// gets the current value
+	value func() string
+}
+
+// The settings by env var name
+var configSettings map[string]*ConfigSetting = make(map[string]*ConfigSetting)
+var configSettingsMutex sync.Mutex
+
+// Record a setting before its env var is read
+// NOTE: the current value is taken as the default
+func registerSetting(name string, value func() string, s ConfigSetting) {
+	s.Name = name
+	s.value = value
+	s.Default = value()
+	s.Source = configSourceDefault
+	if os.Getenv(name) != "" {
+		s.Source = configSourceEnv
+	}
+	configSettingsMutex.Lock()
+	configSettings[name] = &s
+	configSettingsMutex.Unlock()
+}
+
+// Record an int setting with its allowed range
+func registerIntSetting(name string, outVar *int, minVal, maxVal int) {
+	registerSetting(name, func() string { return strconv.Itoa(*outVar) }, ConfigSetting{Min: &minVal, Max: &maxVal})
+}
+
+// Record that a setting was changed through the api
+func setConfigSource(name, source string) {
+	configSettingsMutex.Lock()
+	defer configSettingsMutex.Unlock()
+	if s, found := configSettings[name]; found {
+		s.Source = source
+	}
+}
+
+// Get all the settings with their current values
+func getConfigSettings() []ConfigSetting {
+	configSettingsMutex.Lock()
+	defer configSettingsMutex.Unlock()
+	settings := []ConfigSetting{}
+	for _, s := range configSettings {
+		cs := *s
+		cs.Value = s.value()
+		settings = append(settings, cs)
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Name < settings[j].Name })
+	return settings
+}
+
+// Function to read a single TRUE/FALSE env variable into a variable
+func readSingleEnvVarBool(envVar string, outVar *bool) {
+	registerSetting(envVar, func() string { return strconv.FormatBool(*outVar) },
+		ConfigSetting{Allowed: []string{"TRUE", "FALSE"}})
+	switch v := os.Getenv(envVar); v {
+	case "TRUE":
+		*outVar = true
+	case "FALSE":
+		*outVar = false
+	}
+}
+
+// Function to read a single string env variable into a variable, limited
+// to the allowed values if any are given
+func readSingleEnvVarString(envVar string, outVar *string, allowed ...string) {
+	registerSetting(envVar, func() string { return *outVar }, ConfigSetting{Allowed: allowed})
+	v := os.Getenv(envVar)
+	if v == "" {
+		return
+	}
+	if len(allowed) > 0 {
+		ok := false
+		for _, a := range allowed {
+			ok = ok || v == a
+		}
+		if !ok {
+			log.Printf("Unsupported %s: %s, using %s", envVar, v, *outVar)
+			return
+		}
+	}
+	*outVar = v
+}
+
+// Report every setting with its value, where it came from, and what it is
+// allowed to be
+func doGetConfig(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	SendResponseJSON(w, http.StatusOK, getConfigSettings())
+}
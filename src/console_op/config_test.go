@@ -0,0 +1,68 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestConfigSettings(t *testing.T) {
+	defer func() { configSettings = make(map[string]*ConfigSetting) }()
+	testInt := 10
+	testBool := false
+	testStr := "hash"
+	os.Setenv("TEST_CONFIG_INT", "5000")
+	os.Setenv("TEST_CONFIG_STR", "bogus")
+	defer os.Unsetenv("TEST_CONFIG_INT")
+	defer os.Unsetenv("TEST_CONFIG_STR")
+
+	readSingleEnvVarInt("TEST_CONFIG_INT", &testInt, 1, 100)
+	readSingleEnvVarBool("TEST_CONFIG_BOOL", &testBool)
+	readSingleEnvVarString("TEST_CONFIG_STR", &testStr, "hash", "cabinet")
+	if testStr != "hash" {
+		t.Errorf("Expected: hash. Got: %s.", testStr)
+	}
+
+	settings := make(map[string]ConfigSetting)
+	for _, s := range getConfigSettings() {
+		settings[s.Name] = s
+	}
+	if s := settings["TEST_CONFIG_INT"]; s.Value != "100" || s.Default != "10" || s.Source != configSourceEnv || *s.Max != 100 {
+		t.Errorf("Expected: 100 10 env 100. Got: %s %s %s %d.", s.Value, s.Default, s.Source, *s.Max)
+	}
+	if s := settings["TEST_CONFIG_BOOL"]; s.Value != "false" || s.Source != configSourceDefault {
+		t.Errorf("Expected: false default. Got: %s %s.", s.Value, s.Source)
+	}
+
+	// changed through the api
+	testInt = 50
+	setConfigSource("TEST_CONFIG_INT", configSourceAPI)
+	for _, s := range getConfigSettings() {
+		if s.Name == "TEST_CONFIG_INT" && (s.Value != "50" || s.Source != configSourceAPI) {
+			t.Errorf("Expected: 50 api. Got: %s %s.", s.Value, s.Source)
+		}
+	}
+}
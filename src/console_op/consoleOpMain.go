@@ -1,7 +1,7 @@
 //
 //  MIT License
 //
-//  (C) Copyright 2021-2024 Hewlett Packard Enterprise Development LP
+//  (C) Copyright 2021-2024, 2026 Hewlett Packard Enterprise Development LP
 //
 //  Permission is hereby granted, free of charge, to any person obtaining a
 //  copy of this software and associated documentation files (the "Software"),
@@ -35,6 +35,8 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
@@ -66,6 +68,34 @@ var totalMtnNodes int = -1
 var maxMtnNodesPerPod int = 750
 var maxRvrNodesPerPod int = 2000
 
+// Bounds on the max nodes per pod however they are set - env, api or an
+// imported state
+const minNodesPerPodLimit int = 5
+const maxMtnNodesPerPodLimit int = 1500
+const maxRvrNodesPerPodLimit int = 4000
+
+// Site configured bounds on the number of console-node pods
+var minNodePods int = 1
+var maxNodePods int = 1000
+
+// Global var to allow scaling console-node to zero pods when hsm reports no
+// nodes on the system - off by default since hsm may just not be populated yet
+var scaleToZero bool = false
+
+// Global var to split the nodes between console-node pods in proportion to
+// the capacity of each pod rather than evenly
+// NOTE: this needs a console-node that reads its per pod target file
+var capacityDistribution bool = false
+
+// Global vars to control adding console-node pods when the current pods
+// are using more than the given percent of their cpu or memory limits
+var resourceScaling bool = false
+var scaleCPUPercent int = 80
+var scaleMemPercent int = 80
+
+// Number of console-node pods needed based on resource usage - -1 if unknown
+var resourceNodePods int = -1
+
 // Global var to control how often we check for hardware changes
 var newHardwareCheckPeriodSec int = 30
 var hardwareUpdateTime string = "Unknown"
@@ -77,12 +107,23 @@ var heartbeatCheckPeriodSec int = 15
 // Global var to signal we are shutting down and prevent periodic checks from happening
 var inShutdown bool = false
 
-func updateCachedNodeData(ds DataService, ns NodeService, updateAll bool) (bool, []nodeConsoleInfo) {
+// Maximum time to wait for the background threads to finish on shutdown
+const watcherShutdownTimeout = 10 * time.Second
+
+func updateCachedNodeData(ds DataService, ns NodeService, updateAll bool) (bool, []nodeConsoleInfo, []nodeConsoleInfo) {
 	// return if the console-data update succeeded
 	updateSuccessful := true
 
 	// get the current endpoints from hsm
-	currNodes := ns.getCurrentNodesFromHSM()
+	allNodes := ns.getCurrentNodesFromHSM()
+
+	// only handle the nodes in the shards owned by this replica
+	var currNodes []nodeConsoleInfo = nil
+	for _, n := range allNodes {
+		if nodeInMyShards(n.NodeName) {
+			currNodes = append(currNodes, n)
+		}
+	}
 	currNodesMap := make(map[string]nodeConsoleInfo)
 	for _, n := range currNodes {
 		currNodesMap[n.NodeName] = n
@@ -100,6 +141,11 @@ func updateCachedNodeData(ds DataService, ns NodeService, updateAll bool) (bool,
 	// Find nodes to remove that are in the nodeCache but not in currNodes
 	var removedNodes []nodeConsoleInfo = nil
 	for _, n := range nodeCache {
+		// NOTE: nodes of a shard handed to another replica are just dropped
+		//  from the cache, they are still present in hsm
+		if !nodeInMyShards(n.NodeName) {
+			continue
+		}
 		if _, found := currNodesMap[n.NodeName]; !found {
 			removedNodes = append(removedNodes, n)
 			log.Printf("Removing node: %s", n.String())
@@ -112,6 +158,8 @@ func updateCachedNodeData(ds DataService, ns NodeService, updateAll bool) (bool,
 		nodesToUpdate = currNodes
 		log.Printf("Forcing inventory update of all %d nodes", len(nodesToUpdate))
 	}
+	// NOTE: consoles out of monitoring for maintenance stay out of console-data
+	nodesToUpdate = withoutMaintenanceNodes(nodesToUpdate)
 
 	if len(nodesToUpdate) > 0 {
 		if ok := ds.dataAddNodes(nodesToUpdate); !ok {
@@ -124,7 +172,9 @@ func updateCachedNodeData(ds DataService, ns NodeService, updateAll bool) (bool,
 
 	// remove the nodes from console-data
 	if len(removedNodes) > 0 {
+		checkMassNodeRemoval(len(removedNodes))
 		ds.dataRemoveNodes(removedNodes)
+		removeNodeKeyStatus(removedNodes)
 	} else {
 		log.Printf("No nodes being removed")
 	}
@@ -136,33 +186,51 @@ func updateCachedNodeData(ds DataService, ns NodeService, updateAll bool) (bool,
 
 	// newNodes are returned, not nodesToUpdate because we only want to deploy
 	// 		mountain keys for new nodes, not the during the periodic updateAll.
-	return updateSuccessful, newNodes
+	return updateSuccessful, newNodes, allNodes
 }
 
 // Function to do a hardware update check
 func doHardwareUpdate(ds DataService, ns NodeService, updateAll bool, mountainCredsUpdateChannel chan nodeConsoleInfo) bool {
 	// record the time of the hardware update attempt
-	hardwareUpdateTime = time.Now().Format(time.RFC3339)
+	hardwareUpdateTime = formatTime(time.Now())
 
 	// Update the cache and data in console-data
-	updateSuccessful, newNodes := updateCachedNodeData(ds, ns, updateAll)
+	updateSuccessful, newNodes, allNodes := updateCachedNodeData(ds, ns, updateAll)
 
 	// recalculate the number pods needed and how many assigned to each pod
 	// NOTE: do this every time in case something else made changes on the system
 	//  like number of console-node replicas deployed
+	// NOTE: when sharded the counts are for all of hsm, not just our shards
+	countNodes := nodeCache
+	if shardCount > 1 {
+		countNodes = make(map[string]nodeConsoleInfo)
+		for _, n := range allNodes {
+			countNodes[n.NodeName] = n
+		}
+	}
 	numRvrNodes := 0
 	numMtnNodes := 0
-	for _, v := range nodeCache {
+	for _, v := range countNodes {
 		// update counts of nodes
 		if v.isRiver() {
 			numRvrNodes++
 		} else if v.isMountain() || v.isParadise() {
 			numMtnNodes++
 		} else {
-			log.Printf("Error: unknown node class: %s on node: %s", v.Class, v.NodeName)
+			logError(errHSM, "Error: unknown node class: %s on node: %s", v.Class, v.NodeName)
+		}
+	}
+	// only the owner of the first shard changes the scaling
+	if ownsShard(0) {
+		ns.updateNodeCounts(numMtnNodes, numRvrNodes)
+
+		// let the scheduler know where the hardware is
+		if topologyHints && len(countNodes) > 0 {
+			if frozen, _ := scalingFrozen(time.Now()); !frozen {
+				ns.updatePlacementHints(countNodes)
+			}
 		}
 	}
-	ns.updateNodeCounts(numMtnNodes, numRvrNodes)
 
 	// Update mountain node keys
 	if numMtnNodes > 0 {
@@ -181,21 +249,28 @@ func doHardwareUpdate(ds DataService, ns NodeService, updateAll bool, mountainCr
 }
 
 // Main loop for console-operator stuff
-func watchHardware(ds DataService, ns NodeService) {
+func watchHardware(ctx context.Context, ds DataService, ns NodeService) {
 	// every once in a while send all inventory to update to make sure console-data
 	// is actually up to date
 	forceUpdateCnt := 0
 
 	// setup routine for pushing mountain keys
 	mountainCredsUpdateChannel := make(chan nodeConsoleInfo, 100)
-	go doMountainCredsUpdates(mountainCredsUpdateChannel)
+	go doMountainCredsUpdates(ctx, mountainCredsUpdateChannel)
+
+	// pick up the key deployments the previous instance did not finish
+	for _, n := range handoffKeyNodes {
+		mountainCredsUpdateChannel <- n
+	}
+	handoffKeyNodes = nil
 
 	// loop forever looking for updates to the hardware
 	for {
 		// do a check of the current hardware
 		// NOTE: if the service is currently in the process of shutting down
-		//  do not perform the hardware update check
-		if !inShutdown {
+		//  or has handed off to a new instance do not perform the hardware
+		//  update check
+		if !reconcileStopped() {
 			// do the update
 			updateSuccessful := doHardwareUpdate(ds, ns, forceUpdateCnt == 0, mountainCredsUpdateChannel)
 
@@ -214,18 +289,34 @@ func watchHardware(ds DataService, ns NodeService) {
 
 		// There are times we want to wait for a little before starting a new
 		// process - ie killproc may get caught trying to kill all instances
-		time.Sleep(time.Duration(newHardwareCheckPeriodSec) * time.Second)
+		if !sleepCtx(ctx, time.Duration(newHardwareCheckPeriodSec)*time.Second) {
+			log.Printf("Stopping hardware watch")
+			return
+		}
+	}
+}
+
+// Wait for the given duration - returns false if the context was cancelled
+// before the time was up
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
 	}
 }
 
 // Function to read a single env variable into a variable with min/max checks
 func readSingleEnvVarInt(envVar string, outVar *int, minVal, maxVal int) {
+	registerIntSetting(envVar, outVar, minVal, maxVal)
+
 	// get the env var for maximum number of mountain nodes per pod
 	if v := os.Getenv(envVar); v != "" {
 		log.Printf("Found %s env var: %s", envVar, v)
 		vi, err := strconv.Atoi(v)
 		if err != nil {
-			log.Printf("Error converting value for %s - expected an integer:%s", envVar, err)
+			logError(errConfig, "Error converting value for %s - expected an integer:%s", envVar, err)
 		} else {
 			// do some sanity checking
 			if vi < minVal {
@@ -247,15 +338,103 @@ func main() {
 	flag.BoolVar(&debugOnly, "debug", false, "Run in debug only mode, not starting conmand")
 	flag.Parse()
 
+	// run an admin command against the running service instead of starting one
+	if flag.NArg() > 0 {
+		os.Exit(runCLI(flag.Args(), os.Stdout))
+	}
+
+	// all the times in the log are in the same format as the api
+	setupLogTimes()
+
 	// read the env variables into global vars with min/max sanity checks
+	// NOTE: the env var can only turn on debug mode set by the flag
+	registerSetting("DEBUG", func() string { return strconv.FormatBool(debugOnly) }, ConfigSetting{Allowed: []string{"TRUE"}})
 	if v := os.Getenv("DEBUG"); v == "TRUE" {
 		debugOnly = true
 	}
-	readSingleEnvVarInt("MAX_MTN_NODES_PER_POD", &maxMtnNodesPerPod, 5, 1500)
-	readSingleEnvVarInt("MAX_RVR_NODES_PER_POD", &maxRvrNodesPerPod, 5, 4000)
+	readSingleEnvVarInt("MAX_MTN_NODES_PER_POD", &maxMtnNodesPerPod, minNodesPerPodLimit, maxMtnNodesPerPodLimit)
+	readSingleEnvVarInt("MAX_RVR_NODES_PER_POD", &maxRvrNodesPerPod, minNodesPerPodLimit, maxRvrNodesPerPodLimit)
+	readSingleEnvVarInt("MIN_NODE_PODS", &minNodePods, 1, 1000)
+	readSingleEnvVarInt("MAX_NODE_PODS", &maxNodePods, 1, 1000)
+	if minNodePods > maxNodePods {
+		log.Printf("MIN_NODE_PODS:%d is greater than MAX_NODE_PODS:%d, using %d for both", minNodePods, maxNodePods, minNodePods)
+		maxNodePods = minNodePods
+	}
+	readSingleEnvVarString("POD_NAMESPACE", &operatorNamespace)
+	readSingleEnvVarString("CONSOLE_NODE_KIND", &consoleNodeKind, "StatefulSet", "Deployment")
+	readSingleEnvVarString("CONSOLE_NODE_NAME", &consoleNodeName)
+	readSingleEnvVarString("CONSOLE_NODE_NAMESPACE", &consoleNodeNamespace)
+	readConsoleNodePools()
+	readSingleEnvVarBool("TOPOLOGY_HINTS", &topologyHints)
+	readSingleEnvVarBool("SCALE_TO_ZERO", &scaleToZero)
+	readSingleEnvVarBool("CAPACITY_DISTRIBUTION", &capacityDistribution)
+	readSingleEnvVarBool("CANARY_TARGETS", &canaryTargets)
+	readSingleEnvVarInt("CANARY_CHANGE_PERCENT", &canaryChangePercent, 1, 1000)
+	readSingleEnvVarInt("CANARY_STABLE_CHECKS", &canaryStableChecks, 1, 100)
+	readSingleEnvVarInt("CANARY_MAX_CHECKS", &canaryMaxChecks, 2, 1000)
+	readSingleEnvVarBool("RESOURCE_SCALING", &resourceScaling)
+	readSingleEnvVarInt("SCALE_CPU_PERCENT", &scaleCPUPercent, 10, 100)
+	readSingleEnvVarInt("SCALE_MEMORY_PERCENT", &scaleMemPercent, 10, 100)
 	readSingleEnvVarInt("HARDWARE_UPDATE_SEC_FREQ", &newHardwareCheckPeriodSec, 10, 14400) // 10 sec -> 4 hrs
 	readSingleEnvVarInt("HEARTBEAT_CHECK_SEC_FREQ", &heartbeatCheckPeriodSec, 10, 300)     // 10 sec -> 5 min
 	readSingleEnvVarInt("HEARTBEAT_STALE_DURATION_MINUTES", &heartbeatStaleMinutes, 1, 60) // 1 min -> 60 min
+	readSingleEnvVarInt("SESSION_CHECK_SEC_FREQ", &sessionCheckPeriodSec, 60, 86400)       // 1 min -> 24 hrs
+	readSingleEnvVarInt("SESSION_STUCK_MINUTES", &sessionStuckMinutes, 1, 1440)            // 1 min -> 24 hrs
+	readSingleEnvVarBool("CLEANUP_STUCK_SESSIONS", &cleanupStuckSessions)
+	readSingleEnvVarString("CONSOLE_NODE_CONTAINER", &consoleNodeContainer)
+	readAlertEnvVars()
+	readMaintenanceWindows()
+	readHSMSources()
+	readSingleEnvVarBool("LEADER_ELECTION", &leaderElection)
+	readSingleEnvVarInt("SHARD_COUNT", &shardCount, 1, 64)
+	readSingleEnvVarString("SHARD_MODE", &shardMode, "hash", "cabinet")
+	if shardCount > 1 && !leaderElection {
+		log.Printf("SHARD_COUNT:%d requires leader election, enabling it", shardCount)
+		leaderElection = true
+	}
+	readSingleEnvVarInt("LEADER_LEASE_DURATION_SEC", &leaderLeaseDurationSec, 5, 300)
+	readSingleEnvVarInt("LEADER_RENEW_DEADLINE_SEC", &leaderRenewDeadlineSec, 2, 300)
+	readSingleEnvVarInt("LEADER_RETRY_PERIOD_SEC", &leaderRetryPeriodSec, 1, 60)
+	if leaderRenewDeadlineSec >= leaderLeaseDurationSec {
+		leaderRenewDeadlineSec = leaderLeaseDurationSec * 2 / 3
+		log.Printf("LEADER_RENEW_DEADLINE_SEC must be less than LEADER_LEASE_DURATION_SEC, using %d", leaderRenewDeadlineSec)
+	}
+	if leaderRetryPeriodSec >= leaderRenewDeadlineSec {
+		leaderRetryPeriodSec = 1
+		log.Printf("LEADER_RETRY_PERIOD_SEC must be less than LEADER_RENEW_DEADLINE_SEC, using %d", leaderRetryPeriodSec)
+	}
+	readSingleEnvVarInt("MASS_NODE_REMOVAL_COUNT", &massNodeRemovalCount, 1, 100000)
+	readFederationPeers()
+	readSingleEnvVarInt("KEY_ROTATION_DAYS", &keyRotationDays, 0, 3650)
+	readSingleEnvVarInt("KEY_ROTATION_GRACE_HOURS", &keyRotationGraceHours, 0, 720)
+	readSingleEnvVarInt("KEY_MAX_AGE_DAYS", &keyMaxAgeDays, 0, 3650)
+	readSingleEnvVarBool("TENANT_KEYS", &tenantKeys)
+	if tenantKeys {
+		log.Printf("TENANT_KEYS requires a console-node that reads %s", tenantKeyMapFile)
+	}
+	readSingleEnvVarInt("RIVER_CRED_CHECK_SEC_FREQ", &riverCredCheckPeriodSec, 0, 86400)
+	readSingleEnvVarInt("LOG_CHECK_SEC_FREQ", &consoleLogCheckPeriodSec, 0, 3600)
+	readSingleEnvVarInt("LOG_QUOTA_MB", &logQuotaMB, 0, 1048576)
+	readSingleEnvVarInt("CONSOLE_SILENCE_MINUTES", &consoleSilenceMinutes, 0, 10080)
+	readSingleEnvVarBool("CRASH_SNAPSHOTS", &crashSnapshots)
+	readSingleEnvVarInt("CRASH_SNAPSHOT_RETENTION_DAYS", &crashSnapshotRetentionDays, 1, 3650)
+	readRedactionRules()
+	readSingleEnvVarInt("LOG_COMPRESS_DAYS", &logCompressDays, 0, 3650)
+	readSingleEnvVarString("PCS_URL", &pcsAddrBase)
+	readSingleEnvVarString("FAS_URL", &fasAddrBase)
+	readSingleEnvVarInt("FAS_CHECK_SEC_FREQ", &fasCheckPeriodSec, 0, 86400)
+	readSingleEnvVarBool("KEY_CACHE_ENCRYPTION", &keyCacheEncryption)
+	readSingleEnvVarBool("READ_ONLY_MODE", &readOnlyMode)
+	readSingleEnvVarString("HANDOFF_URL", &handoffURL)
+	readSingleEnvVarString("HANDOFF_TOKEN", &handoffToken)
+	readSingleEnvVarInt("HANDOFF_TIMEOUT_SEC", &handoffTimeoutSec, 60, 86400)
+	readSingleEnvVarBool("HTTP2", &http2Enabled)
+	readSingleEnvVarBool("RESPONSE_COMPRESSION", &responseCompression)
+	readSingleEnvVarString("CORS_ALLOWED_ORIGINS", &corsAllowedOrigins)
+	readSingleEnvVarString("CORS_ALLOWED_METHODS", &corsAllowedMethods)
+	readSingleEnvVarString("CORS_ALLOWED_HEADERS", &corsAllowedHeaders)
+	readSingleEnvVarString("ADMIN_SOCKET", &adminSocket)
+	readSingleEnvVarBool("PPROF", &pprofEnabled)
 
 	// log the fact if we are in debug mode
 	if debugOnly {
@@ -267,20 +446,116 @@ func main() {
 	if err != nil {
 		log.Panicf("ERROR: k8Manager failed to initialize")
 	}
+	eventService = k8Manager
+	keyCacheService = k8Manager
 	slsManager := NewSlsManager()
-	nodeManager := NewNodeManager(k8Manager)
+	nodeManager := NewNodeManager(k8Manager, slsManager)
 	dataManager := NewDataManager(k8Manager, slsManager)
-	healthManager := NewHealthManager(dataManager)
+	healthManager := NewHealthManager(dataManager, k8Manager)
 	debugManager := NewDebugManager(dataManager, healthManager)
+	sessionManager := NewSessionManager(k8Manager)
+	processManager := NewProcessManager()
+	freezeManager := NewFreezeManager()
+	stateManager := NewStateManager(dataManager, k8Manager)
+	tapmsManager := NewTapmsManager()
+	tenantManager := NewTenantManager(tapmsManager)
+	keyManager := NewKeyManager(k8Manager, tapmsManager)
+	logManager := NewLogManager(k8Manager)
+
+	// take over from the instance being upgraded before starting to reconcile
+	if handoffURL != "" && !readOnlyMode {
+		requestHandoff(handoffURL)
+	}
+
+	// all the background threads are stopped through this context on shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	var watchers sync.WaitGroup
+	runWatcher := func(watch func(context.Context)) {
+		watchers.Add(1)
+		go func() {
+			defer watchers.Done()
+			watch(ctx)
+		}()
+	}
 
 	// Set up the zombie killer
-	go watchForZombies()
+	runWatcher(func(ctx context.Context) { watchForZombies(ctx, processManager) })
+
+	// the loops that make changes only run on the leader and are stopped
+	// through the given context if leadership is lost
+	runLeaderLoops := func(ctx context.Context) {
+		var loops sync.WaitGroup
+		runLoop := func(loop func(context.Context)) {
+			loops.Add(1)
+			go func() {
+				defer loops.Done()
+				loop(ctx)
+			}()
+		}
+
+		// loop over new hardware
+		// NOTE: when sharded every replica watches the hardware for its shards
+		if shardCount <= 1 {
+			runLoop(func(ctx context.Context) { watchHardware(ctx, dataManager, nodeManager) })
+		}
+
+		// spin a thread to check for stale heartbeat information
+		runLoop(dataManager.checkHeartbeats)
+
+		// spin a thread to check for stuck console sessions in the console-node pods
+		runLoop(sessionManager.watchConsoleSessions)
+
+		// spin a thread to rotate the mountain console key when it is due
+		runLoop(keyManager.watchKeyRotation)
+
+		// spin a thread to refresh river consoles when their credentials change
+		runLoop(func(ctx context.Context) { watchRiverCreds(ctx, dataManager) })
+
+		// spin a thread to hold consoles steady during bmc firmware updates
+		runLoop(func(ctx context.Context) { watchFirmwareUpdates(ctx, dataManager) })
+
+		// spin a thread to take consoles out of monitoring for maintenance windows
+		runLoop(func(ctx context.Context) { watchNodeMaintenance(ctx, dataManager) })
+
+		// spin a thread to report powered on nodes with quiet consoles
+		runLoop(logManager.watchConsoleSilence)
+
+		// spin a thread to compress the old rotated console logs
+		runLoop(logManager.watchLogCompression)
 
-	// loop over new hardware
-	go watchHardware(dataManager, nodeManager)
+		// spin a thread to run the rolling restarts of the console-node pods
+		runLoop(dataManager.watchRollingRestarts)
 
-	// spin a thread to check for stale heartbeat information
-	go dataManager.checkHeartbeats()
+		loops.Wait()
+	}
+	if readOnlyMode {
+		// NOTE: a read-only replica never changes anything so it does not
+		//  take part in the election or run any of the loops
+		log.Printf("Running in READ-ONLY mode, not reconciling or scaling")
+	} else if shardCount > 1 {
+		// the leader lease is shard 0, the rest have their own leases
+		runWatcher(func(ctx context.Context) { watchHardware(ctx, dataManager, nodeManager) })
+		runWatcher(func(ctx context.Context) { k8Manager.runLeaderElection(ctx, runLeaderLoops) })
+		for shard := 1; shard < shardCount; shard++ {
+			shard := shard
+			runWatcher(func(ctx context.Context) { k8Manager.runShardElection(ctx, shard) })
+		}
+	} else if leaderElection {
+		runWatcher(func(ctx context.Context) { k8Manager.runLeaderElection(ctx, runLeaderLoops) })
+	} else {
+		atomic.StoreInt32(&isLeader, 1)
+		runWatcher(runLeaderLoops)
+	}
+
+	// spin a thread to watch the console log files for rotation
+	// NOTE: this only reads the shared volume so every replica runs it
+	runWatcher(logManager.watchConsoleLogs)
+
+	// spin a thread to send alerts when process health degrades
+	runWatcher(watchProcessHealth)
+
+	// spin a thread to dump the internal state on SIGUSR1
+	runWatcher(func(ctx context.Context) { watchStateDumpSignal(ctx, healthManager) })
 
 	// set up a channel to wait for the os to tell us to stop
 	// NOTE - must be set up before initializing anything that needs
@@ -289,7 +564,7 @@ func main() {
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM, syscall.SIGKILL)
 
-	setupRoutes(dataManager, healthManager, debugManager)
+	setupRoutes(dataManager, healthManager, debugManager, sessionManager, freezeManager, stateManager, tenantManager, keyManager, logManager)
 
 	// spin the server in a separate thread so main can wait on an os
 	// signal to cleanly shut down
@@ -301,9 +576,15 @@ func main() {
 	go func() {
 		// NOTE: do not use log.Fatal as that will immediately exit
 		// the program and short-circuit the shutdown logic below
-		log.Printf("Info: Server %s\n", httpSrv.ListenAndServe())
+		l, err := listenHTTP(&httpSrv)
+		if err != nil {
+			log.Printf("Info: Server %s\n", err)
+			return
+		}
+		log.Printf("Info: Server %s\n", httpSrv.Serve(l))
 	}()
 	log.Printf("Info: console-operator API listening on: %v\n", httpListen)
+	adminSrv := startAdminServer()
 
 	//////////////////
 	// Clean shutdown section
@@ -314,10 +595,31 @@ func main() {
 	inShutdown = true
 	log.Printf("Info: Detected signal to close service: %s", sig)
 
+	// stop the background threads so nothing new is started while terminating
+	cancel()
+
 	// stop the server from taking requests
 	// NOTE: this waits for active connections to finish
 	log.Printf("Info: Server shutting down")
 	httpSrv.Shutdown(context.Background())
+	if adminSrv != nil {
+		adminSrv.Shutdown(context.Background())
+	}
+
+	// give the background threads a chance to finish what they are doing
+	// NOTE: this is bounded so a hung call to another service can not hold
+	//  up the exit
+	watchersDone := make(chan struct{})
+	go func() {
+		watchers.Wait()
+		close(watchersDone)
+	}()
+	select {
+	case <-watchersDone:
+		log.Printf("Info: Background threads stopped")
+	case <-time.After(watcherShutdownTimeout):
+		log.Printf("Warning: Timed out waiting for background threads to stop")
+	}
 
 	log.Printf("Info: Service Exiting.")
 }
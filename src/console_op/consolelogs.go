@@ -0,0 +1,424 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to find the console log files on the shared
+//  volume and report where they are and when they rotate so log shipping
+//  sidecars can pick them up
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Directory on the shared volume console-node writes the console logs to
+// NOTE: each node has a file console.XNAME, rotated copies have a suffix
+// after the xname
+var consoleLogDir string = "/var/log/conman"
+
+const consoleLogPrefix string = "console."
+
+// How often to check the console log files for rotation - 0 disables it
+var consoleLogCheckPeriodSec int = 30
+
+// Maximum number of rotation events kept
+const maxLogRotationEvents int = 1000
+
+// ConsoleLogLocation - the console log files of a node
+type ConsoleLogLocation struct {
+	Xname    string   `json:"xname"`
+	PodName  string   `json:"podname,omitempty"`
+	Path     string   `json:"path"`
+	Size     int64    `json:"size"`
+	Modified string   `json:"modified"`
+	Rotated  []string `json:"rotated"`
+}
+
+// LogLocationsResponse - where the console logs are on the shared volume
+type LogLocationsResponse struct {
+	Dir   string               `json:"dir"`
+	Nodes []ConsoleLogLocation `json:"nodes"`
+}
+
+// LogRotationEvent - a console log file that was rotated
+type LogRotationEvent struct {
+	Xname    string `json:"xname"`
+	Path     string `json:"path"`
+	Time     string `json:"time"`
+	PrevSize int64  `json:"prevsize"`
+}
+
+// What is known about a console log file between checks
+type consoleLogFileState struct {
+	inode uint64
+	size  int64
+}
+
+// Current state of the console log files
+var consoleLogFiles map[string]consoleLogFileState = make(map[string]consoleLogFileState)
+var logRotationEvents []LogRotationEvent = nil
+var consoleLogMutex sync.Mutex
+
+type LogService interface {
+	watchConsoleLogs(ctx context.Context)
+	watchConsoleSilence(ctx context.Context)
+	watchLogCompression(ctx context.Context)
+	doGetLogLocations(w http.ResponseWriter, r *http.Request)
+	doGetLogRotations(w http.ResponseWriter, r *http.Request)
+	doGetLogShipperConfig(w http.ResponseWriter, r *http.Request)
+	doGetLogQuota(w http.ResponseWriter, r *http.Request)
+	doGetLogManifest(w http.ResponseWriter, r *http.Request)
+	doGetLogArchive(w http.ResponseWriter, r *http.Request)
+	doExportLog(w http.ResponseWriter, r *http.Request)
+	doTailLog(w http.ResponseWriter, r *http.Request)
+	doGetNodeEvents(w http.ResponseWriter, r *http.Request)
+	doGetSilentConsoles(w http.ResponseWriter, r *http.Request)
+	doGetCrashSnapshots(w http.ResponseWriter, r *http.Request)
+	doGetCrashSnapshot(w http.ResponseWriter, r *http.Request)
+	doGetRedactionRules(w http.ResponseWriter, r *http.Request)
+	doGetLogCompression(w http.ResponseWriter, r *http.Request)
+	doCompressLogs(w http.ResponseWriter, r *http.Request)
+	doGetOutputRates(w http.ResponseWriter, r *http.Request)
+	doGetMetrics(w http.ResponseWriter, r *http.Request)
+}
+
+// Implements LogService
+type LogManager struct {
+	k8Service K8Service
+}
+
+// Constructor injection for dependencies
+func NewLogManager(k8s K8Service) LogService {
+	return &LogManager{k8Service: k8s}
+}
+
+// Get the xname a console log file belongs to and if it is a rotated copy
+func parseConsoleLogName(name string) (xname string, rotated bool, ok bool) {
+	if !strings.HasPrefix(name, consoleLogPrefix) {
+		return "", false, false
+	}
+	rest := strings.TrimPrefix(name, consoleLogPrefix)
+	// NOTE: rotated copies are console.XNAME-DATE, console.XNAME.N, or
+	//  either of those compressed
+	if i := strings.IndexAny(rest, ".-"); i >= 0 {
+		xname, rotated = rest[:i], true
+	} else {
+		xname = rest
+	}
+	if !strings.HasPrefix(xname, "x") || len(xname) < 2 {
+		return "", false, false
+	}
+	return xname, rotated, true
+}
+
+// Path of the current console log of a node
+func consoleLogPath(xname string) string {
+	return filepath.Join(consoleLogDir, consoleLogPrefix+xname)
+}
+
+// Find the console log files of all the nodes in the log directory
+func findConsoleLogs(dir string) (map[string]*ConsoleLogLocation, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	logs := make(map[string]*ConsoleLogLocation)
+	for _, fi := range files {
+		if fi.IsDir() {
+			continue
+		}
+		xname, rotated, ok := parseConsoleLogName(fi.Name())
+		if !ok {
+			continue
+		}
+		loc, found := logs[xname]
+		if !found {
+			loc = &ConsoleLogLocation{Xname: xname, Path: filepath.Join(dir, consoleLogPrefix+xname), Rotated: []string{}}
+			logs[xname] = loc
+		}
+		if rotated {
+			loc.Rotated = append(loc.Rotated, filepath.Join(dir, fi.Name()))
+		} else {
+			loc.Size = fi.Size()
+			loc.Modified = formatTime(fi.ModTime())
+		}
+	}
+	for _, loc := range logs {
+		sort.Strings(loc.Rotated)
+	}
+	return logs, nil
+}
+
+// Compare the log files to the last check and record the ones that rotated
+// NOTE: a rotated file is either replaced, so has a new inode, or truncated
+func checkLogRotation(current map[string]consoleLogFileState, now time.Time) []LogRotationEvent {
+	consoleLogMutex.Lock()
+	defer consoleLogMutex.Unlock()
+	var events []LogRotationEvent = nil
+	for path, st := range current {
+		prev, found := consoleLogFiles[path]
+		if found && (st.inode != prev.inode || st.size < prev.size) {
+			xname, _, _ := parseConsoleLogName(filepath.Base(path))
+			events = append(events, LogRotationEvent{
+				Xname:    xname,
+				Path:     path,
+				Time:     formatTime(now),
+				PrevSize: prev.size,
+			})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Xname < events[j].Xname })
+	consoleLogFiles = current
+	logRotationEvents = append(logRotationEvents, events...)
+	if len(logRotationEvents) > maxLogRotationEvents {
+		logRotationEvents = logRotationEvents[len(logRotationEvents)-maxLogRotationEvents:]
+	}
+	return events
+}
+
+// Get the rotation events since the given time
+func getLogRotations(since time.Time) []LogRotationEvent {
+	consoleLogMutex.Lock()
+	defer consoleLogMutex.Unlock()
+	events := []LogRotationEvent{}
+	for _, ev := range logRotationEvents {
+		if t, err := time.Parse(time.RFC3339, ev.Time); err == nil && !t.Before(since) {
+			events = append(events, ev)
+		}
+	}
+	return events
+}
+
+// Loop to watch the console log files for rotation
+func (LogManager) watchConsoleLogs(ctx context.Context) {
+	if consoleLogCheckPeriodSec <= 0 {
+		return
+	}
+	for {
+		if !debugOnly {
+			checkConsoleLogFiles()
+			// NOTE: only the replica making changes enforces the quota
+			if logQuotaMB > 0 && amLeader() && !reconcileStopped() {
+				enforceLogQuota(consoleLogDir, int64(logQuotaMB)*1024*1024)
+			}
+			if crashSnapshots && amLeader() && !reconcileStopped() {
+				checkCrashes(consoleLogDir, time.Now())
+				pruneCrashSnapshots(crashSnapshotDir, time.Now())
+			}
+		}
+		if !sleepCtx(ctx, time.Duration(consoleLogCheckPeriodSec)*time.Second) {
+			log.Printf("Stopping console log checks")
+			return
+		}
+	}
+}
+
+// Look at the current console log files and record any rotations
+func checkConsoleLogFiles() {
+	files, err := ioutil.ReadDir(consoleLogDir)
+	if err != nil {
+		log.Printf("Unable to read the console log directory %s: %s", consoleLogDir, err)
+		return
+	}
+	current := make(map[string]consoleLogFileState)
+	for _, fi := range files {
+		if _, rotated, ok := parseConsoleLogName(fi.Name()); !ok || rotated || fi.IsDir() {
+			continue
+		}
+		st := consoleLogFileState{size: fi.Size()}
+		if sys, ok := fi.Sys().(*syscall.Stat_t); ok {
+			st.inode = sys.Ino
+		}
+		current[filepath.Join(consoleLogDir, fi.Name())] = st
+	}
+	now := time.Now()
+	recordOutputRates(getConsoleLogFileStates(), current, now)
+	for _, ev := range checkLogRotation(current, now) {
+		log.Printf("Console log rotated: %s", ev.Path)
+	}
+}
+
+// Report the console log files of each node and the pod watching it
+func (LogManager) doGetLogLocations(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	logs, err := findConsoleLogs(consoleLogDir)
+	if err != nil {
+		sendJSONError(w, http.StatusInternalServerError,
+			fmt.Sprintf("Unable to read the console log directory: %s", err))
+		return
+	}
+	// NOTE: the pods are only a hint for the sidecars, the locations are
+	//  still useful if console-data can not be reached
+	if inv, err := getDataInventory(getRequestID(r)); err == nil {
+		for _, n := range inv {
+			if loc, found := logs[n.NodeName]; found && n.NodeConsoleName != "" {
+				loc.PodName = fmt.Sprintf("%s-%s", consoleNodeName, n.NodeConsoleName)
+			}
+		}
+	}
+	resp := LogLocationsResponse{Dir: consoleLogDir, Nodes: []ConsoleLogLocation{}}
+	for _, loc := range logs {
+		resp.Nodes = append(resp.Nodes, *loc)
+	}
+	sort.Slice(resp.Nodes, func(i, j int) bool { return resp.Nodes[i].Xname < resp.Nodes[j].Xname })
+	SendResponseJSON(w, http.StatusOK, resp)
+}
+
+// Report the console log rotations, `?since=` limits them to those after an
+// RFC3339 time
+func (LogManager) doGetLogRotations(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	var since time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, v); err != nil {
+			sendJSONError(w, http.StatusBadRequest,
+				fmt.Sprintf("Invalid since time %s, expected RFC3339", v))
+			return
+		}
+	}
+	SendResponseJSON(w, http.StatusOK, getLogRotations(since))
+}
+
+// Generate the vector config to ship the console logs
+func vectorLogConfig(dir, endpoint string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# console logs written by cray-console-node\n")
+	fmt.Fprintf(&sb, "[sources.console_logs]\n")
+	fmt.Fprintf(&sb, "type = \"file\"\n")
+	fmt.Fprintf(&sb, "include = [\"%s/%s*\"]\n", dir, consoleLogPrefix)
+	fmt.Fprintf(&sb, "exclude = [\"%s/%s*.gz\"]\n", dir, consoleLogPrefix)
+	fmt.Fprintf(&sb, "read_from = \"beginning\"\n\n")
+	fmt.Fprintf(&sb, "[transforms.console_xname]\n")
+	fmt.Fprintf(&sb, "type = \"remap\"\n")
+	fmt.Fprintf(&sb, "inputs = [\"console_logs\"]\n")
+	fmt.Fprintf(&sb, "source = '''\n")
+	fmt.Fprintf(&sb, ".xname = parse_regex!(.file, r'%s(?P<xname>x[0-9a-z]+)').xname\n", consoleLogPrefix)
+	// NOTE: the sidecar reads the raw logs so it has to apply the redaction
+	//  rules itself
+	for _, r := range redactionRules {
+		fmt.Fprintf(&sb, ".message = replace(string!(.message), r'%s', %q)\n", r.rule.Regex, r.rule.Mask)
+	}
+	fmt.Fprintf(&sb, "'''\n\n")
+	fmt.Fprintf(&sb, "[sinks.console_out]\n")
+	fmt.Fprintf(&sb, "inputs = [\"console_xname\"]\n")
+	if endpoint != "" {
+		fmt.Fprintf(&sb, "type = \"http\"\n")
+		fmt.Fprintf(&sb, "uri = \"%s\"\n", endpoint)
+		fmt.Fprintf(&sb, "encoding.codec = \"json\"\n")
+	} else {
+		fmt.Fprintf(&sb, "type = \"console\"\n")
+		fmt.Fprintf(&sb, "encoding.codec = \"json\"\n")
+	}
+	return sb.String()
+}
+
+// Generate the fluent-bit config to ship the console logs
+func fluentBitLogConfig(dir, endpoint string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# console logs written by cray-console-node\n")
+	fmt.Fprintf(&sb, "[INPUT]\n")
+	fmt.Fprintf(&sb, "    Name              tail\n")
+	fmt.Fprintf(&sb, "    Path              %s/%s*\n", dir, consoleLogPrefix)
+	fmt.Fprintf(&sb, "    Exclude_Path      %s/%s*.gz\n", dir, consoleLogPrefix)
+	fmt.Fprintf(&sb, "    Path_Key          file\n")
+	fmt.Fprintf(&sb, "    Tag               console.*\n")
+	fmt.Fprintf(&sb, "    Refresh_Interval  %d\n", consoleLogCheckPeriodSec)
+	fmt.Fprintf(&sb, "    Rotate_Wait       30\n\n")
+	fmt.Fprintf(&sb, "[OUTPUT]\n")
+	fmt.Fprintf(&sb, "    Match             console.*\n")
+	if u, err := url.Parse(endpoint); endpoint != "" && err == nil {
+		port := u.Port()
+		if port == "" {
+			port = "80"
+			if u.Scheme == "https" {
+				port = "443"
+			}
+		}
+		fmt.Fprintf(&sb, "    Name              http\n")
+		fmt.Fprintf(&sb, "    Host              %s\n", u.Hostname())
+		fmt.Fprintf(&sb, "    Port              %s\n", port)
+		fmt.Fprintf(&sb, "    URI               %s\n", u.RequestURI())
+		if u.Scheme == "https" {
+			fmt.Fprintf(&sb, "    tls               On\n")
+		}
+		fmt.Fprintf(&sb, "    Format            json\n")
+	} else {
+		fmt.Fprintf(&sb, "    Name              stdout\n")
+	}
+	return sb.String()
+}
+
+// Generate the config for a log shipping sidecar, `?type=vector` or
+// `?type=fluentbit`, `?endpoint=` is where the logs are sent
+func (LogManager) doGetLogShipperConfig(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	endpoint := r.URL.Query().Get("endpoint")
+	var config string
+	switch shipper := r.URL.Query().Get("type"); shipper {
+	case "vector", "":
+		config = vectorLogConfig(consoleLogDir, endpoint)
+	case "fluentbit":
+		config = fluentBitLogConfig(consoleLogDir, endpoint)
+	default:
+		sendJSONError(w, http.StatusBadRequest,
+			fmt.Sprintf("Invalid type %s, expected vector or fluentbit", shipper))
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(config))
+}
@@ -0,0 +1,118 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseConsoleLogName(t *testing.T) {
+	tests := []struct {
+		name    string
+		xname   string
+		rotated bool
+		ok      bool
+	}{
+		{"console.x3000c0s19b1n0", "x3000c0s19b1n0", false, true},
+		{"console.x3000c0s19b1n0-20260101", "x3000c0s19b1n0", true, true},
+		{"console.x3000c0s19b1n0.1.gz", "x3000c0s19b1n0", true, true},
+		{"conman.key", "", false, false},
+		{"console.", "", false, false},
+	}
+	for _, tt := range tests {
+		xname, rotated, ok := parseConsoleLogName(tt.name)
+		if xname != tt.xname || rotated != tt.rotated || ok != tt.ok {
+			t.Errorf("%s Expected: %s %v %v. Got: %s %v %v.", tt.name, tt.xname, tt.rotated, tt.ok, xname, rotated, ok)
+		}
+	}
+}
+
+func TestFindConsoleLogs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "consolelogs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, f := range []string{"console.x1000c0s0b0n0", "console.x1000c0s0b0n0-20260101", "console.x1000c0s0b0n1", "TargetNodes.txt"} {
+		ioutil.WriteFile(filepath.Join(dir, f), []byte("data\n"), 0644)
+	}
+
+	logs, err := findConsoleLogs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 {
+		t.Errorf("Expected: 2. Got: %d.", len(logs))
+	}
+	if loc := logs["x1000c0s0b0n0"]; loc == nil || len(loc.Rotated) != 1 || loc.Size != 5 {
+		t.Errorf("Expected one rotated file and size 5 for x1000c0s0b0n0. Got: %v.", loc)
+	}
+}
+
+func TestCheckLogRotation(t *testing.T) {
+	defer func() {
+		consoleLogFiles = make(map[string]consoleLogFileState)
+		logRotationEvents = nil
+	}()
+	path := "/var/log/conman/console.x1000c0s0b0n0"
+	now := time.Now()
+	checkLogRotation(map[string]consoleLogFileState{path: {inode: 1, size: 100}}, now)
+	if ev := checkLogRotation(map[string]consoleLogFileState{path: {inode: 1, size: 200}}, now); len(ev) != 0 {
+		t.Errorf("Expected: 0. Got: %d.", len(ev))
+	}
+	// replaced by logrotate
+	if ev := checkLogRotation(map[string]consoleLogFileState{path: {inode: 2, size: 0}}, now); len(ev) != 1 || ev[0].PrevSize != 200 {
+		t.Errorf("Expected one rotation from size 200. Got: %v.", ev)
+	}
+	// truncated in place
+	checkLogRotation(map[string]consoleLogFileState{path: {inode: 2, size: 50}}, now)
+	if ev := checkLogRotation(map[string]consoleLogFileState{path: {inode: 2, size: 10}}, now); len(ev) != 1 {
+		t.Errorf("Expected: 1. Got: %d.", len(ev))
+	}
+	if got := getLogRotations(now.Add(-time.Minute)); len(got) != 2 {
+		t.Errorf("Expected: 2. Got: %d.", len(got))
+	}
+	if got := getLogRotations(now.Add(time.Minute)); len(got) != 0 {
+		t.Errorf("Expected: 0. Got: %d.", len(got))
+	}
+}
+
+func TestLogShipperConfig(t *testing.T) {
+	vc := vectorLogConfig("/var/log/conman", "")
+	if !strings.Contains(vc, "include = [\"/var/log/conman/console.*\"]") || !strings.Contains(vc, "type = \"console\"") {
+		t.Errorf("Unexpected vector config: %s", vc)
+	}
+	fc := fluentBitLogConfig("/var/log/conman", "https://logs.example.com/ingest")
+	for _, want := range []string{"Host              logs.example.com", "Port              443", "URI               /ingest", "tls               On"} {
+		if !strings.Contains(fc, want) {
+			t.Errorf("Expected %s in fluent-bit config: %s", want, fc)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to let a web console ui call the api directly
+//  from a browser
+
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Comma separated origins allowed to call the api from a browser, '*' for
+// any origin.  Empty turns cors off.
+var corsAllowedOrigins string = ""
+
+// Comma separated methods and request headers a browser may use
+var corsAllowedMethods string = "GET, POST, DELETE"
+var corsAllowedHeaders string = "Content-Type, X-Request-ID"
+
+// Response headers a browser script may read
+const corsExposedHeaders string = "X-Request-ID, X-Log-Offset"
+
+// Time a browser may cache a preflight answer
+const corsMaxAgeSec string = "600"
+
+// Split a comma separated setting
+func splitList(val string) []string {
+	var items []string = nil
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Check if the origin may call the api
+func corsOriginAllowed(origin string) bool {
+	for _, o := range splitList(corsAllowedOrigins) {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add the cors headers for allowed origins and answer preflight requests
+// NOTE: this runs before the leader check so a standby answers preflights
+func corsPolicy(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || corsAllowedOrigins == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		if !corsOriginAllowed(origin) {
+			// NOTE: the browser blocks the response without the headers
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(splitList(corsAllowedMethods), ", "))
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(splitList(corsAllowedHeaders), ", "))
+			w.Header().Set("Access-Control-Max-Age", corsMaxAgeSec)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
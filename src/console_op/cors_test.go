@@ -0,0 +1,73 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSPolicy(t *testing.T) {
+	defer func(o string) { corsAllowedOrigins = o }(corsAllowedOrigins)
+	corsAllowedOrigins = "https://console.example.com, https://other.example.com"
+	called := false
+	h := corsPolicy(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	// preflight from an allowed origin is answered here
+	req := httptest.NewRequest(http.MethodOptions, "/console-operator/v1/freeze", nil)
+	req.Header.Set("Origin", "https://console.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if called || w.Code != http.StatusNoContent {
+		t.Errorf("Expected: %d. Got: %d.", http.StatusNoContent, w.Code)
+	}
+	if w.Header().Get("Access-Control-Allow-Origin") != "https://console.example.com" ||
+		w.Header().Get("Access-Control-Allow-Methods") != "GET, POST, DELETE" {
+		t.Errorf("Unexpected preflight headers: %v", w.Header())
+	}
+
+	// a request from an unknown origin gets no cors headers
+	req = httptest.NewRequest(http.MethodGet, "/console-operator/info", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if !called || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("Expected no cors headers, got: %v", w.Header())
+	}
+
+	// nothing is added when cors is off
+	corsAllowedOrigins = ""
+	req = httptest.NewRequest(http.MethodGet, "/console-operator/info", nil)
+	req.Header.Set("Origin", "https://console.example.com")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("Expected no cors headers, got: %v", w.Header())
+	}
+}
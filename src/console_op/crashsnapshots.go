@@ -0,0 +1,316 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to watch the console logs for crashes and
+//  save the output around each crash where log rotation will not remove it
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Save the console output around crashes
+var crashSnapshots bool = false
+
+// Days a crash snapshot is kept
+var crashSnapshotRetentionDays int = 30
+
+// Directory on the shared volume the snapshots are kept in, one directory
+// per node
+// NOTE: outside of the conman log directory so log rotation does not touch it
+var crashSnapshotDir string = "/var/log/console/snapshots"
+
+// Bytes of output kept from before and after the crash
+const crashSnapshotPreBytes int64 = 64 * 1024
+const crashSnapshotPostBytes int64 = 64 * 1024
+
+// Time to wait after a crash is seen for the rest of the crash output
+const crashSnapshotPostWait = 2 * time.Minute
+
+// Most new output read from a log in one check
+const maxCrashScanBytes int64 = 4 * 1024 * 1024
+
+// Layout of the time in the snapshot file names
+const crashSnapshotTimeLayout string = "20060102T150405Z"
+
+// Console output that marks a crash
+var crashSignatureRegex = regexp.MustCompile(`Kernel panic - not syncing|Oops: |BUG: (?:unable to handle|soft lockup|kernel NULL pointer)|general protection fault|Machine Check Exception|Call Trace:`)
+
+// CrashSnapshot - saved console output around a crash
+type CrashSnapshot struct {
+	Xname     string `json:"xname"`
+	Time      string `json:"time"`
+	Signature string `json:"signature"`
+	Size      int64  `json:"size"`
+}
+
+// A crash waiting for the output after it
+type pendingCrash struct {
+	path      string
+	offset    int64
+	signature string
+	detected  time.Time
+}
+
+// How far each console log has been scanned and the crashes waiting to be saved
+var crashScanOffsets map[string]int64 = make(map[string]int64)
+var pendingCrashes map[string]pendingCrash = make(map[string]pendingCrash)
+var crashSnapshotMutex sync.Mutex
+
+// Find the first crash in a chunk of console output, returning the offset of
+// the line it is on within the chunk
+func findCrashSignature(chunk []byte) (int64, string, bool) {
+	var offset int64 = 0
+	scanner := bufio.NewScanner(bytes.NewReader(chunk))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if m := crashSignatureRegex.Find(line); m != nil {
+			return offset, strings.TrimSpace(string(line)), true
+		}
+		offset += int64(len(line)) + 1
+	}
+	return 0, "", false
+}
+
+// Read part of a file
+func readLogRange(path string, from, to int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err = f.Seek(from, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(io.LimitReader(f, to-from))
+}
+
+// Scan the new output of the console logs for crashes and save the
+// snapshots of crashes that have waited long enough
+func checkCrashes(dir string, now time.Time) {
+	byNode, err := consoleLogFilesByNode(dir)
+	if err != nil {
+		log.Printf("Unable to read the console log directory %s: %s", dir, err)
+		return
+	}
+	for xname, files := range byNode {
+		for _, f := range files {
+			if !f.rotated {
+				scanLogForCrash(xname, f, now)
+			}
+		}
+	}
+
+	crashSnapshotMutex.Lock()
+	var ready []string = nil
+	for xname, pc := range pendingCrashes {
+		if !now.Before(pc.detected.Add(crashSnapshotPostWait)) {
+			ready = append(ready, xname)
+		}
+	}
+	crashSnapshotMutex.Unlock()
+	for _, xname := range ready {
+		crashSnapshotMutex.Lock()
+		pc := pendingCrashes[xname]
+		delete(pendingCrashes, xname)
+		crashSnapshotMutex.Unlock()
+		if err := saveCrashSnapshot(xname, pc); err != nil {
+			log.Printf("Unable to save the crash snapshot for %s: %s", xname, err)
+		}
+	}
+}
+
+// Scan the output written to a console log since the last check
+func scanLogForCrash(xname string, f consoleLogFile, now time.Time) {
+	crashSnapshotMutex.Lock()
+	from, seen := crashScanOffsets[f.path]
+	_, pending := pendingCrashes[xname]
+	crashScanOffsets[f.path] = f.size
+	crashSnapshotMutex.Unlock()
+
+	// NOTE: output from before the operator started is not scanned, a
+	//  smaller file has been rotated so it is scanned from the start
+	if !seen || pending || from == f.size {
+		return
+	}
+	if from > f.size {
+		from = 0
+	}
+	if f.size-from > maxCrashScanBytes {
+		from = f.size - maxCrashScanBytes
+	}
+	chunk, err := readLogRange(f.path, from, f.size)
+	if err != nil {
+		log.Printf("Unable to scan the console log %s for crashes: %s", f.path, err)
+		return
+	}
+	if off, sig, found := findCrashSignature(chunk); found {
+		log.Printf("Crash detected on the console of %s: %s", xname, sig)
+		crashSnapshotMutex.Lock()
+		pendingCrashes[xname] = pendingCrash{path: f.path, offset: from + off, signature: sig, detected: now}
+		crashSnapshotMutex.Unlock()
+	}
+}
+
+// Save the output around a crash
+func saveCrashSnapshot(xname string, pc pendingCrash) error {
+	from := pc.offset - crashSnapshotPreBytes
+	if from < 0 {
+		from = 0
+	}
+	// NOTE: if the log was rotated since the crash the output after the
+	//  crash is in the new log, what is left is still saved
+	data, err := readLogRange(pc.path, from, pc.offset+crashSnapshotPostBytes)
+	if err != nil {
+		return err
+	}
+	// start at a full line
+	if from > 0 {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+	nodeDir := filepath.Join(crashSnapshotDir, xname)
+	if err = os.MkdirAll(nodeDir, 0755); err != nil {
+		return err
+	}
+	name := filepath.Join(nodeDir, pc.detected.UTC().Format(crashSnapshotTimeLayout)+".log")
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# crash signature: %s\n", redactLine(pc.signature))
+	if _, err = redactCopy(&buf, bytes.NewReader(data)); err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(name, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	log.Printf("Saved crash snapshot %s", name)
+	return nil
+}
+
+// Remove the snapshots older than the retention
+func pruneCrashSnapshots(dir string, now time.Time) {
+	nodeDirs, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	cutoff := now.Add(-time.Duration(crashSnapshotRetentionDays) * 24 * time.Hour)
+	for _, nd := range nodeDirs {
+		if !nd.IsDir() {
+			continue
+		}
+		for _, snap := range listCrashSnapshots(dir, nd.Name()) {
+			if t, err := time.Parse(time.RFC3339, snap.Time); err == nil && t.Before(cutoff) {
+				os.Remove(crashSnapshotPath(dir, snap.Xname, t))
+			}
+		}
+	}
+}
+
+// Path of the snapshot of a node from a given time
+func crashSnapshotPath(dir, xname string, t time.Time) string {
+	return filepath.Join(dir, xname, t.UTC().Format(crashSnapshotTimeLayout)+".log")
+}
+
+// List the snapshots of a node, oldest first
+func listCrashSnapshots(dir, xname string) []CrashSnapshot {
+	snaps := []CrashSnapshot{}
+	files, err := ioutil.ReadDir(filepath.Join(dir, xname))
+	if err != nil {
+		return snaps
+	}
+	for _, fi := range files {
+		t, err := time.Parse(crashSnapshotTimeLayout, strings.TrimSuffix(fi.Name(), ".log"))
+		if err != nil {
+			continue
+		}
+		snap := CrashSnapshot{Xname: xname, Time: formatTime(t), Size: fi.Size()}
+		if f, err := os.Open(filepath.Join(dir, xname, fi.Name())); err == nil {
+			if line, err := bufio.NewReader(f).ReadString('\n'); err == nil {
+				snap.Signature = strings.TrimSpace(strings.TrimPrefix(line, "# crash signature:"))
+			}
+			f.Close()
+		}
+		snaps = append(snaps, snap)
+	}
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].Time < snaps[j].Time })
+	return snaps
+}
+
+// Report the crash snapshots of a node
+func (LogManager) doGetCrashSnapshots(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	SendResponseJSON(w, http.StatusOK, listCrashSnapshots(crashSnapshotDir, chi.URLParam(r, "xname")))
+}
+
+// Send the crash snapshot of a node from the given RFC3339 time
+func (LogManager) doGetCrashSnapshot(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	xname := chi.URLParam(r, "xname")
+	t, err := time.Parse(time.RFC3339, chi.URLParam(r, "time"))
+	if err != nil {
+		sendJSONError(w, http.StatusBadRequest,
+			fmt.Sprintf("Invalid time %s, expected RFC3339", chi.URLParam(r, "time")))
+		return
+	}
+	data, err := ioutil.ReadFile(crashSnapshotPath(crashSnapshotDir, xname, t))
+	if err != nil {
+		sendJSONError(w, http.StatusNotFound,
+			fmt.Sprintf("No crash snapshot for %s at %s", xname, formatTime(t)))
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
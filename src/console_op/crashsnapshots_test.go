@@ -0,0 +1,84 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCrashSnapshots(t *testing.T) {
+	defer func(d string) {
+		crashSnapshotDir = d
+		crashScanOffsets = make(map[string]int64)
+		pendingCrashes = make(map[string]pendingCrash)
+	}(crashSnapshotDir)
+	dir, err := ioutil.TempDir("", "crashsnapshots")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	logDir := filepath.Join(dir, "conman")
+	os.Mkdir(logDir, 0755)
+	crashSnapshotDir = filepath.Join(dir, "snapshots")
+	logFile := filepath.Join(logDir, "console.x1000c0s0b0n0")
+
+	// output from before the first check is not scanned
+	ioutil.WriteFile(logFile, []byte("Kernel panic - not syncing: old\n"), 0644)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	checkCrashes(logDir, now)
+	if len(pendingCrashes) != 0 {
+		t.Errorf("Expected: 0. Got: %d.", len(pendingCrashes))
+	}
+
+	f, _ := os.OpenFile(logFile, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("booting\n[  100.0] Kernel panic - not syncing: Fatal exception\n")
+	checkCrashes(logDir, now)
+	if pc, found := pendingCrashes["x1000c0s0b0n0"]; !found || !strings.Contains(pc.signature, "Fatal exception") {
+		t.Errorf("Expected a pending crash. Got: %v.", pendingCrashes)
+	}
+	f.WriteString("[  100.1] Call Trace:\n")
+	f.Close()
+
+	// saved once the output after the crash has had time to be written
+	checkCrashes(logDir, now.Add(crashSnapshotPostWait))
+	snaps := listCrashSnapshots(crashSnapshotDir, "x1000c0s0b0n0")
+	if len(snaps) != 1 || snaps[0].Time != "2026-01-02T03:04:05Z" || !strings.Contains(snaps[0].Signature, "Fatal exception") {
+		t.Fatalf("Expected one snapshot. Got: %v.", snaps)
+	}
+	data, _ := ioutil.ReadFile(crashSnapshotPath(crashSnapshotDir, "x1000c0s0b0n0", now))
+	if !strings.Contains(string(data), "booting") || !strings.Contains(string(data), "Call Trace:") {
+		t.Errorf("Expected the output around the crash. Got: %s.", data)
+	}
+
+	pruneCrashSnapshots(crashSnapshotDir, now.Add(time.Duration(crashSnapshotRetentionDays+1)*24*time.Hour))
+	if snaps = listCrashSnapshots(crashSnapshotDir, "x1000c0s0b0n0"); len(snaps) != 0 {
+		t.Errorf("Expected: 0. Got: %d.", len(snaps))
+	}
+}
@@ -0,0 +1,146 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to notice when the credentials of the river
+//  bmcs change in vault and have console-node reconnect those consoles
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/tidwall/gjson"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// How often to check the river bmc credentials - 0 disables the check
+var riverCredCheckPeriodSec int = 0
+
+// Hash of the last seen credentials of each river bmc
+// NOTE: only a hash is kept so the credentials are never held here
+var riverCredHashes map[string]string = make(map[string]string)
+
+// Get the hash of the credentials of a bmc from the vault hms-creds store
+func vaultGetBmcCredHash(vaultToken, bmc string) (string, error) {
+	URL := vaultBase + "/secret/hms-creds/" + bmc
+	response, responseCode, err := getURL(URL, map[string]string{"X-Vault-Token": vaultToken})
+	if err != nil {
+		return "", err
+	}
+	if responseCode != http.StatusOK {
+		return "", fmt.Errorf("Unexpected response from Vault for %s credentials, Http response code: %d", bmc, responseCode)
+	}
+	user := gjson.Get(string(response), "data.Username").String()
+	pass := gjson.Get(string(response), "data.Password").String()
+	sum := sha256.Sum256([]byte(user + "\x00" + pass))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Find the bmcs whose credentials changed - bmcs seen for the first time
+// are not a change
+func changedCredBmcs(oldHashes, newHashes map[string]string) []string {
+	var changed []string = nil
+	for bmc, h := range newHashes {
+		if old, found := oldHashes[bmc]; found && old != h {
+			changed = append(changed, bmc)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Loop to check the river bmc credentials for changes
+func watchRiverCreds(ctx context.Context, ds DataService) {
+	if riverCredCheckPeriodSec <= 0 {
+		log.Printf("River credential refresh disabled")
+		return
+	}
+	for {
+		if !debugOnly && !reconcileStopped() {
+			checkRiverCreds(ds)
+		}
+		if !sleepCtx(ctx, time.Duration(riverCredCheckPeriodSec)*time.Second) {
+			log.Printf("Stopping river credential checks")
+			return
+		}
+	}
+}
+
+// Refresh the consoles of the river nodes whose bmc credentials changed
+// NOTE: the nodes are released in console-data and added back so the
+// console-node pods reacquire them and read the new credentials
+func checkRiverCreds(ds DataService) {
+	// NOTE - not thread safe, but should be ok
+	bmcNodes := make(map[string][]nodeConsoleInfo)
+	for _, n := range nodeCache {
+		if n.isRiver() {
+			bmcNodes[n.BmcName] = append(bmcNodes[n.BmcName], n)
+		}
+	}
+	if len(bmcNodes) == 0 {
+		return
+	}
+
+	vaultToken, err := vaultLogin()
+	if err != nil {
+		log.Printf("Unable to check river credentials: %s", err)
+		return
+	}
+	newHashes := make(map[string]string)
+	for bmc := range bmcNodes {
+		h, err := vaultGetBmcCredHash(vaultToken, bmc)
+		if err != nil {
+			// keep the last known hash so a failed read is not a change
+			if old, found := riverCredHashes[bmc]; found {
+				newHashes[bmc] = old
+			}
+			continue
+		}
+		newHashes[bmc] = h
+	}
+	changed := changedCredBmcs(riverCredHashes, newHashes)
+	riverCredHashes = newHashes
+	if len(changed) == 0 {
+		return
+	}
+
+	var nodes []nodeConsoleInfo = nil
+	for _, bmc := range changed {
+		nodes = append(nodes, bmcNodes[bmc]...)
+	}
+	log.Printf("Credentials changed for %d river bmcs, refreshing %d consoles", len(changed), len(nodes))
+	ds.dataRemoveNodes(nodes)
+	if !ds.dataAddNodes(nodes) {
+		log.Printf("Unable to add back the refreshed consoles, they will be added on the next full update")
+	}
+	recordEvent(eventOnOperator, corev1.EventTypeNormal, "CredentialsRefreshed",
+		fmt.Sprintf("Refreshed %d river consoles after the credentials of %d bmcs changed", len(nodes), len(changed)))
+}
@@ -0,0 +1,43 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"testing"
+)
+
+func TestChangedCredBmcs(t *testing.T) {
+	oldHashes := map[string]string{"x3000c0s1b0": "a", "x3000c0s3b0": "b", "x3000c0s5b0": "c"}
+	newHashes := map[string]string{"x3000c0s1b0": "a", "x3000c0s3b0": "z", "x3000c0s7b0": "d"}
+	changed := changedCredBmcs(oldHashes, newHashes)
+	if len(changed) != 1 || changed[0] != "x3000c0s3b0" {
+		t.Errorf("Expected: [x3000c0s3b0]. Got: %v.", changed)
+	}
+
+	// nothing has changed the first time the credentials are seen
+	if changed := changedCredBmcs(map[string]string{}, newHashes); len(changed) != 0 {
+		t.Errorf("Expected: 0. Got: %d.", len(changed))
+	}
+}
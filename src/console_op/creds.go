@@ -1,7 +1,7 @@
 //
 //  MIT License
 //
-//  (C) Copyright 2020-2024 Hewlett Packard Enterprise Development LP
+//  (C) Copyright 2020-2024, 2026 Hewlett Packard Enterprise Development LP
 //
 //  Permission is hereby granted, free of charge, to any person obtaining a
 //  copy of this software and associated documentation files (the "Software"),
@@ -28,15 +28,18 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"os/exec"
+	"strconv"
 	"time"
 
 	"github.com/tidwall/gjson"
+	corev1 "k8s.io/api/core/v1"
 )
 
 // Location of the Mountain BMC console ssh key pair files.
@@ -75,7 +78,7 @@ type scsdNode struct {
 // to have Vault create the key when it is missing or to enable future support
 // for key rotation.  When a future REST api is added to support Conman operations
 // this method should provide the backing support for key rotation.
-func vaultGeneratePrivateKey(vaultToken string) (response []byte, responseCode int, err error) {
+func vaultGeneratePrivateKey(vaultToken, keyName string) (response []byte, responseCode int, err error) {
 	// Create the parameters
 	vaultParam := map[string]string{
 		"type":       vaultBmcKeyAlg,
@@ -89,7 +92,7 @@ func vaultGeneratePrivateKey(vaultToken string) (response []byte, responseCode i
 	}
 
 	// Tell vault to create the private key
-	URL := vaultBase + "/transit/keys/" + vaultBmcKeyName
+	URL := vaultBase + "/transit/keys/" + keyName
 	vaultRequestHeaders := make(map[string]string)
 	vaultRequestHeaders["X-Vault-Token"] = vaultToken
 	response, responseCode, err = postURL(URL, jsonVaultParam, vaultRequestHeaders)
@@ -109,13 +112,13 @@ func vaultGeneratePrivateKey(vaultToken string) (response []byte, responseCode i
 			response, responseCode)
 	}
 
-	log.Printf("A new secret for %s was generated in vault.", vaultBmcKeyName)
+	log.Printf("A new secret for %s was generated in vault.", keyName)
 	return response, responseCode, nil
 }
 
 // Ask vault for the private key
-func vaultExportPrivateKey(vaultToken string) (pvtKey string, response []byte, responseCode int, err error) {
-	URL := vaultBase + "/transit/export/signing-key/" + vaultBmcKeyName
+func vaultExportPrivateKey(vaultToken, keyName string) (pvtKey string, response []byte, responseCode int, err error) {
+	URL := vaultBase + "/transit/export/signing-key/" + keyName
 	vaultRequestHeaders := make(map[string]string)
 	vaultRequestHeaders["X-Vault-Token"] = vaultToken
 	response, responseCode, err = getURL(URL, vaultRequestHeaders)
@@ -123,18 +126,18 @@ func vaultExportPrivateKey(vaultToken string) (pvtKey string, response []byte, r
 	if err != nil {
 		log.Printf(
 			"Unable to get the %s secret from vault: %s  Error was: %s",
-			vaultBmcKeyName, vaultBase, err)
+			keyName, vaultBase, err)
 		return "", response, responseCode, fmt.Errorf("Unable to get the %s secret from vault: %s  Error was: %s",
-			vaultBmcKeyName, vaultBase, err)
+			keyName, vaultBase, err)
 	}
 
 	if responseCode == 404 {
-		log.Printf("The vault secret %s was not found. It will need to be created.", vaultBmcKeyName)
+		log.Printf("The vault secret %s was not found. It will need to be created.", keyName)
 
 		return "", response, 404, nil
 	} else if responseCode == 200 {
-		// Return the secret we found
-		jsonElem := "data.keys.1" // See https://github.com/tidwall/gjson#path-syntax
+		// Return the secret we found - the latest version if it was rotated
+		jsonElem := "data.keys." + latestKeyVersion(gjson.Get(string(response), "data.keys")) // See https://github.com/tidwall/gjson#path-syntax
 		pvtKey := gjson.Get(string(response), jsonElem)
 		if len(pvtKey.String()) == 0 {
 			log.Printf(
@@ -154,14 +157,26 @@ func vaultExportPrivateKey(vaultToken string) (pvtKey string, response []byte, r
 	}
 }
 
+// Find the latest version in a map of vault key versions
+func latestKeyVersion(keys gjson.Result) string {
+	latest := 1
+	keys.ForEach(func(k, v gjson.Result) bool {
+		if ver, err := strconv.Atoi(k.String()); err == nil && ver > latest {
+			latest = ver
+		}
+		return true
+	})
+	return strconv.Itoa(latest)
+}
+
 // Obtain the private key from Vault.  The private key (aka Vault secret) is the
 // only piece of the key pair which is stored in Vault.  The public key piece is
 // created from the private via the standard ssh-keygen utility.
 // If the private key can not be found then vault will be asked to generate and
 // return the new key.
-func vaultGetPrivateKey(vaultToken string) (pvtKey string, err error) {
+func vaultGetPrivateKey(vaultToken, keyName string) (pvtKey string, err error) {
 	// Ask vault for the existing key
-	pvtKey, response, responseCode, err := vaultExportPrivateKey(vaultToken)
+	pvtKey, response, responseCode, err := vaultExportPrivateKey(vaultToken, keyName)
 	if err != nil {
 		return "", err
 	}
@@ -171,7 +186,7 @@ func vaultGetPrivateKey(vaultToken string) (pvtKey string, err error) {
 		return pvtKey, nil
 	} else if responseCode == 404 {
 		// Ask vault to generate a private key.
-		response, responseCode, err := vaultGeneratePrivateKey(vaultToken)
+		response, responseCode, err := vaultGeneratePrivateKey(vaultToken, keyName)
 		if err != nil {
 			return "", err
 		}
@@ -184,7 +199,7 @@ func vaultGetPrivateKey(vaultToken string) (pvtKey string, err error) {
 		}
 
 		// Ask vault again to export the newly generated private key.
-		pvtKey, response, responseCode, err = vaultExportPrivateKey(vaultToken)
+		pvtKey, response, responseCode, err = vaultExportPrivateKey(vaultToken, keyName)
 		if err != nil {
 			return "", err
 		}
@@ -205,18 +220,12 @@ func vaultGetPrivateKey(vaultToken string) (pvtKey string, err error) {
 	}
 }
 
-// Obtain Mountain node BMC credentials from Vault and stage them to the
-// local file system.  A specific error will be returned in the event of
-// any issues.
-func vaultGetMountainConsoleCredentials() error {
-	// Generate an ssh key pair (/etc/conman.key and /etc/conman.key.pub)
-	// This will overwrite the existing public or private key files.
-
-	// Authenticate to Vault
+// Authenticate to Vault and return the client token
+func vaultLogin() (string, error) {
 	svcAcctToken, err := ioutil.ReadFile(svcAcctTokenFile)
 	if err != nil {
-		log.Printf("Unable to read the service account token file: %s  Can not authenticate to vault.", err)
-		return fmt.Errorf("Unable to read the service account token file: %s can not authenticate to vault", err)
+		logError(errVault, "Unable to read the service account token file: %s  Can not authenticate to vault.", err)
+		return "", fmt.Errorf("Unable to read the service account token file: %s can not authenticate to vault", err)
 	}
 
 	vaultAuthParam := map[string]string{
@@ -227,30 +236,58 @@ func vaultGetMountainConsoleCredentials() error {
 	log.Printf("Attempting to authenticate to Vault at: %s", URL)
 	response, responseCode, err := postURL(URL, jsonVaultAuthParam, nil)
 	if err != nil {
-		log.Printf("Unable to authenticate to Vault: %s", err)
-		return fmt.Errorf("Unable to authenticate to Vault: %s", err)
+		logError(errVault, "Unable to authenticate to Vault: %s", err)
+		return "", fmt.Errorf("Unable to authenticate to Vault: %s", err)
 	}
 	// If the response code is not 200 then we failed authentication.
 	if responseCode != 200 {
 		log.Printf(
 			"Vault authentication failed.  Response code: %d  Message: %s",
 			responseCode, string(response))
-		return fmt.Errorf(
+		return "", fmt.Errorf(
 			"Vault authentication failed.  Response code: %d  Message: %s",
 			responseCode, string(response))
 	}
-	log.Printf("Vault authentication was successful.  Attempting to get BMC console key from vault")
-	vaultToken := gjson.Get(string(response), "auth.client_token")
+	log.Printf("Vault authentication was successful.")
+	return gjson.Get(string(response), "auth.client_token").String(), nil
+}
+
+// Obtain Mountain node BMC credentials from Vault and stage them to the
+// local file system.  A specific error will be returned in the event of
+// any issues.
+func vaultGetMountainConsoleCredentials() error {
+	// Generate an ssh key pair (/etc/conman.key and /etc/conman.key.pub)
+	// This will overwrite the existing public or private key files.
+
+	// Authenticate to Vault
+	vaultToken, err := vaultLogin()
+	if err != nil {
+		return err
+	}
 
 	// Get the private key from Vault.
-	pvtKey, err := vaultGetPrivateKey(vaultToken.String())
+	log.Printf("Attempting to get BMC console key from vault")
+	pvtKey, err := vaultGetPrivateKey(vaultToken, vaultBmcKeyName)
 	if err != nil {
 		return err
 	}
 	log.Printf("Obtained BMC console key from vault.")
+	if err = writeMountainConsoleKeys(pvtKey); err != nil {
+		return err
+	}
+	cacheMountainConsoleKey(pvtKey)
+	return nil
+}
+
+// Write the private key to the local file system and extract the public key
+func writeMountainConsoleKeys(pvtKey string) error {
+	return writeConsoleKeyFiles(pvtKey, mountainConsoleKey, mountainConsoleKeyPub)
+}
 
+// Write a private key and its public key to the given files
+func writeConsoleKeyFiles(pvtKey, keyFile, pubKeyFile string) error {
 	// Write the private key to the local file system.
-	err = ioutil.WriteFile(mountainConsoleKey, []byte(pvtKey), 0600)
+	err := ioutil.WriteFile(keyFile, []byte(pvtKey), 0600)
 	if err != nil {
 		log.Printf("Failed to write our the private ssh key received from Vault.")
 		return err
@@ -260,12 +297,12 @@ func vaultGetMountainConsoleCredentials() error {
 	log.Printf("Attempting to obtain BMC public console key.")
 	var outBuf bytes.Buffer
 	cmd := exec.Command("sh", "-c", fmt.Sprintf("ssh-keygen -yf %s > %s",
-		mountainConsoleKey, mountainConsoleKeyPub))
+		keyFile, pubKeyFile))
 	cmd.Stderr = &outBuf
 	cmd.Stdout = &outBuf
 	err = cmd.Run()
 	if err != nil {
-		log.Printf("Error extracting the public key: %s", err)
+		logError(errKeyGeneration, "Error extracting the public key: %s", err)
 		return err
 	}
 	log.Printf("Successfully obtained BMC public console key.")
@@ -288,7 +325,7 @@ func generateMountainConsoleCredentials() error {
 	cmd.Stdout = &outBuf
 	err := cmd.Run()
 	if err != nil {
-		log.Printf("Error generating console key pair: %s", err)
+		logError(errKeyGeneration, "Error generating console key pair: %s", err)
 		return fmt.Errorf("Error generating console key pair: %s", err)
 	}
 	return nil
@@ -310,9 +347,15 @@ func ensureMountainConsoleKeysExist() bool {
 		log.Printf("Obtaining Mountain console credentials from Vault")
 		if err := vaultGetMountainConsoleCredentials(); err != nil {
 			log.Printf("%s", err)
+			// fall back to the last key from vault before making a new one
+			if err := loadCachedMountainConsoleKey(); err == nil {
+				return true
+			}
 			log.Printf("Generating Mountain console credentials.")
 			if err := generateMountainConsoleCredentials(); err != nil {
-				log.Printf("Unable to generate credentials.  Error was: %s", err)
+				logError(errKeyGeneration, "Unable to generate credentials.  Error was: %s", err)
+				recordEvent(eventOnOperator, corev1.EventTypeWarning, "KeyGenerationFailed",
+					fmt.Sprintf("Unable to get or generate the mountain console keys: %s", err))
 				return false
 			}
 		}
@@ -320,45 +363,154 @@ func ensureMountainConsoleKeysExist() bool {
 	return true
 }
 
+// Backoff between retries of a failed key deployment to a node
+const keyRetryMinBackoff = 60 * time.Second
+const keyRetryMaxBackoff = 30 * time.Minute
+
+// Retry state of a node whose key deployment failed
+type keyRetry struct {
+	attempts int
+	next     time.Time
+}
+
+// Time to wait before the next attempt after the given number of failures
+func keyRetryBackoff(attempts int) time.Duration {
+	d := keyRetryMinBackoff
+	for i := 1; i < attempts && d < keyRetryMaxBackoff; i++ {
+		d *= 2
+	}
+	if d > keyRetryMaxBackoff {
+		d = keyRetryMaxBackoff
+	}
+	return d
+}
+
+// Get the nodes that are not waiting out a retry backoff
+func dueKeyNodes(nodesToUpdate map[string]nodeConsoleInfo, retries map[string]keyRetry, now time.Time) map[string]nodeConsoleInfo {
+	due := make(map[string]nodeConsoleInfo)
+	for xname, node := range nodesToUpdate {
+		if r, found := retries[xname]; found && now.Before(r.next) {
+			continue
+		}
+		due[xname] = node
+	}
+	return due
+}
+
 // Watches the mountainCredsUpdateChannel for new nodes to update
-func doMountainCredsUpdates(mountainCredsUpdateChannel chan nodeConsoleInfo) {
+// NOTE: each node that fails is retried on its own backoff so one dead bmc
+// does not hold up or repeat the deployment to the rest
+func doMountainCredsUpdates(ctx context.Context, mountainCredsUpdateChannel chan nodeConsoleInfo) {
 	nodesToUpdate := make(map[string]nodeConsoleInfo)
+	retries := make(map[string]keyRetry)
 	for {
 		select {
+		case <-ctx.Done():
+			log.Printf("Stopping mountain key updates, %d pending", len(nodesToUpdate))
+			return
 		case node := <-mountainCredsUpdateChannel:
+			// a node sent again is tried right away
 			nodesToUpdate[node.NodeName] = node
+			delete(retries, node.NodeName)
+			setPendingKeyNodes(nodesToUpdate)
 		case <-time.After(time.Second):
-			// If no new nodes come in for 1 second, send the current batch
-			updateCount := len(nodesToUpdate)
-			if updateCount > 0 {
-				log.Printf("Updating mountain keys for %d nodes", updateCount)
-				nodesToUpdate = doMountainCredsUpdate(nodesToUpdate)
-				remainingCount := len(nodesToUpdate)
-				if remainingCount > 0 {
-					log.Printf("%d out of %d key updates failed and will be retried", remainingCount, updateCount)
-					// Sleep for 1 minute so we don't flood the system/logs with retries
-					time.Sleep(60 * time.Second)
+			// If no new nodes come in for 1 second, send the nodes that are due
+			now := time.Now()
+			due := dueKeyNodes(nodesToUpdate, retries, now)
+			updateCount := len(due)
+			if updateCount == 0 {
+				continue
+			}
+			xnames := make([]string, 0, updateCount)
+			for xname := range due {
+				xnames = append(xnames, xname)
+			}
+			log.Printf("Updating mountain keys for %d nodes", updateCount)
+			remaining := doMountainCredsUpdate(due)
+			newFailures := 0
+			for _, xname := range xnames {
+				if _, failed := remaining[xname]; failed {
+					r := retries[xname]
+					r.attempts++
+					r.next = now.Add(keyRetryBackoff(r.attempts))
+					retries[xname] = r
+					if r.attempts == 1 {
+						newFailures++
+					}
 				} else {
-					log.Printf("All key updates succeeded")
+					delete(nodesToUpdate, xname)
+					delete(retries, xname)
 				}
 			}
+			setPendingKeyNodes(nodesToUpdate)
+			if remainingCount := len(remaining); remainingCount > 0 {
+				logError(errKeyDeployment, "%d out of %d key updates failed and will be retried", remainingCount, updateCount)
+				if newFailures > 0 {
+					recordEvent(eventOnOperator, corev1.EventTypeWarning, "KeyDeploymentFailed",
+						fmt.Sprintf("%d out of %d mountain console key deployments failed and will be retried", newFailures, updateCount))
+				}
+			} else {
+				log.Printf("All key updates succeeded")
+			}
 		}
 	}
 }
 
 // Takes a list of mountain nodes to update and returns a list of nodes that failed and need to be retried
 func doMountainCredsUpdate(nodesToUpdate map[string]nodeConsoleInfo) (remaining map[string]nodeConsoleInfo) {
+	// nodes using different keys are deployed separately
+	groups := make(map[string]map[string]nodeConsoleInfo)
+	for xname, node := range nodesToUpdate {
+		_, pubKeyFile := keyFilesForNode(node)
+		if groups[pubKeyFile] == nil {
+			groups[pubKeyFile] = make(map[string]nodeConsoleInfo)
+		}
+		groups[pubKeyFile][xname] = node
+	}
+	remaining = make(map[string]nodeConsoleInfo)
+	for pubKeyFile, group := range groups {
+		for xname, node := range doMountainCredsUpdateWithKey(group, pubKeyFile) {
+			remaining[xname] = node
+		}
+	}
+
+	// nodes given the current key while a rotated key is being deployed
+	// need the rotated key too before the consoles are switched to it
+	if keyRotationStaged() {
+		keyRotationMutex.Lock()
+		for xname, node := range nodesToUpdate {
+			_, pubKeyFile := keyFilesForNode(node)
+			if _, failed := remaining[xname]; !failed && rotatedPubKeyFile(node) != pubKeyFile {
+				keyRotationPending[xname] = node
+			}
+		}
+		keyRotationMutex.Unlock()
+	}
+	log.Printf("remaining: %d", len(remaining))
+	return remaining
+}
+
+// Deploy the given public key to a list of mountain nodes and return the nodes that failed
+func doMountainCredsUpdateWithKey(nodesToUpdate map[string]nodeConsoleInfo, pubKeyFile string) (remaining map[string]nodeConsoleInfo) {
 	nodeList := make([]nodeConsoleInfo, len(nodesToUpdate))
 	bmcMap := make(map[string][]string)
 	for nodeKey, node := range nodesToUpdate {
 		nodeList = append(nodeList, node)
 		bmcMap[node.BmcName] = append(bmcMap[node.BmcName], nodeKey)
 	}
-	success, reply := deployMountainConsoleKeys(nodeList)
+	success, reply := deployMountainConsoleKeys(nodeList, pubKeyFile)
 	if !success {
+		for _, node := range nodesToUpdate {
+			recordNodeKeyStatus(node, false, "Unable to deploy keys through scsd")
+		}
 		return nodesToUpdate
 	}
+	reported := make(map[string]struct{})
 	for _, t := range reply.Targets {
+		for _, xname := range bmcMap[t.Xname] {
+			reported[xname] = struct{}{}
+			recordNodeKeyStatus(nodesToUpdate[xname], t.StatusCode == 204, fmt.Sprintf("%d %s", t.StatusCode, t.StatusMsg))
+		}
 		if t.StatusCode == 204 {
 			// BMC update was successful and all associated nodes can be removed from the update list
 			for _, xname := range bmcMap[t.Xname] {
@@ -366,12 +518,16 @@ func doMountainCredsUpdate(nodesToUpdate map[string]nodeConsoleInfo) (remaining
 			}
 		}
 	}
-	log.Printf("remaining: %d", len(nodesToUpdate))
+	for xname, node := range nodesToUpdate {
+		if _, found := reported[xname]; !found {
+			recordNodeKeyStatus(node, false, "No result from scsd")
+		}
+	}
 	return nodesToUpdate
 }
 
 // Deploy mountain node console credentials.
-func deployMountainConsoleKeys(nodes []nodeConsoleInfo) (bool, scsdList) {
+func deployMountainConsoleKeys(nodes []nodeConsoleInfo, pubKeyFile string) (bool, scsdList) {
 	// Ensure that we have a console ssh key pair.  If the key pair
 	// is not on the local file system then obtain it from Vault.  If
 	// Vault is not available or we are otherwise unable to obtain the key
@@ -387,7 +543,7 @@ func deployMountainConsoleKeys(nodes []nodeConsoleInfo) (bool, scsdList) {
 	}
 
 	// Read in the public key.
-	pubKey, err := ioutil.ReadFile(mountainConsoleKeyPub)
+	pubKey, err := ioutil.ReadFile(pubKeyFile)
 	if err != nil {
 		log.Printf("Unable to read the public key file: %s", err)
 		return false, scsdReply
@@ -432,7 +588,7 @@ func deployMountainConsoleKeys(nodes []nodeConsoleInfo) (bool, scsdList) {
 
 	err = json.Unmarshal(data, &scsdReply)
 	if err != nil {
-		log.Printf("Error unmarshalling the reply from scsd: %s", err)
+		logError(errKeyDeployment, "Error unmarshalling the reply from scsd: %s", err)
 		return success, scsdReply
 	}
 	for _, t := range scsdReply.Targets {
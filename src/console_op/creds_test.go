@@ -0,0 +1,67 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyRetryBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, keyRetryMinBackoff},
+		{2, 2 * keyRetryMinBackoff},
+		{3, 4 * keyRetryMinBackoff},
+		{100, keyRetryMaxBackoff},
+	}
+	for _, tc := range tests {
+		if got := keyRetryBackoff(tc.attempts); got != tc.expected {
+			t.Errorf("%d: Expected: %s. Got: %s.", tc.attempts, tc.expected, got)
+		}
+	}
+}
+
+func TestDueKeyNodes(t *testing.T) {
+	now := time.Now()
+	nodes := map[string]nodeConsoleInfo{
+		"x1000c0s0b0n0": {NodeName: "x1000c0s0b0n0"},
+		"x1000c0s1b0n0": {NodeName: "x1000c0s1b0n0"},
+		"x1000c0s2b0n0": {NodeName: "x1000c0s2b0n0"},
+	}
+	retries := map[string]keyRetry{
+		"x1000c0s1b0n0": {attempts: 1, next: now.Add(time.Minute)},
+		"x1000c0s2b0n0": {attempts: 3, next: now.Add(-time.Second)},
+	}
+	due := dueKeyNodes(nodes, retries, now)
+	if len(due) != 2 {
+		t.Errorf("Expected: 2. Got: %d.", len(due))
+	}
+	if _, found := due["x1000c0s1b0n0"]; found {
+		t.Errorf("Expected x1000c0s1b0n0 to be waiting out its backoff")
+	}
+}
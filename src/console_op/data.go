@@ -1,7 +1,7 @@
 //
 //  MIT License
 //
-//  (C) Copyright 2021-2024 Hewlett Packard Enterprise Development LP
+//  (C) Copyright 2021-2024, 2026 Hewlett Packard Enterprise Development LP
 //
 //  Permission is hereby granted, free of charge, to any person obtaining a
 //  copy of this software and associated documentation files (the "Software"),
@@ -28,11 +28,14 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -44,12 +47,16 @@ var dataAddrBase string = "http://cray-console-data/v1"
 type DataService interface {
 	dataAddNodes(newNodes []nodeConsoleInfo) bool
 	dataRemoveNodes(removedNodes []nodeConsoleInfo)
-	checkHeartbeats()
+	checkHeartbeats(ctx context.Context)
 	doGetPodLocation(w http.ResponseWriter, r *http.Request)
 	doGetNodePod(w http.ResponseWriter, r *http.Request)
 	doGetPodReplicaCount(w http.ResponseWriter, r *http.Request)
-	getNodePodForXname(xname string) (string, error)
+	getNodePodForXname(xname, reqID string) (string, error)
 	doGetCurrentTargets(w http.ResponseWriter, r *http.Request)
+	doGetPodAssignments(w http.ResponseWriter, r *http.Request)
+	doRollingRestart(w http.ResponseWriter, r *http.Request)
+	doGetRollingRestart(w http.ResponseWriter, r *http.Request)
+	watchRollingRestarts(ctx context.Context)
 }
 
 // Implements DataService
@@ -74,7 +81,7 @@ func (DataManager) dataAddNodes(newNodes []nodeConsoleInfo) bool {
 	// NOTE: data is just a simple array of nodeConsoleInfo structs - no packaging
 	data, err := json.Marshal(newNodes)
 	if err != nil {
-		log.Printf("Error marshalling data for add nodes:%s", err)
+		logError(errConsoleData, "Error marshalling data for add nodes:%s", err)
 		return retVal
 	}
 
@@ -82,7 +89,7 @@ func (DataManager) dataAddNodes(newNodes []nodeConsoleInfo) bool {
 	URL := dataAddrBase + "/inventory"
 	rd, rc, err := putURL(URL, data, nil)
 	if err != nil {
-		log.Printf("Error adding new data to console-data inventory: %s", err)
+		logError(errConsoleData, "Error adding new data to console-data inventory: %s", err)
 		return retVal
 	}
 
@@ -97,7 +104,7 @@ func (DataManager) dataAddNodes(newNodes []nodeConsoleInfo) bool {
 	err = json.Unmarshal(rd, &rp)
 	if err != nil {
 		// handle error
-		log.Printf("Error unmarshalling data: %s, bytesArray:%s", err, rd)
+		logError(errConsoleData, "Error unmarshalling data: %s, bytesArray:%s", err, rd)
 	} else {
 		log.Printf("Console-data return message: %s", rp.message)
 	}
@@ -109,7 +116,7 @@ func (DataManager) dataRemoveNodes(removedNodes []nodeConsoleInfo) {
 	// NOTE: data is just a simple array of nodeConsoleInfo structs - no packaging
 	data, err := json.Marshal(removedNodes)
 	if err != nil {
-		log.Printf("Error marshalling data for remove nodes:%s", err)
+		logError(errConsoleData, "Error marshalling data for remove nodes:%s", err)
 		return
 	}
 
@@ -123,7 +130,7 @@ func (DataManager) dataRemoveNodes(removedNodes []nodeConsoleInfo) {
 	URL := dataAddrBase + "/inventory"
 	rd, rc, err := deleteURL(URL, data, nil)
 	if err != nil {
-		log.Printf("Unable to remove elements from console-data: %s", err)
+		logError(errConsoleData, "Unable to remove elements from console-data: %s", err)
 		return
 	}
 
@@ -138,7 +145,7 @@ func (DataManager) dataRemoveNodes(removedNodes []nodeConsoleInfo) {
 			// handle error
 			// TODO - better error handling?  Do we need a retry so if something fails
 			//  it won't get out of sync??
-			log.Printf("Error unmarshalling data: %s", err)
+			logError(errConsoleData, "Error unmarshalling data: %s", err)
 		} else {
 			log.Printf("Console-data return message: %s", rp.message)
 		}
@@ -149,36 +156,130 @@ func (DataManager) dataRemoveNodes(removedNodes []nodeConsoleInfo) {
 }
 
 // trigger a clearing of nodes from a stale pod
-func (DataManager) checkHeartbeats() {
+func (dm DataManager) checkHeartbeats(ctx context.Context) {
 	for {
-		log.Printf("Checking for stale heartbeats")
-		// format the url for the clear API
-		url := fmt.Sprintf("%s/consolepod/%d/clear", dataAddrBase, heartbeatStaleMinutes)
-
-		// call the console-data api
-		_, _, err := deleteURL(url, nil, nil)
-		if err != nil {
-			log.Printf("Error calling console-data clear stale heartbeats:%s", err)
+		if reason := heartbeatSkipReason(time.Now()); reason != "" {
+			log.Printf("%s, skipping stale heartbeat check", reason)
+		} else if firmwareUpdateActive() {
+			// NOTE: console-node pods can fall behind while bmcs reset during a
+			//  firmware update, so leave the consoles being updated where they are
+			dm.releaseStaleConsoles()
+		} else {
+			log.Printf("Checking for stale heartbeats")
+			// format the url for the clear API
+			url := fmt.Sprintf("%s/consolepod/%d/clear", dataAddrBase, heartbeatStaleMinutes)
+
+			// call the console-data api
+			_, _, err := deleteURL(url, nil, nil)
+			if err != nil {
+				logError(errConsoleData, "Error calling console-data clear stale heartbeats:%s", err)
+			}
 		}
 
 		// wait for the next interval
-		time.Sleep(time.Duration(heartbeatCheckPeriodSec) * time.Second)
+		if !sleepCtx(ctx, time.Duration(heartbeatCheckPeriodSec)*time.Second) {
+			log.Printf("Stopping stale heartbeat checks")
+			return
+		}
+	}
+}
+
+// Find why the stale heartbeat check should be skipped, empty if it should not
+// NOTE: pods restarting during planned maintenance will have stale
+// heartbeats, don't move their nodes while scaling is frozen
+func heartbeatSkipReason(now time.Time) string {
+	if reconcileStopped() {
+		return "Reconciling stopped"
+	}
+	if frozen, reason := scalingFrozen(now); frozen {
+		return fmt.Sprintf("Scaling changes frozen (%s)", reason)
+	}
+	return ""
+}
+
+// Find the consoles held by pods that are no longer running, leaving out
+// the ones that should not be moved
+func staleConsoles(inv []dataNodeInfo, podNames []string, keep func(xname string) bool) []string {
+	running := make(map[string]struct{}, len(podNames))
+	for _, pn := range podNames {
+		running[pn] = struct{}{}
+	}
+	var xnames []string = nil
+	for _, n := range inv {
+		if n.NodeConsoleName == "" {
+			continue
+		}
+		if _, found := running[fmt.Sprintf("%s-%s", consoleNodeName, n.NodeConsoleName)]; found || keep(n.NodeName) {
+			continue
+		}
+		xnames = append(xnames, n.NodeName)
+	}
+	sort.Strings(xnames)
+	return xnames
+}
 
+// Release the consoles of pods that are gone except the ones in maintenance
+// for a firmware update
+// NOTE: console-data can only clear the stale heartbeats of every console at
+// once, so the consoles are released one by one instead.  A pod that is
+// still running but has stopped its heartbeats is left until the update is
+// done.
+func (dm DataManager) releaseStaleConsoles() {
+	log.Printf("Firmware update in progress, checking for consoles on pods that are gone")
+	podNames, err := dm.k8Service.getConsoleNodePods()
+	if err != nil {
+		return
+	}
+	inv, err := getDataInventory("")
+	if err != nil {
+		return
+	}
+	var nodes []nodeConsoleInfo = nil
+	// NOTE - not thread safe, but should be ok
+	for _, xname := range staleConsoles(inv, podNames, inFirmwareMaintenance) {
+		if n, found := nodeCache[xname]; found {
+			nodes = append(nodes, n)
+		}
+	}
+	if len(nodes) == 0 {
+		return
+	}
+	log.Printf("Releasing %d consoles from pods that are gone", len(nodes))
+	dm.dataRemoveNodes(nodes)
+	if !dm.dataAddNodes(nodes) {
+		logError(errConsoleData, "Unable to release %d stale consoles in console-data", len(nodes))
 	}
 }
 
 // GetNodePodResponse - used to report service health stats
 type GetNodePodResponse struct {
+	PodName string    `json:"podname"`
+	Nodes   []NodePod `json:"nodes,omitempty"`
+}
+
+// NodePod - a node found by a lookup and the pod it is connected to
+type NodePod struct {
+	XName   string `json:"xname"`
+	NID     int    `json:"nid"`
+	Role    string `json:"role"`
+	BmcFqdn string `json:"bmcfqdn"`
 	PodName string `json:"podname"`
 }
 
 // GetNodeData - input data for call to getNodeData
+// NOTE: the xname may also be a nid alias (nid001234) and a role, bmc fqdn
+// or hsm group may match more than one node
 type GetNodeData struct {
-	XName string `json:"xname"`
+	XName   string `json:"xname"`
+	NID     int    `json:"nid"`
+	Role    string `json:"role"`
+	BmcFqdn string `json:"bmcfqdn"`
+	Group   string `json:"group"`
 }
 
 type GetNodeReplicasResponse struct {
-	Replicas int `json:"replicas"`
+	Replicas int               `json:"replicas"`
+	Pools    []consoleNodePool `json:"pools"`
 }
 
 // doGetPodLocation response data
@@ -316,10 +417,17 @@ func (dm DataManager) doGetNodePod(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// look up anything other than a plain xname in the known nodes
+	_, isNid := parseNidAlias(inData.XName)
+	if isNid || inData.XName == "" || inData.NID != 0 || inData.Role != "" || inData.BmcFqdn != "" || inData.Group != "" {
+		dm.doGetNodePodLookup(w, getRequestID(r), inData)
+		return
+	}
+
 	// get the correct pod from the console-data service
-	podName, err := dm.getNodePodForXname(inData.XName)
+	podName, err := dm.getNodePodForXname(inData.XName, getRequestID(r))
 	if err != nil {
-		log.Printf("Error getting console node pod from console-data: %s", err)
+		logError(errConsoleData, "Error getting console node pod from console-data: %s", err)
 		var body = BaseResponse{
 			Msg: fmt.Sprintf("There was an error querying console-data service: %s", err),
 		}
@@ -333,13 +441,54 @@ func (dm DataManager) doGetNodePod(w http.ResponseWriter, r *http.Request) {
 	SendResponseJSON(w, http.StatusOK, res)
 }
 
+// Find the pods for nodes looked up by nid, role, bmc fqdn or hsm group
+func (dm DataManager) doGetNodePodLookup(w http.ResponseWriter, reqID string, inData GetNodeData) {
+	var members map[string]struct{} = nil
+	if inData.Group != "" {
+		var err error
+		if members, err = getHSMGroupMembers(inData.Group, reqID); err != nil {
+			sendJSONError(w, http.StatusNotFound, err.Error())
+			return
+		}
+	}
+
+	// NOTE - not thread safe, but should be ok
+	nodes, err := findNodes(inData, nodeCache, members)
+	if err != nil {
+		sendJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(nodes) == 0 {
+		sendJSONError(w, http.StatusNotFound, "No matching nodes found")
+		return
+	}
+
+	var res GetNodePodResponse
+	for _, n := range nodes {
+		podName, err := dm.getNodePodForXname(n.NodeName, reqID)
+		if err != nil {
+			logError(errConsoleData, "Error getting console node pod from console-data: %s", err)
+			var body = BaseResponse{
+				Msg: fmt.Sprintf("There was an error querying console-data service: %s", err),
+			}
+			SendResponseJSON(w, http.StatusInternalServerError, body)
+			return
+		}
+		res.Nodes = append(res.Nodes, NodePod{XName: n.NodeName, NID: n.NID, Role: n.Role, BmcFqdn: n.BmcFqdn, PodName: podName})
+	}
+	if len(res.Nodes) == 1 {
+		res.PodName = res.Nodes[0].PodName
+	}
+	SendResponseJSON(w, http.StatusOK, res)
+}
+
 // query the console-data service for the correct pod
-func (DataManager) getNodePodForXname(xname string) (string, error) {
+func (DataManager) getNodePodForXname(xname, reqID string) (string, error) {
 	// now we have the name the user is looking for, put the request to console-data
 	url := fmt.Sprintf("%s/consolepod/%s", dataAddrBase, xname)
-	rd, _, err := getURL(url, nil)
+	rd, _, err := getURL(url, requestIDHeaders(reqID))
 	if err != nil {
-		log.Printf("Error getting console node pod from console-data: %s", err)
+		logError(errConsoleData, "Error getting console node pod from console-data: %s", err)
 		return "", err
 	}
 
@@ -357,12 +506,12 @@ func (DataManager) getNodePodForXname(xname string) (string, error) {
 	var nd RetNodeConsoleInfo
 	err = json.Unmarshal(rd, &nd)
 	if err != nil {
-		log.Printf("Error unmarshalling data from console-data: %s", err)
+		logError(errConsoleData, "Error unmarshalling data from console-data: %s", err)
 		return "", err
 	}
 
 	// return the result
-	return fmt.Sprintf("cray-console-node-%s", nd.NodeConsoleName), nil
+	return fmt.Sprintf("%s-%s", consoleNodeName, nd.NodeConsoleName), nil
 }
 
 func (dm DataManager) doGetPodReplicaCount(w http.ResponseWriter, r *http.Request) {
@@ -376,7 +525,7 @@ func (dm DataManager) doGetPodReplicaCount(w http.ResponseWriter, r *http.Reques
 
 	nodeRepCount, err := dm.k8Service.getReplicaCount()
 	if err != nil {
-		log.Printf("Error: There was an error while retrieving console-node replica counts: %s\n", err)
+		logError(errK8s, "Error: There was an error while retrieving console-node replica counts: %s", err)
 		var body = BaseResponse{
 			Msg: fmt.Sprintf("There was an error while retrieving console-node replica counts: %s\n", err),
 		}
@@ -385,6 +534,7 @@ func (dm DataManager) doGetPodReplicaCount(w http.ResponseWriter, r *http.Reques
 
 	var resp GetNodeReplicasResponse
 	resp.Replicas = nodeRepCount
+	resp.Pools = getConsoleNodePools()
 	SendResponseJSON(w, http.StatusOK, resp)
 }
 
@@ -407,3 +557,168 @@ func (dm DataManager) doGetCurrentTargets(w http.ResponseWriter, r *http.Request
 	resp.TargetNumNodePods = numNodePods
 	SendResponseJSON(w, http.StatusOK, resp)
 }
+
+// Node information as returned from the console-data inventory
+type dataNodeInfo struct {
+	NodeName        string `json:"nodename"`        // node xname
+	Class           string `json:"class"`           // river/mtn class
+	NodeConsoleName string `json:"nodeconsolename"` // the pod console
+}
+
+// Per pod target and acquired node counts
+type PodAssignment struct {
+	PodName           string `json:"podname"`
+	Running           bool   `json:"running"`
+	TargetNumMtnNodes int    `json:"targetnummtnnodes"`
+	TargetNumRvrNodes int    `json:"targetnumrvrnodes"`
+	AcquiredMtnNodes  int    `json:"acquiredmtnnodes"`
+	AcquiredRvrNodes  int    `json:"acquiredrvrnodes"`
+	HeadroomMtnNodes  int    `json:"headroommtnnodes"`
+	HeadroomRvrNodes  int    `json:"headroomrvrnodes"`
+}
+
+// doGetPodAssignments response data
+type GetPodAssignmentsResponse struct {
+	UnassignedMtnNodes int             `json:"unassignedmtnnodes"`
+	UnassignedRvrNodes int             `json:"unassignedrvrnodes"`
+	Pods               []PodAssignment `json:"pods"`
+}
+
+// Keep only the inventory of the given nodes
+func filterInventory(inv []dataNodeInfo, members map[string]struct{}) []dataNodeInfo {
+	var filtered []dataNodeInfo = nil
+	for _, n := range inv {
+		if _, found := members[strings.ToLower(n.NodeName)]; found {
+			filtered = append(filtered, n)
+		}
+	}
+	return filtered
+}
+
+// Get the current node inventory from console-data including which pod has
+// acquired each node
+// NOTE: reqID is the id of the request being handled, or empty for a new one
+func getDataInventory(reqID string) ([]dataNodeInfo, error) {
+	rd, _, err := getURL(dataAddrBase+"/inventory", requestIDHeaders(reqID))
+	if err != nil {
+		logError(errConsoleData, "Error getting inventory from console-data: %s", err)
+		return nil, err
+	}
+	var inv []dataNodeInfo
+	if err = json.Unmarshal(rd, &inv); err != nil {
+		logError(errConsoleData, "Error unmarshalling inventory from console-data: %s", err)
+		return nil, err
+	}
+	return inv, nil
+}
+
+// Combine the running pods, their targets, and the console-data inventory
+// into the per pod assignment counts
+// NOTE: pods holding nodes that are no longer running are reported too so
+// nodes stuck on a stale pod can be seen
+func calcPodAssignments(podNames []string, targets []podTarget, inv []dataNodeInfo) GetPodAssignmentsResponse {
+	var resp GetPodAssignmentsResponse
+	pods := make(map[string]*PodAssignment)
+	var order []string = nil
+	getPod := func(podName string) *PodAssignment {
+		pa, ok := pods[podName]
+		if !ok {
+			// default to the shared even split targets
+			pa = &PodAssignment{PodName: podName, TargetNumMtnNodes: numMtnNodesPerPod, TargetNumRvrNodes: numRvrNodesPerPod}
+			pods[podName] = pa
+			order = append(order, podName)
+		}
+		return pa
+	}
+	for _, pn := range podNames {
+		getPod(pn).Running = true
+	}
+	for _, pt := range targets {
+		pa := getPod(pt.PodName)
+		pa.TargetNumMtnNodes = pt.TargetNumMtnNodes
+		pa.TargetNumRvrNodes = pt.TargetNumRvrNodes
+	}
+
+	// count up what each pod has acquired
+	for _, n := range inv {
+		node := nodeConsoleInfo{NodeName: n.NodeName, Class: n.Class}
+		isRvr := node.isRiver()
+		if n.NodeConsoleName == "" {
+			if isRvr {
+				resp.UnassignedRvrNodes++
+			} else {
+				resp.UnassignedMtnNodes++
+			}
+			continue
+		}
+		pa := getPod(fmt.Sprintf("%s-%s", consoleNodeName, n.NodeConsoleName))
+		if isRvr {
+			pa.AcquiredRvrNodes++
+		} else {
+			pa.AcquiredMtnNodes++
+		}
+	}
+
+	sort.Strings(order)
+	for _, pn := range order {
+		pa := pods[pn]
+		pa.HeadroomMtnNodes = pa.TargetNumMtnNodes - pa.AcquiredMtnNodes
+		pa.HeadroomRvrNodes = pa.TargetNumRvrNodes - pa.AcquiredRvrNodes
+		resp.Pods = append(resp.Pods, *pa)
+	}
+	return resp
+}
+
+// Report the target, acquired, and headroom node counts of each console-node pod
+func (dm DataManager) doGetPodAssignments(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	podNames, err := dm.k8Service.getConsoleNodePods()
+	if err != nil {
+		sendJSONError(w, http.StatusInternalServerError,
+			fmt.Sprintf("Unable to get the console-node pods: %s", err))
+		return
+	}
+	inv, err := getDataInventory(getRequestID(r))
+	if err != nil {
+		sendJSONError(w, http.StatusInternalServerError,
+			fmt.Sprintf("Unable to get the inventory from console-data: %s", err))
+		return
+	}
+
+	// `?group=<label>` only counts the nodes in an hsm group
+	if group := r.URL.Query().Get("group"); group != "" {
+		members, err := getHSMGroupMembers(group, getRequestID(r))
+		if err != nil {
+			sendJSONError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		inv = filterInventory(inv, members)
+	}
+
+	// NOTE: per pod targets only exist when distributing by capacity
+	var targets []podTarget = nil
+	if capacityDistribution {
+		targets = getPodTargets()
+	}
+	resp := calcPodAssignments(podNames, targets, inv)
+
+	// `/console-operator/v1/podAssignments/{podID}` narrows to a single pod
+	if podID := chi.URLParam(r, "podID"); podID != "" {
+		for _, pa := range resp.Pods {
+			if pa.PodName == podID {
+				SendResponseJSON(w, http.StatusOK, pa)
+				return
+			}
+		}
+		sendJSONError(w, http.StatusNotFound, fmt.Sprintf("Pod %s not found", podID))
+		return
+	}
+	SendResponseJSON(w, http.StatusOK, resp)
+}
@@ -1,7 +1,7 @@
 //
 //  MIT License
 //
-//  (C) Copyright 2023 Hewlett Packard Enterprise Development LP
+//  (C) Copyright 2023, 2026 Hewlett Packard Enterprise Development LP
 //
 //  Permission is hereby granted, free of charge, to any person obtaining a
 //  copy of this software and associated documentation files (the "Software"),
@@ -30,6 +30,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -122,3 +123,75 @@ func TestDoGetPodReplicaCount(t *testing.T) {
 		t.Errorf("Expected: %d. Got: %d.", eReplicas, resp.Replicas)
 	}
 }
+
+func TestCalcPodAssignments(t *testing.T) {
+	// restore the globals when done
+	oldMtn, oldRvr := numMtnNodesPerPod, numRvrNodesPerPod
+	defer func() { numMtnNodesPerPod, numRvrNodesPerPod = oldMtn, oldRvr }()
+	numMtnNodesPerPod = 5
+	numRvrNodesPerPod = 4
+	podNames := []string{"cray-console-node-0", "cray-console-node-1"}
+	targets := []podTarget{{PodName: "cray-console-node-1", TargetNumMtnNodes: 8, TargetNumRvrNodes: 6}}
+	inv := []dataNodeInfo{
+		{NodeName: "x1000c0s0b0n0", Class: "Mountain", NodeConsoleName: "0"},
+		{NodeName: "x1000c0s0b0n1", Class: "Mountain", NodeConsoleName: "1"},
+		{NodeName: "x3000c0s1b0n0", Class: "River", NodeConsoleName: "1"},
+		{NodeName: "x3000c0s2b0n0", Class: "River", NodeConsoleName: "2"},
+		{NodeName: "x3000c0s3b0n0", Class: "River", NodeConsoleName: ""},
+	}
+
+	resp := calcPodAssignments(podNames, targets, inv)
+	if resp.UnassignedRvrNodes != 1 || resp.UnassignedMtnNodes != 0 {
+		t.Errorf("Expected: 1 river and 0 mountain unassigned. Got: %d river and %d mountain.",
+			resp.UnassignedRvrNodes, resp.UnassignedMtnNodes)
+	}
+	expected := []PodAssignment{
+		{PodName: "cray-console-node-0", Running: true, TargetNumMtnNodes: 5, TargetNumRvrNodes: 4,
+			AcquiredMtnNodes: 1, HeadroomMtnNodes: 4, HeadroomRvrNodes: 4},
+		{PodName: "cray-console-node-1", Running: true, TargetNumMtnNodes: 8, TargetNumRvrNodes: 6,
+			AcquiredMtnNodes: 1, AcquiredRvrNodes: 1, HeadroomMtnNodes: 7, HeadroomRvrNodes: 5},
+		// a pod that is gone but still holds a node
+		{PodName: "cray-console-node-2", Running: false, TargetNumMtnNodes: 5, TargetNumRvrNodes: 4,
+			AcquiredRvrNodes: 1, HeadroomMtnNodes: 5, HeadroomRvrNodes: 3},
+	}
+	if len(resp.Pods) != len(expected) {
+		t.Fatalf("Expected: %d pods. Got: %d.", len(expected), len(resp.Pods))
+	}
+	for i, pa := range resp.Pods {
+		if pa != expected[i] {
+			t.Errorf("Expected: %v. Got: %v.", expected[i], pa)
+		}
+	}
+}
+
+func TestStaleConsoles(t *testing.T) {
+	podNames := []string{"cray-console-node-0"}
+	inv := []dataNodeInfo{
+		{NodeName: "x1000c0s0b0n0", NodeConsoleName: "0"},
+		{NodeName: "x1000c0s0b0n1", NodeConsoleName: "1"},
+		{NodeName: "x1000c0s1b0n0", NodeConsoleName: "1"},
+		{NodeName: "x3000c0s1b0n0", NodeConsoleName: ""},
+	}
+	// the console being updated stays on the pod that is gone
+	keep := func(xname string) bool { return xname == "x1000c0s1b0n0" }
+
+	got := staleConsoles(inv, podNames, keep)
+	if len(got) != 1 || got[0] != "x1000c0s0b0n1" {
+		t.Errorf("Expected: [x1000c0s0b0n1]. Got: %v.", got)
+	}
+}
+
+func TestHeartbeatSkipReason(t *testing.T) {
+	oldShutdown := inShutdown
+	defer func() { inShutdown = oldShutdown }()
+	now := time.Now()
+
+	inShutdown = false
+	if reason := heartbeatSkipReason(now); reason != "" {
+		t.Errorf("Expected no reason to skip. Got: %s.", reason)
+	}
+	inShutdown = true
+	if reason := heartbeatSkipReason(now); reason == "" {
+		t.Errorf("Expected the check skipped while reconciling is stopped")
+	}
+}
@@ -1,7 +1,7 @@
 //
 //  MIT License
 //
-//  (C) Copyright 2021-2024 Hewlett Packard Enterprise Development LP
+//  (C) Copyright 2021-2024, 2026 Hewlett Packard Enterprise Development LP
 //
 //  Permission is hereby granted, free of charge, to any person obtaining a
 //  copy of this software and associated documentation files (the "Software"),
@@ -45,6 +45,8 @@ type DebugService interface {
 	doSuspend(w http.ResponseWriter, r *http.Request)
 	doResume(w http.ResponseWriter, r *http.Request)
 	doSetMaxNodesPerPod(w http.ResponseWriter, r *http.Request)
+	doZombies(w http.ResponseWriter, r *http.Request)
+	doStateDump(w http.ResponseWriter, r *http.Request)
 }
 
 type DebugManager struct {
@@ -128,16 +130,18 @@ func (dm DebugManager) doSetMaxNodesPerPod(w http.ResponseWriter, r *http.Reques
 	// process the results - do a sanity check on the user input
 	log.Printf("Resetting max nodes based on user input: maxMtn: %d, maxRvr: %d", inData.MaxMtnNodes, inData.MaxRvrNodes)
 	ok := true
-	maxMtnNodesPerPod, ok = dm.pinNumNodes(inData.MaxMtnNodes, 2, 750)
+	maxMtnNodesPerPod, ok = dm.pinNumNodes(inData.MaxMtnNodes, minNodesPerPodLimit, maxMtnNodesPerPodLimit)
 	if !ok {
-		log.Printf("Error - invalid max mountain nodes per pod. Asked: %d, defaulted to: %d",
+		logError(errBadRequest, "Error - invalid max mountain nodes per pod. Asked: %d, defaulted to: %d",
 			inData.MaxMtnNodes, maxMtnNodesPerPod)
 	}
-	maxRvrNodesPerPod, ok = dm.pinNumNodes(inData.MaxRvrNodes, 2, 2000)
+	maxRvrNodesPerPod, ok = dm.pinNumNodes(inData.MaxRvrNodes, minNodesPerPodLimit, maxRvrNodesPerPodLimit)
 	if !ok {
-		log.Printf("Error - invalid max river nodes per pod. Asked: %d, defaulted to: %d",
+		logError(errBadRequest, "Error - invalid max river nodes per pod. Asked: %d, defaulted to: %d",
 			inData.MaxRvrNodes, maxRvrNodesPerPod)
 	}
+	setConfigSource("MAX_MTN_NODES_PER_POD", configSourceAPI)
+	setConfigSource("MAX_RVR_NODES_PER_POD", configSourceAPI)
 
 	// write the response
 	w.WriteHeader(http.StatusOK)
@@ -175,7 +179,7 @@ func (dm DebugManager) doInfo(w http.ResponseWriter, r *http.Request) {
 	// keep track of how many nodes are connected to each node-pod
 	tally := make(map[string]int)
 	for nn := range nodeCache {
-		podName, err := dm.dataService.getNodePodForXname(nn)
+		podName, err := dm.dataService.getNodePodForXname(nn, getRequestID(r))
 		if err != nil {
 			tally["Unassigned"] = tally["Unassigned"] + 1
 		} else {
@@ -256,3 +260,24 @@ func (DebugManager) doResume(w http.ResponseWriter, r *http.Request) {
 	// write the response
 	w.WriteHeader(http.StatusOK)
 }
+
+// ZombieHistoryResponse - zombie processes that have been cleaned up
+type ZombieHistoryResponse struct {
+	Zombies []zombieProcess `json:"zombies"`
+}
+
+// Debugging only - report the zombie processes that have been reaped
+func (DebugManager) doZombies(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	// write the response
+	var resp ZombieHistoryResponse
+	resp.Zombies = getZombieHistory()
+	SendResponseJSON(w, http.StatusOK, resp)
+}
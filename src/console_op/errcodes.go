@@ -0,0 +1,238 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the catalog of error codes reported in responses, logs
+//  and events so failures can be documented and acted on by code
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+)
+
+// A stable error code - once published a code keeps its meaning
+type errorCode string
+
+const (
+	// request errors
+	errBadRequest         errorCode = "CO1000"
+	errMethodNotAllowed   errorCode = "CO1001"
+	errNotFound           errorCode = "CO1002"
+	errConflict           errorCode = "CO1003"
+	errReadOnlyReplica    errorCode = "CO1004"
+	errStandbyReplica     errorCode = "CO1005"
+	errInternal           errorCode = "CO1006"
+	errServiceUnavailable errorCode = "CO1007"
+
+	// dependent service errors
+	errConsoleData errorCode = "CO2000"
+	errHSM         errorCode = "CO2001"
+	errTAPMS       errorCode = "CO2002"
+	errPCS         errorCode = "CO2003"
+	errVault       errorCode = "CO2004"
+	errK8s         errorCode = "CO2005"
+	errFAS         errorCode = "CO2006"
+	errSLS         errorCode = "CO2007"
+	errFederation  errorCode = "CO2008"
+
+	// console operation errors
+	errKeyGeneration   errorCode = "CO3000"
+	errKeyDeployment   errorCode = "CO3001"
+	errKeyRotation     errorCode = "CO3002"
+	errConsoleSessions errorCode = "CO3003"
+	errMassNodeRemoval errorCode = "CO3004"
+	errLogQuota        errorCode = "CO3005"
+	errScaling         errorCode = "CO3006"
+	errHandoff         errorCode = "CO3007"
+
+	// operator errors
+	errConfig     errorCode = "CO4000"
+	errFileSystem errorCode = "CO4001"
+	errZombie     errorCode = "CO4002"
+	errAlert      errorCode = "CO4003"
+)
+
+// ErrorCodeInfo - what an error code means and what to do about it
+type ErrorCodeInfo struct {
+	Code        errorCode `json:"code"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Remediation string    `json:"remediation"`
+}
+
+// The catalog of all error codes
+var errorCatalog = map[errorCode]ErrorCodeInfo{
+	errBadRequest: {errBadRequest, "BadRequest",
+		"The request was missing a parameter or had one that was not valid.",
+		"Fix the request using the message returned with the error."},
+	errMethodNotAllowed: {errMethodNotAllowed, "MethodNotAllowed",
+		"The endpoint does not support the http method used.",
+		"Use a method from the Allow header of the response."},
+	errNotFound: {errNotFound, "NotFound",
+		"The node, pod, or file asked for is not known to the operator.",
+		"Check the xname or pod name; nodes are only known after hsm reports them."},
+	errConflict: {errConflict, "Conflict",
+		"The request conflicts with an operation already in progress.",
+		"Wait for the current operation to finish and retry."},
+	errReadOnlyReplica: {errReadOnlyReplica, "ReadOnlyReplica",
+		"A change was sent to a replica running in read-only mode.",
+		"Send changes to the leader replica."},
+	errStandbyReplica: {errStandbyReplica, "StandbyReplica",
+		"A change was sent to a replica that is not the leader.",
+		"Send changes to the leader named in the message, or retry after the election settles."},
+	errInternal: {errInternal, "Internal",
+		"The operator hit an unexpected error handling the request.",
+		"Check the operator logs for the cause and retry."},
+	errServiceUnavailable: {errServiceUnavailable, "ServiceUnavailable",
+		"The operator can not serve the request right now.",
+		"Check /console-operator/status for the degraded component and retry."},
+	errConsoleData: {errConsoleData, "ConsoleDataFailed",
+		"A call to cray-console-data failed.",
+		"Check that the cray-console-data pods and their postgres database are running."},
+	errHSM: {errHSM, "HSMFailed",
+		"A call to hsm failed.",
+		"Check that cray-smd is running; node inventory is kept as-is until it answers."},
+	errTAPMS: {errTAPMS, "TAPMSFailed",
+		"A call to tapms failed.",
+		"Check that tapms is running; tenant information may be out of date."},
+	errPCS: {errPCS, "PCSFailed",
+		"A call to pcs failed.",
+		"Check that cray-power-control is running; silent consoles are not reported until it answers."},
+	errVault: {errVault, "VaultFailed",
+		"A call to vault failed.",
+		"Check that vault is unsealed and the operator service account can log in."},
+	errK8s: {errK8s, "KubernetesFailed",
+		"A call to the kubernetes api failed.",
+		"Check the operator service account permissions and the api server health."},
+	errFAS: {errFAS, "FASFailed",
+		"A call to fas failed.",
+		"Check that cray-fas is running; consoles stay as they are until it answers."},
+	errSLS: {errSLS, "SLSFailed",
+		"A call to sls failed.",
+		"Check that cray-sls is running; the hardware topology is not updated until it answers."},
+	errFederation: {errFederation, "FederationPeerFailed",
+		"A request could not be forwarded to a federation peer.",
+		"Check the url configured for the peer in FEDERATION_PEERS and that its console-operator is running."},
+	errKeyGeneration: {errKeyGeneration, "KeyGenerationFailed",
+		"The console ssh key pair could not be generated or stored.",
+		"Check vault, then restart the operator to generate the key again."},
+	errKeyDeployment: {errKeyDeployment, "KeyDeploymentFailed",
+		"The console public key could not be deployed to one or more BMCs.",
+		"Check /console-operator/v1/keys/status for the BMCs and retry with /console-operator/v1/keys/retry."},
+	errKeyRotation: {errKeyRotation, "KeyRotationFailed",
+		"A console key rotation did not complete.",
+		"Check /console-operator/v1/keys/rotation and retry the rotation."},
+	errConsoleSessions: {errConsoleSessions, "ConsoleSessionsFailed",
+		"The console sessions in a console-node pod could not be checked or cleaned up.",
+		"Check that the console-node pods are running and the operator can exec into them."},
+	errMassNodeRemoval: {errMassNodeRemoval, "MassNodeRemoval",
+		"A large number of nodes were removed at once.",
+		"Check that hsm is reporting the full inventory."},
+	errLogQuota: {errLogQuota, "LogQuotaExceeded",
+		"The console logs went over their disk quota and old logs were removed.",
+		"Check /console-operator/v1/logs/quota for the nodes using the most space."},
+	errScaling: {errScaling, "ScaleFailed",
+		"The console-node workload could not be scaled.",
+		"Check the operator service account permissions and the console-node workload."},
+	errHandoff: {errHandoff, "HandoffFailed",
+		"The state handed off by the instance being replaced could not be used.",
+		"Nothing to do, the new instance starts from scratch; check both instances run the same version."},
+	errConfig: {errConfig, "ConfigInvalid",
+		"A setting in the operator environment could not be parsed and was ignored.",
+		"Fix the setting named in the message; /console-operator/config shows the values in use."},
+	errFileSystem: {errFileSystem, "FileWriteFailed",
+		"A file on the shared console log volume could not be written or removed.",
+		"Check that the console log volume is mounted and has space."},
+	errZombie: {errZombie, "ZombieReapFailed",
+		"Exited child processes of the operator could not be found or reaped.",
+		"Check /console-operator/zombies; restart the operator pod if zombies build up."},
+	errAlert: {errAlert, "AlertFailed",
+		"An alert could not be sent to the webhook.",
+		"Check that ALERT_WEBHOOK_URL is reachable from the operator pod."},
+}
+
+// Code used for an error response when a handler does not give one
+func defaultErrorCode(httpCode int) errorCode {
+	switch httpCode {
+	case http.StatusBadRequest:
+		return errBadRequest
+	case http.StatusMethodNotAllowed:
+		return errMethodNotAllowed
+	case http.StatusNotFound:
+		return errNotFound
+	case http.StatusConflict:
+		return errConflict
+	case http.StatusServiceUnavailable:
+		return errServiceUnavailable
+	}
+	if httpCode >= 500 {
+		return errInternal
+	}
+	if httpCode >= 400 {
+		return errBadRequest
+	}
+	return ""
+}
+
+// Code reported with the events the operator records
+// NOTE: only warnings have a code
+var eventReasonCodes = map[string]errorCode{
+	"KeyGenerationFailed": errKeyGeneration,
+	"KeyDeploymentFailed": errKeyDeployment,
+	"KeyRotationFailed":   errKeyRotation,
+	"MassNodeRemoval":     errMassNodeRemoval,
+	"LogQuotaExceeded":    errLogQuota,
+	"ScaleFailed":         errScaling,
+}
+
+// Log an error with its code in front so it can be searched for
+func logError(code errorCode, format string, v ...interface{}) {
+	log.Printf("%s: %s", code, fmt.Sprintf(format, v...))
+}
+
+// Get the catalog sorted by code
+func getErrorCatalog() []ErrorCodeInfo {
+	codes := make([]ErrorCodeInfo, 0, len(errorCatalog))
+	for _, info := range errorCatalog {
+		codes = append(codes, info)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i].Code < codes[j].Code })
+	return codes
+}
+
+// Report the error code catalog
+func doGetErrorCodes(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	SendResponseJSON(w, http.StatusOK, getErrorCatalog())
+}
@@ -0,0 +1,80 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorCatalog(t *testing.T) {
+	// every code has to be in the catalog under its own code
+	for code, info := range errorCatalog {
+		if info.Code != code {
+			t.Errorf("Expected: %s. Got: %s.", code, info.Code)
+		}
+		if info.Name == "" || info.Description == "" || info.Remediation == "" {
+			t.Errorf("Expected code %s to be documented", code)
+		}
+	}
+	for reason, code := range eventReasonCodes {
+		if _, found := errorCatalog[code]; !found {
+			t.Errorf("Expected event reason %s code %s to be in the catalog", reason, code)
+		}
+	}
+	codes := getErrorCatalog()
+	for i := 1; i < len(codes); i++ {
+		if codes[i-1].Code >= codes[i].Code {
+			t.Errorf("Expected the catalog sorted, got %s before %s", codes[i-1].Code, codes[i].Code)
+		}
+	}
+}
+
+func TestSendJSONErrorCode(t *testing.T) {
+	tests := []struct {
+		status int
+		code   errorCode
+	}{
+		{http.StatusBadRequest, errBadRequest},
+		{http.StatusMethodNotAllowed, errMethodNotAllowed},
+		{http.StatusNotFound, errNotFound},
+		{http.StatusServiceUnavailable, errServiceUnavailable},
+		{http.StatusInternalServerError, errInternal},
+		{http.StatusOK, ""},
+	}
+	for _, tc := range tests {
+		w := httptest.NewRecorder()
+		sendJSONError(w, tc.status, "message")
+		var resp ErrResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Unable to decode the response: %s", err)
+		}
+		if resp.Code != tc.code {
+			t.Errorf("Expected: %s. Got: %s.", tc.code, resp.Code)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to record k8s events for the decisions made
+//  by the operator so they show up with 'kubectl describe'
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The object an event is recorded against
+type eventTarget int
+
+const (
+	eventOnConsoleNode eventTarget = iota // the console-node workload
+	eventOnOperator                       // the pod running this operator
+)
+
+// Number of nodes removed at once that is reported as a mass removal
+var massNodeRemovalCount int = 10
+
+// Record a warning if a large number of nodes are removed at once
+// NOTE: a large removal usually means something is wrong with hsm
+func checkMassNodeRemoval(numRemoved int) {
+	if numRemoved >= massNodeRemovalCount {
+		recordEvent(eventOnOperator, corev1.EventTypeWarning, "MassNodeRemoval",
+			fmt.Sprintf("Removing %d nodes that are no longer reported by hsm", numRemoved))
+	}
+}
+
+// Service used to record events - nil when k8s is not available
+var eventService K8Service = nil
+
+// Record an event if k8s is available
+// NOTE: eventType is corev1.EventTypeNormal or corev1.EventTypeWarning
+func recordEvent(target eventTarget, eventType, reason, message string) {
+	if eventService == nil {
+		return
+	}
+	eventService.recordEvent(target, eventType, reason, message)
+}
+
+// Put the catalog code in front of the message so it can be looked up
+func eventMessage(reason, message string) string {
+	if code, found := eventReasonCodes[reason]; found {
+		return fmt.Sprintf("%s: %s", code, message)
+	}
+	return message
+}
+
+// Create a k8s event against the given target
+func (k8s K8Manager) recordEvent(target eventTarget, eventType, reason, message string) {
+	if k8s.clientset == nil {
+		return
+	}
+
+	// find the object the event is about
+	var obj corev1.ObjectReference
+	namespace := operatorNamespace
+	if target == eventOnConsoleNode {
+		wl, err := k8s.getConsoleNodeWorkload()
+		if err != nil {
+			return
+		}
+		obj = corev1.ObjectReference{Kind: consoleNodeKind, APIVersion: "apps/v1",
+			Namespace: consoleNodeNamespace, Name: consoleNodeName, UID: wl.UID}
+		namespace = consoleNodeNamespace
+	} else {
+		// NOTE: the pod name is the host name of the container
+		podName, err := os.Hostname()
+		if err != nil {
+			log.Printf("Unable to find the operator pod name for event: %s", err)
+			return
+		}
+		pod, err := k8s.clientset.CoreV1().Pods(operatorNamespace).Get(podName, metav1.GetOptions{})
+		if err != nil {
+			log.Printf("Unable to find the operator pod for event: %s", err)
+			return
+		}
+		obj = corev1.ObjectReference{Kind: "Pod", APIVersion: "v1",
+			Namespace: operatorNamespace, Name: podName, UID: pod.GetUID()}
+	}
+
+	now := metav1.NewTime(time.Now())
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: obj.Name + ".",
+			Namespace:    namespace,
+		},
+		InvolvedObject: obj,
+		Reason:         reason,
+		Message:        eventMessage(reason, message),
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: "cray-console-operator"},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	if _, err := k8s.clientset.CoreV1().Events(namespace).Create(event); err != nil {
+		logError(errK8s, "Error recording event %s on %s: %s", reason, obj.Name, err)
+	}
+}
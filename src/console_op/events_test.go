@@ -0,0 +1,110 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Struct to hold an event recorded through the mock
+type recordedEvent struct {
+	target    eventTarget
+	eventType string
+	reason    string
+	message   string
+}
+
+// Mock to capture the events recorded
+type K8EventMock struct {
+	// embed this so only mock methods as needed
+	K8Manager
+	events *[]recordedEvent
+}
+
+func (m K8EventMock) recordEvent(target eventTarget, eventType, reason, message string) {
+	*m.events = append(*m.events, recordedEvent{target, eventType, reason, message})
+}
+
+func TestRecordEvent(t *testing.T) {
+	oldService := eventService
+	defer func() { eventService = oldService }()
+
+	// without k8s nothing is recorded and nothing breaks
+	eventService = nil
+	recordEvent(eventOnOperator, corev1.EventTypeNormal, "KeyRotated", "rotated")
+
+	var events []recordedEvent
+	eventService = K8EventMock{events: &events}
+	recordEvent(eventOnConsoleNode, corev1.EventTypeNormal, "Scaled", "Scaled to 3 pods")
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event. Got: %d.", len(events))
+	}
+	if e := events[0]; e.target != eventOnConsoleNode || e.eventType != corev1.EventTypeNormal ||
+		e.reason != "Scaled" || e.message != "Scaled to 3 pods" {
+		t.Errorf("Unexpected event: %+v", e)
+	}
+}
+
+func TestCheckMassNodeRemoval(t *testing.T) {
+	oldService, oldCount := eventService, massNodeRemovalCount
+	defer func() { eventService, massNodeRemovalCount = oldService, oldCount }()
+	var events []recordedEvent
+	eventService = K8EventMock{events: &events}
+	massNodeRemovalCount = 10
+
+	tests := []struct {
+		numRemoved int
+		expected   bool
+	}{
+		{0, false},
+		{9, false},
+		{10, true},
+		{11, true},
+	}
+	for _, tc := range tests {
+		events = nil
+		checkMassNodeRemoval(tc.numRemoved)
+		if got := len(events) == 1; got != tc.expected {
+			t.Errorf("%d removed: Expected an event: %t. Got: %d events.", tc.numRemoved, tc.expected, len(events))
+			continue
+		}
+		if tc.expected {
+			if e := events[0]; e.target != eventOnOperator || e.eventType != corev1.EventTypeWarning || e.reason != "MassNodeRemoval" {
+				t.Errorf("Unexpected event: %+v", e)
+			}
+		}
+	}
+}
+
+func TestEventMessage(t *testing.T) {
+	if msg := eventMessage("MassNodeRemoval", "Removing 10 nodes"); msg != string(errMassNodeRemoval)+": Removing 10 nodes" {
+		t.Errorf("Expected the code in front of a warning. Got: %s.", msg)
+	}
+	if msg := eventMessage("Scaled", "Scaled to 3 pods"); msg != "Scaled to 3 pods" {
+		t.Errorf("Expected a normal event unchanged. Got: %s.", msg)
+	}
+}
@@ -0,0 +1,176 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to proxy requests to the console-operator of
+//  another system so several systems can be reached through one endpoint
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Prefix of the routes proxied to a peer system
+const federationPrefix string = "/console-operator/v1/federation"
+
+// Routes a peer may be asked for - the path after the system name
+// NOTE: only console and log reads are forwarded, the peer's admin, debug and
+// write routes are never reachable through the proxy
+var federationNodeRoute = regexp.MustCompile(`^v1/logs/[A-Za-z0-9]+/(manifest|archive|export|tail|snapshots)(/[^/]+)?$`)
+var federationPodRoute = regexp.MustCompile(`^v1/location/[^/]+$`)
+
+// Struct to hold a peer console-operator on another system
+type federationPeer struct {
+	Name string
+	URL  *url.URL
+}
+
+// Configured peers by system name
+var federationPeers map[string]federationPeer = make(map[string]federationPeer)
+
+// Proxies are created once per peer and reused
+var federationProxies map[string]*httputil.ReverseProxy = make(map[string]*httputil.ReverseProxy)
+var federationProxiesMutex sync.Mutex
+
+// Parse a list of peers in the form 'system=url,system=url'
+func parseFederationPeers(val string) (map[string]federationPeer, error) {
+	peers := make(map[string]federationPeer)
+	for _, p := range strings.Split(val, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		parts := strings.SplitN(p, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("Invalid federation peer, expected system=url: %s", p)
+		}
+		if _, found := peers[parts[0]]; found {
+			return nil, fmt.Errorf("Duplicate federation peer: %s", parts[0])
+		}
+		u, err := url.Parse(strings.TrimSuffix(parts[1], "/"))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("Invalid url for federation peer %s: %s", parts[0], parts[1])
+		}
+		peers[parts[0]] = federationPeer{Name: parts[0], URL: u}
+	}
+	return peers, nil
+}
+
+// Read the federation peer configuration
+func readFederationPeers() {
+	registerSetting("FEDERATION_PEERS", func() string {
+		var peers []string = nil
+		for name, p := range federationPeers {
+			peers = append(peers, name+"="+p.URL.String())
+		}
+		sort.Strings(peers)
+		return strings.Join(peers, ",")
+	}, ConfigSetting{})
+	val := os.Getenv("FEDERATION_PEERS")
+	if val == "" {
+		return
+	}
+	peers, err := parseFederationPeers(val)
+	if err != nil {
+		logError(errConfig, "Error reading FEDERATION_PEERS, federation disabled: %s", err)
+		return
+	}
+	federationPeers = peers
+	for name, p := range peers {
+		log.Printf("Federation peer %s: %s", name, p.URL)
+	}
+}
+
+// Get the proxy for a peer, creating it the first time it is used
+func getFederationProxy(peer federationPeer) *httputil.ReverseProxy {
+	federationProxiesMutex.Lock()
+	defer federationProxiesMutex.Unlock()
+	if p, found := federationProxies[peer.Name]; found {
+		return p
+	}
+	p := httputil.NewSingleHostReverseProxy(peer.URL)
+	p.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		logError(errFederation, "Error proxying to federation peer %s: %s", peer.Name, err)
+		sendJSONError(w, http.StatusBadGateway,
+			fmt.Sprintf("Unable to reach system %s", peer.Name))
+	}
+	federationProxies[peer.Name] = p
+	return p
+}
+
+// Check a path after the system name may be forwarded
+func federationRoute(rest string) bool {
+	return federationNodeRoute.MatchString(rest) || federationPodRoute.MatchString(rest)
+}
+
+// Forward a request to the console-operator of another system
+// NOTE: the path after the system name is sent to the peer as a
+// console-operator path, ie .../federation/sys2/v1/location/x goes to
+// /console-operator/v1/location/x on sys2.  The Authorization and tenant
+// headers are passed through so the peer does its own checks, only the peer
+// knows which of its nodes a tenant owns.
+func (TenantManager) doFederationProxy(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	system := chi.URLParam(r, "system")
+	peer, found := federationPeers[system]
+	if !found {
+		sendJSONError(w, http.StatusNotFound,
+			fmt.Sprintf("Unknown system: %s", system))
+		return
+	}
+
+	rest := strings.TrimPrefix(chi.URLParam(r, "*"), "/")
+	if !federationRoute(rest) {
+		sendJSONError(w, http.StatusNotFound,
+			fmt.Sprintf("Route not available through federation: %s", rest))
+		return
+	}
+
+	// rewrite the path for the peer - the proxy adds the path of the peer url
+	pr := r.Clone(r.Context())
+	pr.URL.Path = "/console-operator/" + rest
+	pr.URL.RawPath = ""
+	pr.Host = peer.URL.Host
+
+	log.Printf("Proxying %s %s to system %s", r.Method, r.URL.Path, system)
+	getFederationProxy(peer).ServeHTTP(w, pr)
+}
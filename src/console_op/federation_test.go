@@ -0,0 +1,138 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestParseFederationPeers(t *testing.T) {
+	peers, err := parseFederationPeers("sys1=http://sys1.example.com/, sys2=https://sys2.example.com/api")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(peers) != 2 {
+		t.Fatalf("Expected: 2. Got: %d.", len(peers))
+	}
+	if peers["sys2"].URL.Path != "/api" {
+		t.Errorf("Expected: /api. Got: %s.", peers["sys2"].URL.Path)
+	}
+
+	for _, bad := range []string{"sys1", "sys1=ftp://sys1", "sys1=http://a,sys1=http://b", "=http://a"} {
+		if _, err := parseFederationPeers(bad); err == nil {
+			t.Errorf("Expected an error for: %s", bad)
+		}
+	}
+}
+
+func TestFederationRoute(t *testing.T) {
+	tests := []struct {
+		rest string
+		ok   bool
+	}{
+		{"v1/location/cray-console-node-0", true},
+		{"v1/logs/x1000c0s0b0n0/tail", true},
+		{"v1/logs/x1000c0s0b0n0/snapshots/20240101T000000Z", true},
+		{"v1/replicas", false},
+		{"v1/state", false},
+		{"v1/logs/x1000c0s0b0n0/../../info", false},
+		{"info", false},
+		{"pprof/heap", false},
+	}
+	for _, tc := range tests {
+		if ok := federationRoute(tc.rest); ok != tc.ok {
+			t.Errorf("%s: Expected: %t. Got: %t.", tc.rest, tc.ok, ok)
+		}
+	}
+}
+
+func TestFederationProxy(t *testing.T) {
+	var gotPath, gotAuth, gotTenant string
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		gotTenant = r.Header.Get(tenantHeader)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer peer.Close()
+
+	oldPeers := federationPeers
+	defer func() { federationPeers = oldPeers }()
+	federationPeers, _ = parseFederationPeers("sys2=" + peer.URL)
+
+	tm := NewTenantManager(TapmsGetTenantsMock{})
+	r := chi.NewRouter()
+	r.HandleFunc(federationPrefix+"/{system}/*", tm.doFederationProxy)
+
+	req := httptest.NewRequest(http.MethodGet, federationPrefix+"/sys2/v1/location/cray-console-node-0", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected: %d. Got: %d.", http.StatusOK, rr.Code)
+	}
+	if gotPath != "/console-operator/v1/location/cray-console-node-0" {
+		t.Errorf("Expected: /console-operator/v1/location/cray-console-node-0. Got: %s.", gotPath)
+	}
+	if gotAuth != "Bearer token" {
+		t.Errorf("Expected the Authorization header to be passed through. Got: %s.", gotAuth)
+	}
+
+	tests := []struct {
+		method   string
+		path     string
+		tenant   string
+		expected int
+	}{
+		{http.MethodGet, "/sys3/v1/location/cray-console-node-0", "", http.StatusNotFound},
+		{http.MethodGet, "/sys2/v1/replicas", "", http.StatusNotFound},
+		{http.MethodGet, "/sys2/info", "", http.StatusNotFound},
+		{http.MethodPost, "/sys2/v1/location/cray-console-node-0", "", http.StatusMethodNotAllowed},
+	}
+	for _, tc := range tests {
+		req = httptest.NewRequest(tc.method, federationPrefix+tc.path, nil)
+		if tc.tenant != "" {
+			req.Header.Set(tenantHeader, tc.tenant)
+		}
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != tc.expected {
+			t.Errorf("%s %s (%s): Expected: %d. Got: %d.", tc.method, tc.path, tc.tenant, tc.expected, rr.Code)
+		}
+	}
+
+	// the tenant is checked by the peer, which knows who owns its nodes
+	req = httptest.NewRequest(http.MethodGet, federationPrefix+"/sys2/v1/logs/x9000c0s0b0n0/tail", nil)
+	req.Header.Set(tenantHeader, "vcluster-blue")
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || gotTenant != "vcluster-blue" {
+		t.Errorf("Expected the tenant header to be passed through. Got: %d %s.", rr.Code, gotTenant)
+	}
+}
@@ -0,0 +1,229 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to keep consoles steady while fas updates
+//  the firmware of their bmcs and reconnect them once the update is done
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tidwall/gjson"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Base url of the firmware action service
+var fasAddrBase string = "http://cray-fas/v1"
+
+// How often to check fas for firmware updates - 0 disables the check
+var fasCheckPeriodSec int = 0
+
+// States of a fas action that is not finished yet
+var fasActiveActionStates = map[string]bool{"new": true, "configured": true, "running": true, "blocked": true}
+
+// States of a fas operation that has not finished yet
+var fasActiveOperationStates = []string{"initial", "configured", "blocked", "inProgress", "needsVerified", "verifying"}
+
+// FirmwareMaintenance - a console in maintenance while its bmc is updated
+type FirmwareMaintenance struct {
+	Xname    string `json:"xname"`
+	BmcName  string `json:"bmcname"`
+	ActionID string `json:"actionid"`
+	Since    string `json:"since"`
+}
+
+// Consoles in maintenance by node xname
+var firmwareMaintenance map[string]FirmwareMaintenance = make(map[string]FirmwareMaintenance)
+var firmwareMaintenanceMutex sync.Mutex
+
+// Get the ids of the fas actions that are not finished
+func parseFasActiveActions(data []byte) []string {
+	var ids []string = nil
+	for _, a := range gjson.GetBytes(data, "actions").Array() {
+		if fasActiveActionStates[a.Get("state").String()] {
+			ids = append(ids, a.Get("actionID").String())
+		}
+	}
+	return ids
+}
+
+// Get the xnames a fas action is still working on
+func parseFasActionXnames(data []byte) []string {
+	var xnames []string = nil
+	for _, state := range fasActiveOperationStates {
+		for _, x := range gjson.GetBytes(data, "operationSummary."+state+".operationsKeys.#.xname").Array() {
+			xnames = append(xnames, x.String())
+		}
+	}
+	return xnames
+}
+
+// Get the bmcs being updated by fas, with the action updating each
+func getFasActiveBmcs() (map[string]string, error) {
+	data, sc, err := getURL(fasAddrBase+"/actions", nil)
+	if err != nil {
+		return nil, err
+	}
+	if sc != http.StatusOK {
+		return nil, fmt.Errorf("GET %s/actions returned status: %d", fasAddrBase, sc)
+	}
+	bmcs := make(map[string]string)
+	for _, id := range parseFasActiveActions(data) {
+		data, sc, err := getURL(fasAddrBase+"/actions/"+id, nil)
+		if err != nil {
+			return nil, err
+		}
+		if sc != http.StatusOK {
+			return nil, fmt.Errorf("GET %s/actions/%s returned status: %d", fasAddrBase, id, sc)
+		}
+		for _, xname := range parseFasActionXnames(data) {
+			bmcs[xname] = id
+		}
+	}
+	return bmcs, nil
+}
+
+// Update the consoles in maintenance from the bmcs being updated, returning
+// the consoles that went into maintenance and the ones whose update finished
+func updateFirmwareMaintenance(bmcs map[string]string, nodes map[string]nodeConsoleInfo, now time.Time) (started, finished []nodeConsoleInfo) {
+	firmwareMaintenanceMutex.Lock()
+	defer firmwareMaintenanceMutex.Unlock()
+	for xname, n := range nodes {
+		id, found := bmcs[n.BmcName]
+		if !found {
+			continue
+		}
+		if _, found := firmwareMaintenance[xname]; !found {
+			firmwareMaintenance[xname] = FirmwareMaintenance{Xname: xname, BmcName: n.BmcName, ActionID: id, Since: formatTime(now)}
+			started = append(started, n)
+		}
+	}
+	for xname, fm := range firmwareMaintenance {
+		if _, found := bmcs[fm.BmcName]; found {
+			continue
+		}
+		delete(firmwareMaintenance, xname)
+		if n, found := nodes[xname]; found {
+			finished = append(finished, n)
+		}
+	}
+	return started, finished
+}
+
+// Check if a console is in maintenance for a firmware update
+func inFirmwareMaintenance(xname string) bool {
+	firmwareMaintenanceMutex.Lock()
+	defer firmwareMaintenanceMutex.Unlock()
+	_, found := firmwareMaintenance[xname]
+	return found
+}
+
+// Check if any console is in maintenance for a firmware update
+func firmwareUpdateActive() bool {
+	firmwareMaintenanceMutex.Lock()
+	defer firmwareMaintenanceMutex.Unlock()
+	return len(firmwareMaintenance) > 0
+}
+
+// Get the consoles in maintenance sorted by xname
+func getFirmwareMaintenance() []FirmwareMaintenance {
+	firmwareMaintenanceMutex.Lock()
+	defer firmwareMaintenanceMutex.Unlock()
+	consoles := make([]FirmwareMaintenance, 0, len(firmwareMaintenance))
+	for _, fm := range firmwareMaintenance {
+		consoles = append(consoles, fm)
+	}
+	sort.Slice(consoles, func(i, j int) bool { return consoles[i].Xname < consoles[j].Xname })
+	return consoles
+}
+
+// Main loop to follow the fas firmware updates
+func watchFirmwareUpdates(ctx context.Context, ds DataService) {
+	if fasCheckPeriodSec <= 0 {
+		log.Printf("Firmware update coordination disabled")
+		return
+	}
+	for {
+		if !debugOnly && !reconcileStopped() {
+			checkFirmwareUpdates(ds, time.Now())
+		}
+		if !sleepCtx(ctx, time.Duration(fasCheckPeriodSec)*time.Second) {
+			log.Printf("Stopping firmware update checks")
+			return
+		}
+	}
+}
+
+// Put the consoles of bmcs being updated into maintenance and reconnect
+// them once the update is done
+// NOTE: the reconnect releases the nodes in console-data and adds them back
+// so the console-node pods reacquire them with a fresh connection
+func checkFirmwareUpdates(ds DataService, now time.Time) {
+	bmcs, err := getFasActiveBmcs()
+	if err != nil {
+		// NOTE: keep the consoles as they are until fas answers
+		logError(errFAS, "Unable to get the firmware updates from fas: %s", err)
+		return
+	}
+	// NOTE - not thread safe, but should be ok
+	nodes := make(map[string]nodeConsoleInfo, len(nodeCache))
+	for xname, n := range nodeCache {
+		nodes[xname] = n
+	}
+	started, finished := updateFirmwareMaintenance(bmcs, nodes, now)
+	if len(started) > 0 {
+		log.Printf("Firmware update started for the bmcs of %d consoles, putting them in maintenance", len(started))
+		recordEvent(eventOnOperator, corev1.EventTypeNormal, "FirmwareUpdateStarted",
+			fmt.Sprintf("Holding %d consoles in maintenance while fas updates their bmcs", len(started)))
+	}
+	if len(finished) > 0 {
+		log.Printf("Firmware update finished for the bmcs of %d consoles, reconnecting them", len(finished))
+		ds.dataRemoveNodes(finished)
+		if !ds.dataAddNodes(finished) {
+			log.Printf("Unable to add back the updated consoles, they will be added on the next full update")
+		}
+		recordEvent(eventOnOperator, corev1.EventTypeNormal, "FirmwareUpdateFinished",
+			fmt.Sprintf("Reconnected %d consoles after fas finished updating their bmcs", len(finished)))
+	}
+}
+
+// Report the consoles in maintenance for firmware updates
+func doGetFirmwareMaintenance(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	SendResponseJSON(w, http.StatusOK, getFirmwareMaintenance())
+}
@@ -0,0 +1,82 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFasActions(t *testing.T) {
+	actions := []byte(`{"actions":[
+		{"actionID":"a1","state":"running"},
+		{"actionID":"a2","state":"completed"},
+		{"actionID":"a3","state":"configured"}]}`)
+	ids := parseFasActiveActions(actions)
+	if len(ids) != 2 || ids[0] != "a1" || ids[1] != "a3" {
+		t.Errorf("Unexpected active actions: %v", ids)
+	}
+
+	action := []byte(`{"actionID":"a1","state":"running","operationSummary":{
+		"inProgress":{"operationsKeys":[{"xname":"x3000c0s19b0","target":"BMC"}]},
+		"initial":{"operationsKeys":[{"xname":"x1000c0s0b0","target":"BMC"}]},
+		"succeeded":{"operationsKeys":[{"xname":"x3000c0s17b0","target":"BMC"}]}}}`)
+	xnames := parseFasActionXnames(action)
+	if len(xnames) != 2 || xnames[0] != "x1000c0s0b0" || xnames[1] != "x3000c0s19b0" {
+		t.Errorf("Unexpected xnames: %v", xnames)
+	}
+}
+
+func TestUpdateFirmwareMaintenance(t *testing.T) {
+	defer func() { firmwareMaintenance = make(map[string]FirmwareMaintenance) }()
+	nodes := map[string]nodeConsoleInfo{
+		"x3000c0s19b0n0": {NodeName: "x3000c0s19b0n0", BmcName: "x3000c0s19b0"},
+		"x3000c0s17b0n0": {NodeName: "x3000c0s17b0n0", BmcName: "x3000c0s17b0"},
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	started, finished := updateFirmwareMaintenance(map[string]string{"x3000c0s19b0": "a1"}, nodes, now)
+	if len(started) != 1 || started[0].NodeName != "x3000c0s19b0n0" || len(finished) != 0 {
+		t.Errorf("Unexpected started: %v finished: %v", started, finished)
+	}
+	if !inFirmwareMaintenance("x3000c0s19b0n0") || inFirmwareMaintenance("x3000c0s17b0n0") || !firmwareUpdateActive() {
+		t.Errorf("Expected only x3000c0s19b0n0 in maintenance, got: %v", getFirmwareMaintenance())
+	}
+
+	// still being updated - nothing changes
+	started, finished = updateFirmwareMaintenance(map[string]string{"x3000c0s19b0": "a1"}, nodes, now)
+	if len(started) != 0 || len(finished) != 0 {
+		t.Errorf("Unexpected started: %v finished: %v", started, finished)
+	}
+
+	// update done - the console is reconnected
+	started, finished = updateFirmwareMaintenance(map[string]string{}, nodes, now)
+	if len(started) != 0 || len(finished) != 1 || finished[0].NodeName != "x3000c0s19b0n0" {
+		t.Errorf("Unexpected started: %v finished: %v", started, finished)
+	}
+	if firmwareUpdateActive() {
+		t.Errorf("Expected no consoles in maintenance, got: %v", getFirmwareMaintenance())
+	}
+}
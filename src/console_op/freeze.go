@@ -0,0 +1,288 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to freeze scaling changes during planned
+//  maintenance so the console-node pods do not churn
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A period of time when scaling changes are not allowed
+// NOTE: a daily window has only the time of day (UTC) set in Start and End
+type maintenanceWindow struct {
+	Start time.Time
+	End   time.Time
+	Daily bool
+}
+
+// Provide a function to convert struct to string
+func (mw maintenanceWindow) String() string {
+	if mw.Daily {
+		return fmt.Sprintf("daily %s-%s UTC", mw.Start.Format("15:04"), mw.End.Format("15:04"))
+	}
+	return fmt.Sprintf("%s/%s", formatTime(mw.Start), formatTime(mw.End))
+}
+
+// Check if the given time falls inside the window
+func (mw maintenanceWindow) contains(t time.Time) bool {
+	if !mw.Daily {
+		return !t.Before(mw.Start) && t.Before(mw.End)
+	}
+	// compare just the time of day, the window may wrap past midnight
+	t = t.UTC()
+	tod := t.Hour()*60 + t.Minute()
+	start := mw.Start.Hour()*60 + mw.Start.Minute()
+	end := mw.End.Hour()*60 + mw.End.Minute()
+	if start <= end {
+		return tod >= start && tod < end
+	}
+	return tod >= start || tod < end
+}
+
+// Maintenance windows configured through MAINTENANCE_WINDOWS
+var maintenanceWindows []maintenanceWindow = nil
+
+// Freeze set through the api - zero time for 'until' means no end time
+var freezeActive bool = false
+var freezeReason string = ""
+var freezeUntil time.Time
+var freezeMutex sync.Mutex
+
+// Parse a comma separated list of maintenance windows.  Each window is either
+// an RFC3339 start and end separated by '/' or a daily 'HH:MM-HH:MM' in UTC.
+func parseMaintenanceWindows(val string) ([]maintenanceWindow, error) {
+	var windows []maintenanceWindow = nil
+	for _, w := range strings.Split(val, ",") {
+		w = strings.TrimSpace(w)
+		if w == "" {
+			continue
+		}
+		if parts := strings.Split(w, "/"); len(parts) == 2 {
+			start, err := time.Parse(time.RFC3339, parts[0])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid maintenance window start %s: %s", w, err)
+			}
+			end, err := time.Parse(time.RFC3339, parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid maintenance window end %s: %s", w, err)
+			}
+			if !end.After(start) {
+				return nil, fmt.Errorf("Maintenance window %s ends before it starts", w)
+			}
+			windows = append(windows, maintenanceWindow{Start: start, End: end})
+			continue
+		}
+		if parts := strings.Split(w, "-"); len(parts) == 2 {
+			start, err := time.Parse("15:04", parts[0])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid maintenance window start %s: %s", w, err)
+			}
+			end, err := time.Parse("15:04", parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid maintenance window end %s: %s", w, err)
+			}
+			windows = append(windows, maintenanceWindow{Start: start, End: end, Daily: true})
+			continue
+		}
+		return nil, fmt.Errorf("Invalid maintenance window: %s", w)
+	}
+	return windows, nil
+}
+
+// Read the maintenance window configuration
+func readMaintenanceWindows() {
+	registerSetting("MAINTENANCE_WINDOWS", func() string { return fmt.Sprint(maintenanceWindows) }, ConfigSetting{})
+	val := os.Getenv("MAINTENANCE_WINDOWS")
+	if val == "" {
+		return
+	}
+	windows, err := parseMaintenanceWindows(val)
+	if err != nil {
+		logError(errConfig, "Error: ignoring MAINTENANCE_WINDOWS: %s", err)
+		return
+	}
+	for _, mw := range windows {
+		log.Printf("Maintenance window: %s", mw)
+	}
+	maintenanceWindows = windows
+}
+
+// Check if scaling changes are currently frozen and why
+func scalingFrozen(now time.Time) (bool, string) {
+	freezeMutex.Lock()
+	defer freezeMutex.Unlock()
+	if freezeActive {
+		if freezeUntil.IsZero() || now.Before(freezeUntil) {
+			return true, freezeReason
+		}
+		// the freeze has expired
+		log.Printf("Scaling freeze expired")
+		freezeActive = false
+		freezeReason = ""
+		freezeUntil = time.Time{}
+	}
+	for _, mw := range maintenanceWindows {
+		if mw.contains(now) {
+			return true, fmt.Sprintf("maintenance window %s", mw)
+		}
+	}
+	return false, ""
+}
+
+type FreezeService interface {
+	doGetFreeze(w http.ResponseWriter, r *http.Request)
+	doSetFreeze(w http.ResponseWriter, r *http.Request)
+	doClearFreeze(w http.ResponseWriter, r *http.Request)
+	doGetNodeMaintenance(w http.ResponseWriter, r *http.Request)
+	doAddNodeMaintenance(w http.ResponseWriter, r *http.Request)
+	doDeleteNodeMaintenance(w http.ResponseWriter, r *http.Request)
+}
+
+// Implements FreezeService
+type FreezeManager struct{}
+
+// Constructor for the freeze handling
+func NewFreezeManager() FreezeService {
+	return &FreezeManager{}
+}
+
+// FreezeRequest - input data to freeze scaling changes
+type FreezeRequest struct {
+	Reason string `json:"reason"`
+	Until  string `json:"until"` // RFC3339, empty for no end time
+}
+
+// FreezeResponse - current freeze state
+type FreezeResponse struct {
+	Frozen             bool     `json:"frozen"`
+	Reason             string   `json:"reason"`
+	Until              string   `json:"until"`
+	MaintenanceWindows []string `json:"maintenancewindows"`
+}
+
+// Report if scaling changes are currently frozen
+func (FreezeManager) doGetFreeze(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	var resp FreezeResponse
+	resp.Frozen, resp.Reason = scalingFrozen(time.Now())
+	freezeMutex.Lock()
+	if freezeActive && !freezeUntil.IsZero() {
+		resp.Until = formatTime(freezeUntil)
+	}
+	freezeMutex.Unlock()
+	resp.MaintenanceWindows = []string{}
+	for _, mw := range maintenanceWindows {
+		resp.MaintenanceWindows = append(resp.MaintenanceWindows, mw.String())
+	}
+	SendResponseJSON(w, http.StatusOK, resp)
+}
+
+// Freeze scaling changes until cleared or the given time
+func (FreezeManager) doSetFreeze(w http.ResponseWriter, r *http.Request) {
+	// only allow 'POST' calls
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	// the body is optional
+	var req FreezeRequest
+	reqBody, err := ioutil.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		sendJSONError(w, http.StatusBadRequest,
+			fmt.Sprintf("There was an error reading the request body: %s", err))
+		return
+	}
+	if len(reqBody) > 0 {
+		if err = json.Unmarshal(reqBody, &req); err != nil {
+			sendJSONError(w, http.StatusBadRequest,
+				fmt.Sprintf("There was an error decoding the request body: %s", err))
+			return
+		}
+	}
+	var until time.Time
+	if req.Until != "" {
+		if until, err = time.Parse(time.RFC3339, req.Until); err != nil {
+			sendJSONError(w, http.StatusBadRequest,
+				fmt.Sprintf("Invalid time for until: %s", err))
+			return
+		}
+	}
+	if req.Reason == "" {
+		req.Reason = "frozen through api"
+	}
+
+	freezeMutex.Lock()
+	freezeActive = true
+	freezeReason = req.Reason
+	freezeUntil = until
+	freezeMutex.Unlock()
+	log.Printf("Scaling changes frozen: %s, until: %s", req.Reason, req.Until)
+
+	// write the response
+	w.WriteHeader(http.StatusOK)
+}
+
+// Remove a freeze set through the api
+// NOTE: this does not affect the configured maintenance windows
+func (FreezeManager) doClearFreeze(w http.ResponseWriter, r *http.Request) {
+	// only allow 'DELETE' calls
+	if r.Method != http.MethodDelete {
+		w.Header().Set("Allow", "DELETE")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	freezeMutex.Lock()
+	freezeActive = false
+	freezeReason = ""
+	freezeUntil = time.Time{}
+	freezeMutex.Unlock()
+	log.Printf("Scaling freeze cleared")
+
+	// write the response
+	w.WriteHeader(http.StatusOK)
+}
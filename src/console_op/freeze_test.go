@@ -0,0 +1,88 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMaintenanceWindows(t *testing.T) {
+	windows, err := parseMaintenanceWindows("2026-10-20T00:00:00Z/2026-10-21T00:00:00Z, 22:00-02:00")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(windows) != 2 {
+		t.Fatalf("Expected: 2. Got: %d.", len(windows))
+	}
+
+	tests := []struct {
+		time     string
+		expected bool
+	}{
+		{"2026-10-19T23:59:59Z", true}, // daily window
+		{"2026-10-20T12:00:00Z", true}, // dated window
+		{"2026-10-21T00:00:00Z", true}, // dated window over, daily wraps midnight
+		{"2026-10-21T02:00:00Z", false},
+		{"2026-10-21T21:59:00Z", false},
+	}
+	for _, tc := range tests {
+		now, _ := time.Parse(time.RFC3339, tc.time)
+		got := false
+		for _, mw := range windows {
+			if mw.contains(now) {
+				got = true
+			}
+		}
+		if got != tc.expected {
+			t.Errorf("%s: Expected: %t. Got: %t.", tc.time, tc.expected, got)
+		}
+	}
+
+	// bad windows are rejected
+	for _, bad := range []string{"tomorrow", "25:00-01:00", "2026-10-21T00:00:00Z/2026-10-20T00:00:00Z"} {
+		if _, err := parseMaintenanceWindows(bad); err == nil {
+			t.Errorf("Expected an error for: %s", bad)
+		}
+	}
+}
+
+func TestScalingFrozen(t *testing.T) {
+	now := time.Now()
+	freezeActive = true
+	freezeReason = "upgrade"
+	freezeUntil = now.Add(time.Hour)
+	if frozen, reason := scalingFrozen(now); !frozen || reason != "upgrade" {
+		t.Errorf("Expected: frozen for upgrade. Got: %t, %s.", frozen, reason)
+	}
+
+	// the freeze ends on its own
+	if frozen, _ := scalingFrozen(now.Add(2 * time.Hour)); frozen {
+		t.Errorf("Expected: not frozen after the freeze expired.")
+	}
+	if freezeActive {
+		t.Errorf("Expected: expired freeze to be cleared.")
+	}
+}
@@ -0,0 +1,210 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to hand off the state of a running operator to
+// the instance replacing it so an upgrade does not rediscover everything
+
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Url of the instance being replaced - empty to skip the handoff
+var handoffURL string = ""
+
+// Shared secret both instances must have for the handoff - empty disables it
+var handoffToken string = ""
+
+// How long a handoff holds before this instance takes the work back if it
+// is still running
+var handoffTimeoutSec int = 600
+
+// Unix time until which this instance has handed off to its replacement
+var handedOffUntil int64 = 0
+
+// Mountain nodes still waiting for their console keys, kept so they can be
+// passed to the next instance
+var pendingKeyNodes []nodeConsoleInfo = nil
+var pendingKeyNodesMutex sync.Mutex
+
+// Mountain nodes received in the handoff that still need their keys
+var handoffKeyNodes []nodeConsoleInfo = nil
+
+// HandoffResponse - state and in-flight work passed to the new instance
+type HandoffResponse struct {
+	State           ConsoleState      `json:"state"`
+	PendingKeyNodes []nodeConsoleInfo `json:"pendingkeynodes"`
+}
+
+// Is reconciling stopped because this instance is shutting down or has
+// handed off to another one
+func reconcileStopped() bool {
+	return inShutdown || handoffActive()
+}
+
+// Has this instance handed off its work and not yet taken it back
+func handoffActive() bool {
+	return time.Now().Unix() < atomic.LoadInt64(&handedOffUntil)
+}
+
+// Check the caller has the shared handoff token
+func handoffAuthorized(r *http.Request) bool {
+	if handoffToken == "" {
+		return false
+	}
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+	tok := strings.TrimPrefix(auth, "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(tok), []byte(handoffToken)) == 1
+}
+
+// Record the mountain nodes waiting for their console keys
+func setPendingKeyNodes(nodes map[string]nodeConsoleInfo) {
+	pendingKeyNodesMutex.Lock()
+	defer pendingKeyNodesMutex.Unlock()
+	pendingKeyNodes = make([]nodeConsoleInfo, 0, len(nodes))
+	for _, n := range nodes {
+		pendingKeyNodes = append(pendingKeyNodes, n)
+	}
+}
+
+// Get a copy of the mountain nodes waiting for their console keys
+func getPendingKeyNodes() []nodeConsoleInfo {
+	pendingKeyNodesMutex.Lock()
+	defer pendingKeyNodesMutex.Unlock()
+	nodes := make([]nodeConsoleInfo, len(pendingKeyNodes))
+	copy(nodes, pendingKeyNodes)
+	return nodes
+}
+
+// Hand off to the instance replacing this one
+// NOTE: once handed off this instance stops reconciling but keeps serving
+// reads. If it is still running after HANDOFF_TIMEOUT_SEC, or the handoff is
+// released, it starts reconciling again.
+func (StateManager) doHandoff(w http.ResponseWriter, r *http.Request) {
+	// only allow 'POST' calls
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+	if !handoffAuthorized(r) {
+		sendJSONError(w, http.StatusUnauthorized, "Handoff not authorized")
+		return
+	}
+
+	// stop making changes before the state is captured so it can not change
+	// under the new instance
+	until := time.Now().Add(time.Duration(handoffTimeoutSec) * time.Second)
+	atomic.StoreInt64(&handedOffUntil, until.Unix())
+	log.Printf("Handing off to a new instance, reconciling stopped until %s", formatTime(until))
+
+	SendResponseJSON(w, http.StatusOK, HandoffResponse{
+		State:           exportConsoleState(),
+		PendingKeyNodes: getPendingKeyNodes(),
+	})
+}
+
+// Take the work back from a replacement that did not come up
+func (StateManager) doReleaseHandoff(w http.ResponseWriter, r *http.Request) {
+	// only allow 'DELETE' calls
+	if r.Method != http.MethodDelete {
+		w.Header().Set("Allow", "DELETE")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+	if !handoffAuthorized(r) {
+		sendJSONError(w, http.StatusUnauthorized, "Handoff not authorized")
+		return
+	}
+
+	atomic.StoreInt64(&handedOffUntil, 0)
+	log.Printf("Handoff released, reconciling resumed")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Take over from the instance being replaced
+// NOTE: the nodes are already in console-data and the pods already have their
+// targets so only the local state is seeded - the first hardware check then
+// only finds what changed since the handoff
+func requestHandoff(url string) bool {
+	log.Printf("Requesting handoff from: %s", url)
+	if handoffToken == "" {
+		log.Printf("HANDOFF_TOKEN not set, starting from scratch")
+		return false
+	}
+	rb, sc, err := postURL(url, nil, map[string]string{"Authorization": "Bearer " + handoffToken})
+	if err != nil || sc != http.StatusOK {
+		log.Printf("Handoff failed, starting from scratch. Status:%d, err:%v", sc, err)
+		return false
+	}
+	var resp HandoffResponse
+	if err = json.Unmarshal(rb, &resp); err != nil {
+		logError(errHandoff, "Error unmarshalling handoff, starting from scratch: %s", err)
+		return false
+	}
+	if err = validateConsoleState(resp.State); err != nil {
+		log.Printf("Invalid handoff state, starting from scratch: %s", err)
+		return false
+	}
+	seedConsoleState(resp.State)
+	handoffKeyNodes = resp.PendingKeyNodes
+	log.Printf("Handoff complete: %d nodes, %d pod targets, %d pending key deployments",
+		len(resp.State.Nodes), len(resp.State.PodTargets), len(resp.PendingKeyNodes))
+	return true
+}
+
+// Seed the local state from a handed off document
+func seedConsoleState(st ConsoleState) {
+	newCache := make(map[string]nodeConsoleInfo)
+	for _, n := range st.Nodes {
+		newCache[n.NodeName] = n
+	}
+	nodeCache = newCache
+	maxMtnNodesPerPod = st.Settings.MaxMtnNodesPerPod
+	maxRvrNodesPerPod = st.Settings.MaxRvrNodesPerPod
+	if st.NumNodePods > 0 {
+		numNodePods = st.NumNodePods
+	}
+	if st.TargetNumMtnNodes > 0 && st.TargetNumRvrNodes > 0 {
+		numMtnNodesPerPod = st.TargetNumMtnNodes
+		numRvrNodesPerPod = st.TargetNumRvrNodes
+	}
+	podTargetsMutex.Lock()
+	podTargets = st.PodTargets
+	podTargetsMutex.Unlock()
+}
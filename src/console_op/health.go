@@ -1,7 +1,7 @@
 //
 //  MIT License
 //
-//  (C) Copyright 2021-2024 Hewlett Packard Enterprise Development LP
+//  (C) Copyright 2021-2024, 2026 Hewlett Packard Enterprise Development LP
 //
 //  Permission is hereby granted, free of charge, to any person obtaining a
 //  copy of this software and associated documentation files (the "Software"),
@@ -30,6 +30,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 )
 
 type HealthService interface {
@@ -37,30 +38,48 @@ type HealthService interface {
 	doHealth(w http.ResponseWriter, r *http.Request)
 	doReadiness(w http.ResponseWriter, r *http.Request)
 	getCurrentHealth() HealthResponse
+	doStatus(w http.ResponseWriter, r *http.Request)
 }
 
 // Implements HealthService
 type HealthManager struct {
 	dataService DataService
+	k8Service   K8Service
 }
 
 // Constructor injection for dependencies
-func NewHealthManager(ds DataService) HealthService {
-	return &HealthManager{dataService: ds}
+func NewHealthManager(ds DataService, k8s K8Service) HealthService {
+	return &HealthManager{dataService: ds, k8Service: k8s}
 }
 
 // HealthResponse - used to report service health stats
 type HealthResponse struct {
-	NumberConsoles       string `json:"consoles"`
-	HardwareUpdateSec    string `json:"hardwareupdatesec"`
-	LastHardwareUpdate   string `json:"hardwareupdate"`
-	NumberNodePods       string `json:"nodepods"`
-	NumberRvrNodesPerPod string `json:"rvrnodesperpod"`
-	NumberMtnNodesPerPod string `json:"mtnnodesperpod"`
-	MaxRvrNodesPerPod    string `json:"maxrvrnodesperpod"`
-	MaxMtnNodesPerPod    string `json:"maxmtnnodesperpod"`
-	HeartbeatCheckSec    string `json:"heartbeatcheck"`
-	HeartbeatStaleMin    string `json:"heartbeatstale"`
+	NumberConsoles        string `json:"consoles"`
+	HardwareUpdateSec     string `json:"hardwareupdatesec"`
+	LastHardwareUpdate    string `json:"hardwareupdate"`
+	NumberNodePods        string `json:"nodepods"`
+	NumberRvrNodesPerPod  string `json:"rvrnodesperpod"`
+	NumberMtnNodesPerPod  string `json:"mtnnodesperpod"`
+	MaxRvrNodesPerPod     string `json:"maxrvrnodesperpod"`
+	MaxMtnNodesPerPod     string `json:"maxmtnnodesperpod"`
+	HeartbeatCheckSec     string `json:"heartbeatcheck"`
+	HeartbeatStaleMin     string `json:"heartbeatstale"`
+	HardwareUpdateSeconds int    `json:"hardwareupdateseconds"`
+	HeartbeatCheckSeconds int    `json:"heartbeatcheckseconds"`
+	HeartbeatStaleMinutes int    `json:"heartbeatstaleminutes"`
+	ResourceNodePods      string `json:"resourcenodepods"`
+	MinNodePods           string `json:"minnodepods"`
+	MaxNodePods           string `json:"maxnodepods"`
+	ScaleToZero           string `json:"scaletozero"`
+	IsLeader              string `json:"isleader"`
+	Leader                string `json:"leader"`
+	OwnedShards           string `json:"ownedshards"`
+	HandedOff             string `json:"handedoff"`
+	ReadOnly              string `json:"readonly"`
+	KeyCreated            string `json:"keycreated"`
+	KeyAgeDays            string `json:"keyagedays"`
+	NextKeyRotation       string `json:"nextkeyrotation"`
+	KeyAgeWarning         string `json:"keyagewarning,omitempty"`
 }
 
 // Debugging information query
@@ -80,7 +99,7 @@ func (hm HealthManager) doHealth(w http.ResponseWriter, r *http.Request) {
 	stats := hm.getCurrentHealth()
 
 	// log the query
-	log.Printf("Health check: %s", stats)
+	log.Printf("Health check: %v", stats)
 
 	// write the output
 	SendResponseJSON(w, http.StatusOK, stats)
@@ -100,6 +119,29 @@ func (HealthManager) getCurrentHealth() HealthResponse {
 	stats.MaxMtnNodesPerPod = fmt.Sprintf("%d", maxMtnNodesPerPod)
 	stats.HeartbeatCheckSec = fmt.Sprintf("%d", heartbeatCheckPeriodSec)
 	stats.HeartbeatStaleMin = fmt.Sprintf("%d", heartbeatStaleMinutes)
+	stats.HardwareUpdateSeconds = newHardwareCheckPeriodSec
+	stats.HeartbeatCheckSeconds = heartbeatCheckPeriodSec
+	stats.HeartbeatStaleMinutes = heartbeatStaleMinutes
+	stats.ResourceNodePods = fmt.Sprintf("%d", resourceNodePods)
+	stats.MinNodePods = fmt.Sprintf("%d", minNodePods)
+	stats.MaxNodePods = fmt.Sprintf("%d", maxNodePods)
+	stats.ScaleToZero = fmt.Sprintf("%t", scaleToZero)
+	stats.IsLeader = fmt.Sprintf("%t", amLeader())
+	stats.Leader = getCurrentLeader()
+	stats.OwnedShards = fmt.Sprintf("%d of %d", numOwnedShards(), shardCount)
+	stats.HandedOff = fmt.Sprintf("%t", handoffActive())
+	stats.ReadOnly = fmt.Sprintf("%t", readOnlyMode)
+	now := time.Now()
+	if created := getKeyCreated(); !created.IsZero() {
+		stats.KeyCreated = formatTime(created)
+		stats.KeyAgeDays = fmt.Sprintf("%d", keyAgeDays(created, now))
+		if keyRotationDays > 0 {
+			stats.NextKeyRotation = formatTime(created.Add(time.Duration(keyRotationDays) * 24 * time.Hour))
+		}
+		if keyTooOld(created, now) {
+			stats.KeyAgeWarning = fmt.Sprintf("Key is older than the policy age of %d days", keyMaxAgeDays)
+		}
+	}
 	return stats
 }
 
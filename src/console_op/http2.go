@@ -0,0 +1,139 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to serve the api over cleartext http/2 next
+//  to http/1.1 on the same port
+
+package main
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// Serve http/2 to clients that start with the http/2 preface
+// NOTE: only prior knowledge http/2 is served, any http/1.1 request
+// including one asking for a websocket or h2c upgrade stays on http/1.1
+var http2Enabled bool = false
+
+// Time allowed for a new connection to send enough to tell the protocol
+const http2SniffTimeout time.Duration = 10 * time.Second
+
+// A connection that has had the start of its data read already
+type peekedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *peekedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// Listener that hands http/2 connections to the http/2 server and returns
+// the rest to the http/1.1 server
+type h2cListener struct {
+	net.Listener
+	srv   *http.Server
+	h2    *http2.Server
+	conns chan net.Conn
+	done  chan struct{}
+	err   error
+}
+
+// Start sorting the connections of the listener for the server
+func newH2CListener(l net.Listener, srv *http.Server) (*h2cListener, error) {
+	h2 := &http2.Server{}
+	// NOTE: this ties the http/2 connections into the server shutdown
+	if err := http2.ConfigureServer(srv, h2); err != nil {
+		return nil, err
+	}
+	hl := &h2cListener{Listener: l, srv: srv, h2: h2,
+		conns: make(chan net.Conn), done: make(chan struct{})}
+	go hl.acceptLoop()
+	return hl, nil
+}
+
+func (hl *h2cListener) acceptLoop() {
+	for {
+		conn, err := hl.Listener.Accept()
+		if err != nil {
+			hl.err = err
+			close(hl.done)
+			return
+		}
+		// NOTE: sort in the background so a slow client does not hold up
+		//  the connections behind it
+		go hl.sort(conn)
+	}
+}
+
+// Check if the connection starts with the http/2 preface
+func isHTTP2(r *bufio.Reader) bool {
+	for n := 1; n <= len(http2.ClientPreface); n++ {
+		b, err := r.Peek(n)
+		if err != nil || b[n-1] != http2.ClientPreface[n-1] {
+			return false
+		}
+	}
+	return true
+}
+
+func (hl *h2cListener) sort(conn net.Conn) {
+	pc := &peekedConn{Conn: conn, r: bufio.NewReader(conn)}
+	conn.SetReadDeadline(time.Now().Add(http2SniffTimeout))
+	h2 := isHTTP2(pc.r)
+	conn.SetReadDeadline(time.Time{})
+	if h2 {
+		hl.h2.ServeConn(pc, &http2.ServeConnOpts{BaseConfig: hl.srv, Handler: hl.srv.Handler})
+		return
+	}
+	select {
+	case hl.conns <- pc:
+	case <-hl.done:
+		pc.Close()
+	}
+}
+
+// Get the next http/1.1 connection
+func (hl *h2cListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-hl.conns:
+		return conn, nil
+	case <-hl.done:
+		return nil, hl.err
+	}
+}
+
+// Listen on the address of the server, serving http/2 as well if enabled
+func listenHTTP(srv *http.Server) (net.Listener, error) {
+	l, err := net.Listen("tcp", srv.Addr)
+	if err != nil || !http2Enabled {
+		return l, err
+	}
+	return newH2CListener(l, srv)
+}
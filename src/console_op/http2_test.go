@@ -0,0 +1,92 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"testing"
+
+	"golang.org/x/net/http2"
+)
+
+func TestH2CListener(t *testing.T) {
+	oldEnabled := http2Enabled
+	defer func() { http2Enabled = oldEnabled }()
+	http2Enabled = true
+	srv := &http.Server{Addr: "127.0.0.1:0",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Proto", r.Proto)
+		})}
+	l, err := listenHTTP(srv)
+	if err != nil {
+		t.Fatalf("Unable to listen: %s", err)
+	}
+	go srv.Serve(l)
+	defer srv.Close()
+	URL := "http://" + l.Addr().String() + "/console-operator/info"
+
+	// plain http/1.1 still works
+	resp, err := http.Get(URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	resp.Body.Close()
+	if resp.Header.Get("X-Proto") != "HTTP/1.1" {
+		t.Errorf("Expected: HTTP/1.1. Got: %s.", resp.Header.Get("X-Proto"))
+	}
+
+	// prior knowledge http/2 is served as http/2
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	resp, err = client.Get(URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	resp.Body.Close()
+	if resp.Header.Get("X-Proto") != "HTTP/2.0" {
+		t.Errorf("Expected: HTTP/2.0. Got: %s.", resp.Header.Get("X-Proto"))
+	}
+}
+
+func TestListenHTTPDisabled(t *testing.T) {
+	oldEnabled := http2Enabled
+	defer func() { http2Enabled = oldEnabled }()
+	http2Enabled = false
+
+	l, err := listenHTTP(&http.Server{Addr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("Unable to listen: %s", err)
+	}
+	defer l.Close()
+	if _, ok := l.(*h2cListener); ok {
+		t.Errorf("Expected a plain listener with http/2 disabled")
+	}
+}
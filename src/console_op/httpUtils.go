@@ -1,7 +1,7 @@
 //
 //  MIT License
 //
-//  (C) Copyright 2019-2022, 2024 Hewlett Packard Enterprise Development LP
+//  (C) Copyright 2019-2022, 2024, 2026 Hewlett Packard Enterprise Development LP
 //
 //  Permission is hereby granted, free of charge, to any person obtaining a
 //  copy of this software and associated documentation files (the "Software"),
@@ -43,7 +43,7 @@ func SendResponseJSON(w http.ResponseWriter, sc int, data interface{}) {
 	if data != nil {
 		err := json.NewEncoder(w).Encode(data)
 		if err != nil {
-			log.Printf("Error: encoding/sending JSON response: %s\n", err)
+			logError(errInternal, "Error: encoding/sending JSON response: %s\n", err)
 			return
 		}
 	}
@@ -51,12 +51,18 @@ func SendResponseJSON(w http.ResponseWriter, sc int, data interface{}) {
 
 // ErrResponse - Simple struct to return error information
 type ErrResponse struct {
-	E      int    `json:"e"` // Error code
-	ErrMsg string `json:"err_msg"`
+	E      int       `json:"e"` // Error code
+	ErrMsg string    `json:"err_msg"`
+	Code   errorCode `json:"code,omitempty"` // Catalog code, see errcodes.go
 }
 
 // Send error or empty OK response
 func sendJSONError(w http.ResponseWriter, ecode int, message string) {
+	sendJSONErrorCode(w, ecode, defaultErrorCode(ecode), message)
+}
+
+// Send an error response with a specific catalog code
+func sendJSONErrorCode(w http.ResponseWriter, ecode int, code errorCode, message string) {
 	// If HTTP call is success, put zero in returned json error field.
 	httpCode := ecode
 	if ecode >= 200 && ecode <= 299 {
@@ -66,6 +72,7 @@ func sendJSONError(w http.ResponseWriter, ecode int, message string) {
 	data := ErrResponse{
 		E:      ecode,
 		ErrMsg: message,
+		Code:   code,
 	}
 
 	SendResponseJSON(w, httpCode, data)
@@ -74,7 +81,6 @@ func sendJSONError(w http.ResponseWriter, ecode int, message string) {
 // Helper function to execute an http command
 func getURL(URL string, requestHeaders map[string]string) ([]byte, int, error) {
 	var err error = nil
-	log.Printf("getURL URL: %s\n", URL)
 	req, err := http.NewRequest("GET", URL, nil)
 	if err != nil {
 		// handle error
@@ -86,6 +92,7 @@ func getURL(URL string, requestHeaders map[string]string) ([]byte, int, error) {
 			req.Header.Add(k, v)
 		}
 	}
+	log.Printf("getURL URL: %s request id: %s", URL, setRequestID(req))
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
@@ -98,7 +105,7 @@ func getURL(URL string, requestHeaders map[string]string) ([]byte, int, error) {
 	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		// handle error
-		log.Printf("Error reading response: %s", err)
+		logError(errInternal, "Error reading response: %s", err)
 		return nil, resp.StatusCode, err
 	}
 	// NOTE: Dumping entire response clogs up the log file but keep for debugging
@@ -109,7 +116,6 @@ func getURL(URL string, requestHeaders map[string]string) ([]byte, int, error) {
 // Helper function to execute an http POST command
 func postURL(URL string, requestBody []byte, requestHeaders map[string]string) ([]byte, int, error) {
 	var err error = nil
-	log.Printf("postURL URL: %s\n", URL)
 	req, err := http.NewRequest("POST", URL, bytes.NewReader(requestBody))
 	if err != nil {
 		// handle error
@@ -122,6 +128,7 @@ func postURL(URL string, requestBody []byte, requestHeaders map[string]string) (
 			req.Header.Add(k, v)
 		}
 	}
+	log.Printf("postURL URL: %s request id: %s", URL, setRequestID(req))
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
@@ -145,7 +152,6 @@ func postURL(URL string, requestBody []byte, requestHeaders map[string]string) (
 // Helper function to execute an http PUT command
 func putURL(URL string, requestBody []byte, requestHeaders map[string]string) ([]byte, int, error) {
 	var err error = nil
-	log.Printf("putURL URL: %s\n", URL)
 	req, err := http.NewRequest("PUT", URL, bytes.NewReader(requestBody))
 	if err != nil {
 		// handle error
@@ -158,6 +164,7 @@ func putURL(URL string, requestBody []byte, requestHeaders map[string]string) ([
 			req.Header.Add(k, v)
 		}
 	}
+	log.Printf("putURL URL: %s request id: %s", URL, setRequestID(req))
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
@@ -181,7 +188,6 @@ func putURL(URL string, requestBody []byte, requestHeaders map[string]string) ([
 // Helper function to execute an http PUT command
 func deleteURL(URL string, requestBody []byte, requestHeaders map[string]string) ([]byte, int, error) {
 	var err error = nil
-	log.Printf("deleteURL URL: %s\n", URL)
 	req, err := http.NewRequest("DELETE", URL, bytes.NewReader(requestBody))
 	if err != nil {
 		// handle error
@@ -194,6 +200,7 @@ func deleteURL(URL string, requestBody []byte, requestHeaders map[string]string)
 			req.Header.Add(k, v)
 		}
 	}
+	log.Printf("deleteURL URL: %s request id: %s", URL, setRequestID(req))
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
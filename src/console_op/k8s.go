@@ -1,7 +1,7 @@
 //
 //  MIT License
 //
-//  (C) Copyright 2021-2023 Hewlett Packard Enterprise Development LP
+//  (C) Copyright 2021-2023, 2026 Hewlett Packard Enterprise Development LP
 //
 //  Permission is hereby granted, free of charge, to any person obtaining a
 //  copy of this software and associated documentation files (the "Software"),
@@ -27,14 +27,26 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/client-go/rest"
@@ -44,12 +56,61 @@ import (
 // a shared file system so console-node pods can read what is set here
 const targetNodeFile string = "/var/log/console/TargetNodes.txt"
 
+// The kind (StatefulSet or Deployment) and name of the console-node workload
+// that is scaled by the operator - more pools may be added in other
+// namespaces with CONSOLE_NODE_POOLS
+var consoleNodeKind string = "StatefulSet"
+var consoleNodeName string = "cray-console-node"
+
+// Namespace the operator pod runs in, set from the downward api
+var operatorNamespace string = "services"
+
+// Namespace the console-node workload and its pods run in
+var consoleNodeNamespace string = "services"
+
+// Per pod target files used when the nodes are split by pod capacity - the
+// pod name is inserted before the extension of the target node file
+// NOTE: these are only used once console-node reads its own file, see
+// Cray-HPE/console-node.  Until then TargetNodes.txt is the source of truth
+// and is always written with the even split.
+const podTargetNodeFilePrefix string = "/var/log/console/TargetNodes-"
+const podTargetNodeFileSuffix string = ".txt"
+
+// Longest a command run in a console-node pod may take
+const podExecTimeout = 30 * time.Second
+
+// Annotation a console-node pod may set to declare its own capacity - this
+// takes precedence over the cpu requested by the pod
+const podCapacityAnnotation string = "console.cray.com/capacity"
+
 type K8Service interface {
 	printK8sInfo()
 	getReplicaCount() (replicaCnt int, err error)
 	updateReplicaCount(newReplicaCnt int)
+	isRolloutComplete() (done bool, err error)
 	updateNodesPerPod(newNumMtn, newNumRvr int)
 	getPodLocationAlias(podID string) (loc string, err error)
+	getConsoleNodePods() (podNames []string, err error)
+	execInPod(podName, container string, cmd []string) (output string, err error)
+	getConsoleNodePodUsage() (usage []podResourceUsage, err error)
+	getConsoleNodePodCapacity() (capacity map[string]int64, err error)
+	recordEvent(target eventTarget, eventType, reason, message string)
+	getKeySecret() (map[string][]byte, error)
+	saveKeySecret(data map[string][]byte) error
+	updatePlacementHints(terms []corev1.PreferredSchedulingTerm)
+	updatePodTargets(targets []podTarget)
+	getConsoleNodePodState(podName string) (uid string, ready bool, err error)
+	deleteConsoleNodePod(podName string) error
+}
+
+// Struct to hold the resource usage and limits of a console-node pod
+// NOTE: cpu values are in millicores and memory values are in bytes
+type podResourceUsage struct {
+	PodName  string
+	CPUUsage int64
+	MemUsage int64
+	CPULimit int64
+	MemLimit int64
 }
 
 // Implements K8Service
@@ -90,11 +151,11 @@ func (k8s K8Manager) printK8sInfo() {
 
 	// Or specify namespace to get pods in particular namespace
 	log.Printf("Getting Pods in namespace...")
-	pods, err := k8s.clientset.CoreV1().Pods("services").List(metav1.ListOptions{})
+	pods, err := k8s.clientset.CoreV1().Pods(operatorNamespace).List(metav1.ListOptions{})
 	if err != nil {
 		log.Printf("PodsList error: %s", err.Error())
 	}
-	log.Printf("There are %d pods in the services namespace in the cluster\n", len(pods.Items))
+	log.Printf("There are %d pods in the %s namespace in the cluster\n", len(pods.Items), operatorNamespace)
 
 	// print details on each pod found
 	for _, pod := range pods.Items {
@@ -105,43 +166,131 @@ func (k8s K8Manager) printK8sInfo() {
 	// - Use helper functions e.g. errors.IsNotFound()
 	// - And/or cast to StatusError and use its properties like e.g. ErrStatus.Message
 	log.Printf("Getting cray-console-node pods...")
-	_, err = k8s.clientset.CoreV1().Pods("services").Get("cray-console-node", metav1.GetOptions{})
+	_, err = k8s.clientset.CoreV1().Pods(operatorNamespace).Get("cray-console-node", metav1.GetOptions{})
 	if errors.IsNotFound(err) {
-		log.Printf("Pod cray-console-node not found in services namespace\n")
+		log.Printf("Pod cray-console-node not found in %s namespace\n", operatorNamespace)
 	} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
-		log.Printf("Error getting pod %v\n", statusError.ErrStatus.Message)
+		logError(errK8s, "Error getting pod %v\n", statusError.ErrStatus.Message)
 	} else if err != nil {
-		log.Printf("Error getting pod: %s", err.Error())
+		logError(errK8s, "Error getting pod: %s", err.Error())
 	} else {
 		fmt.Printf("Found cray-conman pod in default namespace\n")
 	}
 
 }
 
-// Grab the current number of console-node replicas from k8s
+// The replica information common to the kinds of console-node workload
+type consoleNodeWorkload struct {
+	Namespace          string
+	UID                types.UID
+	Replicas           int32
+	Generation         int64
+	ObservedGeneration int64
+	StatusReplicas     int32
+	ReadyReplicas      int32
+	Selector           *metav1.LabelSelector
+	statefulSet        *appsv1.StatefulSet
+	deployment         *appsv1.Deployment
+}
+
+// Get the console-node workload of the configured kind and name
+func (k8s K8Manager) getConsoleNodeWorkload() (*consoleNodeWorkload, error) {
+	return k8s.getPoolWorkload(getConsoleNodePools()[0])
+}
+
+// Set the number of replicas of the console-node workload
+func (k8s K8Manager) setConsoleNodeReplicas(wl *consoleNodeWorkload, replicas int32) (int32, error) {
+	if wl.deployment != nil {
+		wl.deployment.Spec.Replicas = &replicas
+	} else {
+		wl.statefulSet.Spec.Replicas = &replicas
+	}
+	if err := k8s.updateConsoleNodeWorkload(wl); err != nil {
+		return 0, err
+	}
+	return wl.Replicas, nil
+}
+
+// Push changes made to the console-node workload
+func (k8s K8Manager) updateConsoleNodeWorkload(wl *consoleNodeWorkload) error {
+	if wl.deployment != nil {
+		dep, err := k8s.clientset.AppsV1().Deployments(wl.Namespace).Update(wl.deployment)
+		if err != nil {
+			return err
+		}
+		wl.deployment = dep
+		if dep.Spec.Replicas != nil {
+			wl.Replicas = *dep.Spec.Replicas
+		}
+		return nil
+	}
+	ss, err := k8s.clientset.AppsV1().StatefulSets(wl.Namespace).Update(wl.statefulSet)
+	if err != nil {
+		return err
+	}
+	wl.statefulSet = ss
+	if ss.Spec.Replicas != nil {
+		wl.Replicas = *ss.Spec.Replicas
+	}
+	return nil
+}
+
+// Grab the current number of console-node replicas from k8s, the total of
+// all the pools
 func (k8s K8Manager) getReplicaCount() (replicaCnt int, err error) {
-	// get the stateful set
-	consoleNodeRepCount := -1
-	dep, err := k8s.clientset.AppsV1().StatefulSets("services").Get("cray-console-node", metav1.GetOptions{})
-	if errors.IsNotFound(err) {
-		log.Printf("StatefulSet cray-console-node not found in services namespace\n")
-		return consoleNodeRepCount, err
-	} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
-		log.Printf("Error getting statefulSet cray-console-node in services namespace: %v\n", statusError.ErrStatus.Message)
-		return consoleNodeRepCount, err
-	} else if err != nil {
-		log.Printf("Unknown error getting statefulSet cray-console-node in services namespace: %s", err.Error())
-		return consoleNodeRepCount, err
+	for _, pool := range getConsoleNodePools() {
+		wl, err := k8s.getPoolWorkload(pool)
+		if err != nil {
+			return -1, err
+		}
+		replicaCnt += int(wl.Replicas)
 	}
+	return replicaCnt, nil
+}
+
+// Check if all the console-node replicas of every pool are ready
+func (k8s K8Manager) isRolloutComplete() (done bool, err error) {
+	for _, pool := range getConsoleNodePools() {
+		wl, err := k8s.getPoolWorkload(pool)
+		if err != nil {
+			return false, err
+		}
 
-	consoleNodeRepCount = int(*dep.Spec.Replicas)
-	return consoleNodeRepCount, nil
+		// NOTE: the status is only meaningful once the controller has seen the
+		//  latest spec
+		poolDone := wl.ObservedGeneration >= wl.Generation &&
+			wl.StatusReplicas == wl.Replicas &&
+			wl.ReadyReplicas == wl.Replicas
+		if !poolDone {
+			log.Printf("%s rollout in progress - replicas: %d, ready: %d, wanted: %d",
+				pool.Name, wl.StatusReplicas, wl.ReadyReplicas, wl.Replicas)
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Keep the number of console-node pods within the site configured bounds
+// NOTE: zero is allowed through when scaling to zero is enabled
+func boundNodePods(numPods int) int {
+	if numPods == 0 && scaleToZero {
+		return 0
+	}
+	if numPods < minNodePods {
+		log.Printf("Raising console-node pods from %d to the minimum of %d", numPods, minNodePods)
+		return minNodePods
+	}
+	if numPods > maxNodePods {
+		log.Printf("Limiting console-node pods from %d to the maximum of %d", numPods, maxNodePods)
+		return maxNodePods
+	}
+	return numPods
 }
 
 // Function to update the number of console-node replicas
 func (k8s K8Manager) updateReplicaCount(newReplicaCnt int) {
 	// This function interacts with k8s to check the current number of replicas
-	// in the console-node statefulset.  It will change the replica count to
+	// in the console-node workload.  It will change the replica count to
 	// match what it should be creating new pods or destroying current ones.
 
 	// ensure that k8s was initialized correctly
@@ -150,41 +299,25 @@ func (k8s K8Manager) updateReplicaCount(newReplicaCnt int) {
 		return
 	}
 
-	// get the stateful set
-	dep, err := k8s.clientset.AppsV1().StatefulSets("services").Get("cray-console-node", metav1.GetOptions{})
-	if errors.IsNotFound(err) {
-		log.Printf("StatefulSet cray-console-node not found in services namespace\n")
-		return
-	} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
-		log.Printf("Error getting statefulSet %v\n", statusError.ErrStatus.Message)
-		return
-	} else if err != nil {
-		log.Printf("Unknown error getting statefulSet: %s", err.Error())
-		return
-	}
-
-	// Find the current number of replicas in the deployment
-	currReplicas := *dep.Spec.Replicas
-	log.Printf("Current console-node replicas: %d, Requested replicas: %d", currReplicas, newReplicaCnt)
+	// never go outside of the configured bounds
+	newReplicaCnt = boundNodePods(newReplicaCnt)
 
-	// if the numbers don't match, update the replica count
-	if int32(newReplicaCnt) != currReplicas {
-		// update deployment to the desired number
-		*dep.Spec.Replicas = int32(newReplicaCnt)
-		newDep, err := k8s.clientset.AppsV1().StatefulSets("services").Update(dep)
-		if err != nil {
-			// NOTE - do not reset numNodePods if this failed, that should trigger
-			//  a retry the next time it checks
-			log.Printf("Error updating deployment: %s", err.Error())
-			return
+	// each pool is scaled to its share on its own so one failing pool does
+	// not hold up the rest
+	pools := getConsoleNodePools()
+	failed := 0
+	for i, replicas := range splitPoolReplicas(newReplicaCnt, pools) {
+		if err := k8s.scalePool(pools[i], replicas); err != nil {
+			failed++
 		}
-		log.Printf("  Updated stateful set to %d replicas", *newDep.Spec.Replicas)
-	} else {
-		log.Printf("  Already correct number of replicas in deployment")
 	}
 
 	// only set the global number when successful
-	numNodePods = newReplicaCnt
+	// NOTE - do not reset numNodePods if a pool failed, that should trigger
+	//  a retry the next time it checks
+	if failed == 0 {
+		numNodePods = newReplicaCnt
+	}
 }
 
 // keep track of the number of file access errors
@@ -204,7 +337,7 @@ func (K8Manager) updateNodesPerPod(newNumMtn, newNumRvr int) {
 	// make sure the directory exists to put the file in place
 	pos := strings.LastIndex(targetNodeFile, "/")
 	if pos < 0 {
-		log.Printf("Error: incorrect target node file name: %s", targetNodeFile)
+		logError(errFileSystem, "Error: incorrect target node file name: %s", targetNodeFile)
 		return
 	}
 	targetNodeDir := targetNodeFile[:pos]
@@ -230,7 +363,7 @@ func (K8Manager) updateNodesPerPod(newNumMtn, newNumRvr int) {
 		if numFileErrors > 3 {
 			log.Panicf("Multiple file access errors, unable to open config file to write: %s", err)
 		}
-		log.Printf("Error: Unable to open config file to write: %s", err)
+		logError(errFileSystem, "Error: Unable to open config file to write: %s", err)
 		numFileErrors += 1
 		return
 	}
@@ -249,14 +382,263 @@ func (K8Manager) updateNodesPerPod(newNumMtn, newNumRvr int) {
 	numRvrNodesPerPod = newNumRvr
 }
 
+// Write the per pod target files and remove any left from pods that are gone
+// NOTE: this is in addition to the shared target node file, never instead of
+// it - a console-node that does not read its own file uses the shared one
+func (K8Manager) updatePodTargets(targets []podTarget) {
+	current := make(map[string]struct{})
+	for _, pt := range targets {
+		fileName := podTargetNodeFilePrefix + pt.PodName + podTargetNodeFileSuffix
+		current[fileName] = struct{}{}
+		data := fmt.Sprintf("River:%d\nMountain:%d\n", pt.TargetNumRvrNodes, pt.TargetNumMtnNodes)
+		if err := ioutil.WriteFile(fileName, []byte(data), 0666); err != nil {
+			logError(errFileSystem, "Error: Unable to write pod target file %s: %s", fileName, err)
+			return
+		}
+	}
+
+	files, err := filepath.Glob(podTargetNodeFilePrefix + "*" + podTargetNodeFileSuffix)
+	if err != nil {
+		logError(errFileSystem, "Error finding pod target files: %s", err)
+		return
+	}
+	for _, f := range files {
+		if _, found := current[f]; !found {
+			log.Printf("Removing pod target file: %s", f)
+			if err := os.Remove(f); err != nil {
+				logError(errFileSystem, "Error removing pod target file %s: %s", f, err)
+			}
+		}
+	}
+}
+
 // Find and return where the current pod is running in k8s
 func (k8s K8Manager) getPodLocationAlias(podID string) (loc string, err error) {
-	pod, err := k8s.clientset.CoreV1().Pods("services").Get(podID, metav1.GetOptions{})
+	pod, err := k8s.clientset.CoreV1().Pods(podNamespace(podID)).Get(podID, metav1.GetOptions{})
 	if err != nil {
-		log.Printf("Error: Unable to find the node for pod %s, %s", podID, err)
+		logError(errK8s, "Error: Unable to find the node for pod %s, %s", podID, err)
 		return "", err
 	}
 
 	loc = pod.Spec.NodeName
 	return loc, err
 }
+
+// Find the running console-node pods of all the pools
+func (k8s K8Manager) getRunningConsoleNodePods() ([]corev1.Pod, error) {
+	var running []corev1.Pod = nil
+	for _, pool := range getConsoleNodePools() {
+		pods, err := k8s.getRunningPoolPods(pool)
+		if err != nil {
+			return nil, err
+		}
+		running = append(running, pods...)
+	}
+	return running, nil
+}
+
+// Find the names of the running console-node pods
+func (k8s K8Manager) getConsoleNodePods() (podNames []string, err error) {
+	pods, err := k8s.getRunningConsoleNodePods()
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods {
+		podNames = append(podNames, pod.GetName())
+	}
+	return podNames, nil
+}
+
+// Get the current resource usage of the console-node pods from metrics-server
+func (k8s K8Manager) getConsoleNodePodUsage() (usage []podResourceUsage, err error) {
+	pods, err := k8s.getRunningConsoleNodePods()
+	if err != nil {
+		return nil, err
+	}
+
+	// the limits come from the console-node container of each pod - fall
+	// back to the requests if there are no limits
+	limits := make(map[string]podResourceUsage)
+	for _, pod := range pods {
+		for _, c := range pod.Spec.Containers {
+			if c.Name != consoleNodeContainer {
+				continue
+			}
+			pu := podResourceUsage{PodName: pod.GetName()}
+			if q, ok := c.Resources.Limits[corev1.ResourceCPU]; ok {
+				pu.CPULimit = q.MilliValue()
+			} else if q, ok := c.Resources.Requests[corev1.ResourceCPU]; ok {
+				pu.CPULimit = q.MilliValue()
+			}
+			if q, ok := c.Resources.Limits[corev1.ResourceMemory]; ok {
+				pu.MemLimit = q.Value()
+			} else if q, ok := c.Resources.Requests[corev1.ResourceMemory]; ok {
+				pu.MemLimit = q.Value()
+			}
+			limits[pu.PodName] = pu
+		}
+	}
+
+	// NOTE: there is no typed client vendored for the metrics api so query
+	//  it directly and only decode the parts needed here
+	type containerMetrics struct {
+		Name  string            `json:"name"`
+		Usage map[string]string `json:"usage"`
+	}
+	type podMetrics struct {
+		Metadata   metav1.ObjectMeta  `json:"metadata"`
+		Containers []containerMetrics `json:"containers"`
+	}
+	type podMetricsList struct {
+		Items []podMetrics `json:"items"`
+	}
+	var pml podMetricsList
+	for _, pool := range getConsoleNodePools() {
+		wl, err := k8s.getPoolWorkload(pool)
+		if err != nil {
+			return nil, err
+		}
+		selector, err := metav1.LabelSelectorAsSelector(wl.Selector)
+		if err != nil {
+			logError(errK8s, "Error parsing the %s selector: %s", pool.Name, err)
+			return nil, err
+		}
+		data, err := k8s.clientset.CoreV1().RESTClient().Get().
+			AbsPath("/apis/metrics.k8s.io/v1beta1/namespaces/"+pool.Namespace+"/pods").
+			Param("labelSelector", selector.String()).
+			DoRaw()
+		if err != nil {
+			logError(errK8s, "Error getting console-node pod metrics: %s", err)
+			return nil, err
+		}
+		var poolMetrics podMetricsList
+		if err = json.Unmarshal(data, &poolMetrics); err != nil {
+			logError(errK8s, "Error unmarshalling console-node pod metrics: %s", err)
+			return nil, err
+		}
+		pml.Items = append(pml.Items, poolMetrics.Items...)
+	}
+
+	// combine the usage with the limits
+	for _, pm := range pml.Items {
+		pu, ok := limits[pm.Metadata.Name]
+		if !ok {
+			continue
+		}
+		for _, c := range pm.Containers {
+			if c.Name != consoleNodeContainer {
+				continue
+			}
+			if q, err := resource.ParseQuantity(c.Usage["cpu"]); err == nil {
+				pu.CPUUsage = q.MilliValue()
+			}
+			if q, err := resource.ParseQuantity(c.Usage["memory"]); err == nil {
+				pu.MemUsage = q.Value()
+			}
+		}
+		usage = append(usage, pu)
+	}
+	return usage, nil
+}
+
+// Get the capacity of each running console-node pod - this is the value of
+// the capacity annotation if present, otherwise the cpu request in millicores
+// NOTE: a pod with no declared capacity is reported as 0
+func (k8s K8Manager) getConsoleNodePodCapacity() (capacity map[string]int64, err error) {
+	pods, err := k8s.getRunningConsoleNodePods()
+	if err != nil {
+		return nil, err
+	}
+
+	capacity = make(map[string]int64)
+	for _, pod := range pods {
+		var podCap int64 = 0
+		if v, ok := pod.GetAnnotations()[podCapacityAnnotation]; ok {
+			if c, err := strconv.ParseInt(v, 10, 64); err == nil && c > 0 {
+				podCap = c
+			} else {
+				log.Printf("Invalid capacity annotation on pod %s: %s", pod.GetName(), v)
+			}
+		}
+		if podCap == 0 {
+			for _, c := range pod.Spec.Containers {
+				if c.Name != consoleNodeContainer {
+					continue
+				}
+				if q, ok := c.Resources.Requests[corev1.ResourceCPU]; ok {
+					podCap = q.MilliValue()
+				}
+			}
+		}
+		capacity[pod.GetName()] = podCap
+	}
+	return capacity, nil
+}
+
+// Get the uid of a console-node pod and if it is ready to serve consoles
+// NOTE: a pod that does not exist has an empty uid
+func (k8s K8Manager) getConsoleNodePodState(podName string) (uid string, ready bool, err error) {
+	pod, err := k8s.clientset.CoreV1().Pods(podNamespace(podName)).Get(podName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+	if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+		return string(pod.GetUID()), false, nil
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return string(pod.GetUID()), c.Status == corev1.ConditionTrue, nil
+		}
+	}
+	return string(pod.GetUID()), false, nil
+}
+
+// Delete a console-node pod so the workload starts a new one
+func (k8s K8Manager) deleteConsoleNodePod(podName string) error {
+	return k8s.clientset.CoreV1().Pods(podNamespace(podName)).Delete(podName, &metav1.DeleteOptions{})
+}
+
+// Run a command in a container of a pod and return the output
+func (k8s K8Manager) execInPod(podName, container string, cmd []string) (output string, err error) {
+	// build the request for the exec sub-resource of the pod
+	req := k8s.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(podNamespace(podName)).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   cmd,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	exec, err := remotecommand.NewSPDYExecutor(k8s.config, "POST", req.URL())
+	if err != nil {
+		logError(errK8s, "Error setting up exec in pod %s: %s", podName, err)
+		return "", err
+	}
+
+	// run the command and collect the output
+	// NOTE: this client-go has no Stream with a context, so the stream runs on
+	//  its own and is given up on after the timeout - the buffers are left
+	//  to it since it may still be writing to them
+	var stdout, stderr bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- exec.Stream(remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr})
+	}()
+	select {
+	case err = <-done:
+	case <-time.After(podExecTimeout):
+		logError(errK8s, "Timed out after %s running %v in pod %s", podExecTimeout, cmd, podName)
+		return "", fmt.Errorf("Timed out after %s running %v in pod %s", podExecTimeout, cmd, podName)
+	}
+	if err != nil {
+		logError(errK8s, "Error running %v in pod %s: %s, stderr: %s", cmd, podName, err, stderr.String())
+		return stdout.String(), err
+	}
+	return stdout.String(), nil
+}
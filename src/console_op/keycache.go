@@ -0,0 +1,266 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to cache the mountain console key from vault
+//  in a k8s secret so the same key can be used when vault is not available,
+//  optionally encrypted with a data key from vault
+
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+
+	"github.com/tidwall/gjson"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Name of the secret the vault key is cached in and the keys it is kept under
+const keySecretName string = "cray-console-operator-keys"
+const keySecretKey string = "conman.key"
+const keySecretSealedKey string = "conman.key.sealed"
+const keySecretDataKey string = "datakey"
+
+// Encrypt the cached key with a data key from vault transit before it is
+// written to the secret
+// NOTE: with this on the cached key can only be read while vault is up, it
+// still covers the console key itself being lost from vault
+var keyCacheEncryption bool = false
+
+// Vault transit key the data keys are wrapped with
+const keyCacheTransitKey string = "console-operator-keycache"
+
+// Service used to cache the key - nil when k8s is not available
+var keyCacheService K8Service = nil
+
+// Cache the private key from vault if k8s is available
+func cacheMountainConsoleKey(pvtKey string) {
+	if keyCacheService == nil {
+		return
+	}
+	data := map[string][]byte{keySecretKey: []byte(pvtKey)}
+	if keyCacheEncryption {
+		var err error
+		if data, err = sealKeyForCache(pvtKey); err != nil {
+			log.Printf("Unable to encrypt the console key for the cache: %s", err)
+			return
+		}
+	}
+	if err := keyCacheService.saveKeySecret(data); err != nil {
+		log.Printf("Unable to cache the console key in secret %s: %s", keySecretName, err)
+	}
+}
+
+// Restore the key files from the cached copy of the vault key
+func loadCachedMountainConsoleKey() error {
+	if keyCacheService == nil {
+		return fmt.Errorf("No k8s secret to get the cached console key from")
+	}
+	data, err := keyCacheService.getKeySecret()
+	if err == nil {
+		var pvtKey string
+		if pvtKey, err = unsealCachedKey(data); err == nil {
+			log.Printf("Using the console key cached in secret %s", keySecretName)
+			return writeMountainConsoleKeys(pvtKey)
+		}
+	}
+	log.Printf("Unable to use the cached console key: %s", err)
+	return err
+}
+
+// Encrypt the private key with a new data key from vault
+func sealKeyForCache(pvtKey string) (map[string][]byte, error) {
+	vaultToken, err := vaultLogin()
+	if err != nil {
+		return nil, err
+	}
+	if err = vaultEnsureTransitKey(vaultToken, keyCacheTransitKey); err != nil {
+		return nil, err
+	}
+	dataKey, wrapped, err := vaultNewDataKey(vaultToken, keyCacheTransitKey)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := sealKey(dataKey, []byte(pvtKey))
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{keySecretSealedKey: sealed, keySecretDataKey: []byte(wrapped)}, nil
+}
+
+// Get the private key from the cached secret data, decrypting it if needed
+func unsealCachedKey(data map[string][]byte) (string, error) {
+	if pvtKey, found := data[keySecretKey]; found && len(pvtKey) > 0 {
+		return string(pvtKey), nil
+	}
+	sealed, found := data[keySecretSealedKey]
+	wrapped, foundKey := data[keySecretDataKey]
+	if !found || !foundKey {
+		return "", fmt.Errorf("Secret %s does not hold a console key", keySecretName)
+	}
+	vaultToken, err := vaultLogin()
+	if err != nil {
+		return "", err
+	}
+	dataKey, err := vaultUnwrapDataKey(vaultToken, keyCacheTransitKey, string(wrapped))
+	if err != nil {
+		return "", err
+	}
+	pvtKey, err := openKey(dataKey, sealed)
+	return string(pvtKey), err
+}
+
+// Encrypt with AES-GCM, the nonce is put in front of the result
+func sealKey(dataKey, plain []byte) ([]byte, error) {
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+// Decrypt the result of sealKey
+func openKey(dataKey, sealed []byte) ([]byte, error) {
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("Sealed console key is too short")
+	}
+	return gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+}
+
+// Create a vault transit encryption key if it does not exist yet
+func vaultEnsureTransitKey(vaultToken, keyName string) error {
+	vaultRequestHeaders := map[string]string{"X-Vault-Token": vaultToken}
+	URL := vaultBase + "/transit/keys/" + keyName
+	_, responseCode, err := getURL(URL, vaultRequestHeaders)
+	if err != nil {
+		return err
+	}
+	if responseCode == 200 {
+		return nil
+	}
+	param, _ := json.Marshal(map[string]string{"type": "aes256-gcm96"})
+	response, responseCode, err := postURL(URL, param, vaultRequestHeaders)
+	if err != nil {
+		return err
+	}
+	if responseCode != 200 && responseCode != 204 {
+		return fmt.Errorf("Unexpected response from Vault when creating key %s: %s  Http response code: %d",
+			keyName, response, responseCode)
+	}
+	return nil
+}
+
+// Get a new data key from vault, both in the clear and wrapped by the transit key
+func vaultNewDataKey(vaultToken, keyName string) ([]byte, string, error) {
+	vaultRequestHeaders := map[string]string{"X-Vault-Token": vaultToken}
+	URL := vaultBase + "/transit/datakey/plaintext/" + keyName
+	param, _ := json.Marshal(map[string]int{"bits": 256})
+	response, responseCode, err := postURL(URL, param, vaultRequestHeaders)
+	if err != nil {
+		return nil, "", err
+	}
+	if responseCode != 200 {
+		return nil, "", fmt.Errorf("Unexpected response from Vault when creating a data key: %s  Http response code: %d",
+			response, responseCode)
+	}
+	dataKey, err := base64.StdEncoding.DecodeString(gjson.Get(string(response), "data.plaintext").String())
+	if err != nil {
+		return nil, "", err
+	}
+	return dataKey, gjson.Get(string(response), "data.ciphertext").String(), nil
+}
+
+// Unwrap a data key with the transit key
+func vaultUnwrapDataKey(vaultToken, keyName, wrapped string) ([]byte, error) {
+	vaultRequestHeaders := map[string]string{"X-Vault-Token": vaultToken}
+	URL := vaultBase + "/transit/decrypt/" + keyName
+	param, _ := json.Marshal(map[string]string{"ciphertext": wrapped})
+	response, responseCode, err := postURL(URL, param, vaultRequestHeaders)
+	if err != nil {
+		return nil, err
+	}
+	if responseCode != 200 {
+		return nil, fmt.Errorf("Unexpected response from Vault when decrypting the data key: %s  Http response code: %d",
+			response, responseCode)
+	}
+	return base64.StdEncoding.DecodeString(gjson.Get(string(response), "data.plaintext").String())
+}
+
+// Get the cached key data
+func (k8s K8Manager) getKeySecret() (map[string][]byte, error) {
+	secret, err := k8s.clientset.CoreV1().Secrets(operatorNamespace).Get(keySecretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return secret.Data, nil
+}
+
+// Save the key data in the cache secret, creating it if needed
+func (k8s K8Manager) saveKeySecret(data map[string][]byte) error {
+	secrets := k8s.clientset.CoreV1().Secrets(operatorNamespace)
+	secret, err := secrets.Get(keySecretName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: keySecretName, Namespace: operatorNamespace},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       data,
+		}
+		_, err = secrets.Create(secret)
+		return err
+	} else if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(secret.Data, data) {
+		return nil
+	}
+	// NOTE: replace everything so a plain copy does not stay behind when
+	//  switching to an encrypted one
+	secret.Data = data
+	_, err = secrets.Update(secret)
+	return err
+}
@@ -0,0 +1,564 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to rotate the mountain console ssh key on a
+//  schedule and redeploy it to the mountain bmcs
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tidwall/gjson"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Rotate the mountain console key after this many days - 0 disables rotation
+var keyRotationDays int = 0
+
+// Warn when the key is older than this many days - 0 disables the warning
+var keyMaxAgeDays int = 365
+
+// Hours the consoles stay on the old key while a rotated key is deployed to
+// the bmcs - 0 moves them as soon as the deploy is done
+// NOTE: console-node only reads the one key file, so the rotated key is
+// staged next to it and only replaces it once every bmc has the rotated key
+// or the window is over
+var keyRotationGraceHours int = 24
+
+// Key files a rotated key is staged in until it is on every bmc
+func nextKeyFiles(keyFile string) (string, string) {
+	return keyFile + ".next", keyFile + ".next.pub"
+}
+
+// Staged public key files of all the rotated keys
+func stagedKeyFiles() []string {
+	files, _ := filepath.Glob(filepath.Join(filepath.Dir(mountainConsoleKey), "*.key.next.pub"))
+	return files
+}
+
+// How often to check if the key is due for rotation and retry failed deployments
+const keyRotationCheckPeriod = 10 * time.Minute
+
+// Maximum number of rotation records kept
+const maxKeyRotationHistory int = 20
+
+// Record of a single key rotation
+type keyRotationRecord struct {
+	Started   string `json:"started"`
+	Completed string `json:"completed"`
+	Reason    string `json:"reason"`
+	Success   bool   `json:"success"`
+	NumNodes  int    `json:"numnodes"`
+	NumFailed int    `json:"numfailed"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Current state of key rotation
+var keyRotationMutex sync.Mutex
+var keyRotationInProgress bool = false
+var keyRotationHistory []keyRotationRecord = nil
+var keyRotationPending map[string]nodeConsoleInfo = make(map[string]nodeConsoleInfo)
+var keyCreated time.Time
+var keyGraceUntil time.Time
+
+// KeyRotationStatus - status and history of key rotation
+type KeyRotationStatus struct {
+	Enabled      bool                `json:"enabled"`
+	PeriodDays   int                 `json:"perioddays"`
+	KeyCreated   string              `json:"keycreated"`
+	NextRotation string              `json:"nextrotation"`
+	GraceUntil   string              `json:"graceuntil,omitempty"`
+	InProgress   bool                `json:"inprogress"`
+	PendingNodes int                 `json:"pendingnodes"`
+	History      []keyRotationRecord `json:"history"`
+}
+
+type KeyService interface {
+	watchKeyRotation(ctx context.Context)
+	doGetKeyRotation(w http.ResponseWriter, r *http.Request)
+	doRotateKey(w http.ResponseWriter, r *http.Request)
+	doGetKeyStatus(w http.ResponseWriter, r *http.Request)
+	doRetryFailedKeys(w http.ResponseWriter, r *http.Request)
+}
+
+// Implements KeyService
+type KeyManager struct {
+	k8Service    K8Service
+	tapmsService TapmsService
+}
+
+// Constructor injection for dependencies
+func NewKeyManager(k8s K8Service, tapms TapmsService) KeyService {
+	return &KeyManager{k8Service: k8s, tapmsService: tapms}
+}
+
+// Ask vault to rotate a console key to a new version
+func vaultRotateKey(vaultToken, keyName string) error {
+	URL := vaultBase + "/transit/keys/" + keyName + "/rotate"
+	vaultRequestHeaders := make(map[string]string)
+	vaultRequestHeaders["X-Vault-Token"] = vaultToken
+	response, responseCode, err := postURL(URL, nil, vaultRequestHeaders)
+	if err != nil {
+		return err
+	}
+	if responseCode != 200 && responseCode != 204 {
+		return fmt.Errorf("Unexpected response from Vault when rotating the key: %s  Http response code: %d",
+			response, responseCode)
+	}
+	log.Printf("The vault secret %s was rotated.", keyName)
+	return nil
+}
+
+// Get the creation time of the latest version of the console key
+func vaultGetKeyCreationTime(vaultToken string) (time.Time, error) {
+	URL := vaultBase + "/transit/keys/" + vaultBmcKeyName
+	vaultRequestHeaders := make(map[string]string)
+	vaultRequestHeaders["X-Vault-Token"] = vaultToken
+	response, responseCode, err := getURL(URL, vaultRequestHeaders)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if responseCode != 200 {
+		return time.Time{}, fmt.Errorf("Unexpected response from Vault when reading the key: %s  Http response code: %d",
+			response, responseCode)
+	}
+	keys := gjson.Get(string(response), "data.keys")
+	created := gjson.Get(keys.Raw, latestKeyVersion(keys)+".creation_time").String()
+	return time.Parse(time.RFC3339Nano, created)
+}
+
+// Get when the current key was created
+// NOTE: if vault has not been reached the time the key file was written is
+// the best guess
+func getKeyCreated() time.Time {
+	keyRotationMutex.Lock()
+	created := keyCreated
+	keyRotationMutex.Unlock()
+	if created.IsZero() {
+		if fi, err := os.Stat(mountainConsoleKey); err == nil {
+			created = fi.ModTime()
+		}
+	}
+	return created
+}
+
+// Age of a key in whole days
+func keyAgeDays(created, now time.Time) int {
+	if created.IsZero() {
+		return 0
+	}
+	return int(now.Sub(created) / (24 * time.Hour))
+}
+
+// Check if a key is older than the policy allows
+func keyTooOld(created, now time.Time) bool {
+	return keyMaxAgeDays > 0 && !created.IsZero() && keyAgeDays(created, now) >= keyMaxAgeDays
+}
+
+// Check if a key created at the given time is due for rotation
+func keyRotationDue(created, now time.Time) bool {
+	if keyRotationDays <= 0 || created.IsZero() {
+		return false
+	}
+	return now.Sub(created) >= time.Duration(keyRotationDays)*24*time.Hour
+}
+
+// Add a rotation record to the history, dropping the oldest if full
+func recordKeyRotation(rec keyRotationRecord) {
+	keyRotationMutex.Lock()
+	defer keyRotationMutex.Unlock()
+	keyRotationHistory = append(keyRotationHistory, rec)
+	if len(keyRotationHistory) > maxKeyRotationHistory {
+		keyRotationHistory = keyRotationHistory[len(keyRotationHistory)-maxKeyRotationHistory:]
+	}
+}
+
+// Loop to rotate the key when it is due and retry failed deployments
+func (km KeyManager) watchKeyRotation(ctx context.Context) {
+	for {
+		if !debugOnly && !reconcileStopped() {
+			km.checkKeyRotation()
+		}
+		if !sleepCtx(ctx, keyRotationCheckPeriod) {
+			log.Printf("Stopping key rotation checks")
+			return
+		}
+	}
+}
+
+// Retry failed deployments from the last rotation and rotate if due
+func (km KeyManager) checkKeyRotation() {
+	// move nodes to and from the keys of their tenants
+	if tenantKeys {
+		km.refreshTenantKeys()
+	}
+
+	// retry the nodes that did not get the rotated key
+	keyRotationMutex.Lock()
+	pending := keyRotationPending
+	keyRotationPending = make(map[string]nodeConsoleInfo)
+	keyRotationMutex.Unlock()
+	if len(pending) > 0 {
+		log.Printf("Retrying rotated key deployment for %d nodes", len(pending))
+		remaining := deployRotatedKey(pending)
+		keyRotationMutex.Lock()
+		for xname, node := range remaining {
+			keyRotationPending[xname] = node
+		}
+		keyRotationMutex.Unlock()
+	}
+
+	// pick up a rotation that was staged before a restart
+	resumeKeyRotation()
+
+	// move the consoles to the rotated key once it is on every bmc
+	km.checkKeyGrace(time.Now())
+
+	// find the age of the current key
+	vaultToken, err := vaultLogin()
+	if err != nil {
+		log.Printf("Unable to check the console key age: %s", err)
+		return
+	}
+	created, err := vaultGetKeyCreationTime(vaultToken)
+	if err != nil {
+		log.Printf("Unable to check the console key age: %s", err)
+		return
+	}
+	keyRotationMutex.Lock()
+	keyCreated = created
+	keyRotationMutex.Unlock()
+
+	if keyTooOld(created, time.Now()) {
+		log.Printf("WARNING: the console key is %d days old, policy age is %d days", keyAgeDays(created, time.Now()), keyMaxAgeDays)
+	}
+	if keyRotationDue(created, time.Now()) {
+		km.rotateMountainConsoleKey(fmt.Sprintf("Scheduled, key older than %d days", keyRotationDays))
+	}
+}
+
+// Rotate the key, redeploy it to all the mountain bmcs and switch the
+// console-node pods over to the new key once the bmcs have it
+// NOTE: when sharded only the nodes watched by this replica are in the
+// node cache, the other replicas deploy to theirs when their keys are
+// retried
+func (km KeyManager) rotateMountainConsoleKey(reason string) keyRotationRecord {
+	keyRotationMutex.Lock()
+	if keyRotationInProgress {
+		keyRotationMutex.Unlock()
+		return keyRotationRecord{Reason: reason, Error: "A key rotation is already in progress"}
+	}
+	if !keyGraceUntil.IsZero() {
+		numPending := len(keyRotationPending)
+		keyRotationMutex.Unlock()
+		return keyRotationRecord{Reason: reason,
+			Error: fmt.Sprintf("The last rotated key is still being deployed to %d nodes", numPending)}
+	}
+	keyRotationInProgress = true
+	keyRotationMutex.Unlock()
+	defer func() {
+		keyRotationMutex.Lock()
+		keyRotationInProgress = false
+		keyRotationMutex.Unlock()
+	}()
+
+	log.Printf("Rotating the mountain console key: %s", reason)
+	rec := keyRotationRecord{Started: formatTime(time.Now()), Reason: reason}
+	finish := func(err error) keyRotationRecord {
+		rec.Completed = formatTime(time.Now())
+		if err != nil {
+			rec.Error = err.Error()
+			logError(errKeyRotation, "Mountain console key rotation failed: %s", err)
+			recordEvent(eventOnOperator, corev1.EventTypeWarning, "KeyRotationFailed",
+				fmt.Sprintf("Mountain console key rotation failed: %s", err))
+		} else {
+			rec.Success = true
+			recordEvent(eventOnOperator, corev1.EventTypeNormal, "KeyRotated",
+				fmt.Sprintf("Mountain console key rotated and deployed to %d of %d nodes", rec.NumNodes-rec.NumFailed, rec.NumNodes))
+		}
+		recordKeyRotation(rec)
+		return rec
+	}
+
+	// get a new version of the key from vault
+	vaultToken, err := vaultLogin()
+	if err != nil {
+		return finish(err)
+	}
+	if err = vaultRotateKey(vaultToken, vaultBmcKeyName); err != nil {
+		return finish(err)
+	}
+	pvtKey, err := vaultGetPrivateKey(vaultToken, vaultBmcKeyName)
+	if err != nil {
+		return finish(err)
+	}
+	// stage the new key, the consoles keep using the old one for now
+	nextKey, nextKeyPub := nextKeyFiles(mountainConsoleKey)
+	if err = writeConsoleKeyFiles(pvtKey, nextKey, nextKeyPub); err != nil {
+		return finish(err)
+	}
+	if tenantKeys {
+		rotateTenantKeys(vaultToken)
+	}
+	if created, err := vaultGetKeyCreationTime(vaultToken); err == nil {
+		keyRotationMutex.Lock()
+		keyCreated = created
+		keyRotationMutex.Unlock()
+	}
+
+	// deploy the new public key to all the mountain bmcs
+	// NOTE - not thread safe, but should be ok
+	nodes := mountainNodes()
+	rec.NumNodes = len(nodes)
+	keyRotationMutex.Lock()
+	keyGraceUntil = time.Now().Add(time.Duration(keyRotationGraceHours) * time.Hour)
+	keyRotationMutex.Unlock()
+	remaining := deployRotatedKey(nodes)
+	rec.NumFailed = len(remaining)
+	keyRotationMutex.Lock()
+	for xname, node := range remaining {
+		keyRotationPending[xname] = node
+	}
+	keyRotationMutex.Unlock()
+
+	km.checkKeyGrace(time.Now())
+	return finish(nil)
+}
+
+// Mountain nodes in the node cache
+// NOTE - not thread safe, but should be ok
+func mountainNodes() map[string]nodeConsoleInfo {
+	nodes := make(map[string]nodeConsoleInfo)
+	for _, n := range nodeCache {
+		if n.isMountain() {
+			nodes[n.NodeName] = n
+		}
+	}
+	return nodes
+}
+
+// Check if a rotated key is staged and not yet used by the consoles
+func keyRotationStaged() bool {
+	return len(stagedKeyFiles()) > 0
+}
+
+// Public key file to deploy to a node during a rotation, the staged key if
+// the key of the node was rotated
+func rotatedPubKeyFile(node nodeConsoleInfo) string {
+	keyFile, pubKeyFile := keyFilesForNode(node)
+	_, nextKeyPub := nextKeyFiles(keyFile)
+	if _, err := os.Stat(nextKeyPub); err == nil {
+		return nextKeyPub
+	}
+	return pubKeyFile
+}
+
+// Deploy the rotated keys to a list of nodes and return the nodes that failed
+func deployRotatedKey(nodes map[string]nodeConsoleInfo) map[string]nodeConsoleInfo {
+	groups := make(map[string]map[string]nodeConsoleInfo)
+	for xname, node := range nodes {
+		pubKeyFile := rotatedPubKeyFile(node)
+		if groups[pubKeyFile] == nil {
+			groups[pubKeyFile] = make(map[string]nodeConsoleInfo)
+		}
+		groups[pubKeyFile][xname] = node
+	}
+	remaining := make(map[string]nodeConsoleInfo)
+	for pubKeyFile, group := range groups {
+		for xname, node := range doMountainCredsUpdateWithKey(group, pubKeyFile) {
+			remaining[xname] = node
+		}
+	}
+	return remaining
+}
+
+// Pick up a rotated key that was staged before the operator restarted
+// NOTE: which bmcs already had it is lost, so it is sent to all of them again
+func resumeKeyRotation() {
+	staged := stagedKeyFiles()
+	if len(staged) == 0 {
+		return
+	}
+	fi, err := os.Stat(staged[0])
+	if err != nil {
+		return
+	}
+	keyRotationMutex.Lock()
+	resume := keyGraceUntil.IsZero() && !keyRotationInProgress
+	keyRotationMutex.Unlock()
+	nodes := mountainNodes()
+	if !resume || len(nodes) == 0 {
+		return
+	}
+	log.Printf("Resuming the deployment of the rotated console key to %d nodes", len(nodes))
+	keyRotationMutex.Lock()
+	keyGraceUntil = fi.ModTime().Add(time.Duration(keyRotationGraceHours) * time.Hour)
+	for xname, node := range nodes {
+		keyRotationPending[xname] = node
+	}
+	keyRotationMutex.Unlock()
+}
+
+// Check if the consoles can be moved to the rotated key, either every bmc
+// has it or the grace window is over
+func keyGraceOver(now time.Time) bool {
+	keyRotationMutex.Lock()
+	defer keyRotationMutex.Unlock()
+	if keyGraceUntil.IsZero() {
+		return false
+	}
+	numPending := len(keyRotationPending)
+	if numPending > 0 && now.Before(keyGraceUntil) {
+		log.Printf("Waiting for the rotated key on %d bmcs before switching consoles, until %s",
+			numPending, formatTime(keyGraceUntil))
+		return false
+	}
+	if numPending > 0 {
+		log.Printf("Console key grace window is over, switching consoles with %d bmcs still without the key", numPending)
+	} else {
+		log.Printf("Rotated key deployed to all bmcs, switching consoles")
+	}
+	return true
+}
+
+// Replace the current key files with the staged rotated keys
+func switchRotatedKey() error {
+	for _, nextKeyPub := range stagedKeyFiles() {
+		keyFile := strings.TrimSuffix(nextKeyPub, ".next.pub")
+		nextKey, _ := nextKeyFiles(keyFile)
+		if pvtKey, err := ioutil.ReadFile(nextKey); err == nil {
+			if err = os.Rename(nextKey, keyFile); err != nil {
+				return err
+			}
+			if keyFile == mountainConsoleKey {
+				cacheMountainConsoleKey(string(pvtKey))
+			}
+		}
+		if err := os.Rename(nextKeyPub, keyFile+".pub"); err != nil {
+			return err
+		}
+	}
+	keyRotationMutex.Lock()
+	keyGraceUntil = time.Time{}
+	keyRotationMutex.Unlock()
+	return nil
+}
+
+// Move the mountain consoles to the rotated key once it is on every bmc, or
+// once the grace window is over
+func (km KeyManager) checkKeyGrace(now time.Time) {
+	if !keyGraceOver(now) {
+		return
+	}
+	if err := switchRotatedKey(); err != nil {
+		logError(errKeyRotation, "Unable to switch the consoles to the rotated key: %s", err)
+		return
+	}
+	km.reconnectMountainConsoles()
+}
+
+// Matches the command line of the ssh sessions using any of the console keys
+var consoleKeyPattern string = filepath.Join(filepath.Dir(mountainConsoleKey), "conman")
+
+// Drop the mountain console connections in the console-node pods so conman
+// reconnects them using the current key file
+func (km KeyManager) reconnectMountainConsoles() {
+	pods, err := km.k8Service.getConsoleNodePods()
+	if err != nil {
+		logError(errK8s, "Unable to get the console-node pods to reconnect consoles: %s", err)
+		return
+	}
+	for _, pod := range pods {
+		// NOTE: the mountain console connections are ssh sessions using the
+		//  default or a tenant key file, pkill fails if there are none which
+		//  is fine
+		if _, err := km.k8Service.execInPod(pod, consoleNodeContainer, []string{"pkill", "-f", consoleKeyPattern}); err != nil {
+			log.Printf("No mountain consoles reconnected in pod %s: %s", pod, err)
+		}
+	}
+}
+
+// Report the key rotation status and history
+func (KeyManager) doGetKeyRotation(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	keyRotationMutex.Lock()
+	defer keyRotationMutex.Unlock()
+	status := KeyRotationStatus{
+		Enabled:      keyRotationDays > 0,
+		PeriodDays:   keyRotationDays,
+		InProgress:   keyRotationInProgress,
+		PendingNodes: len(keyRotationPending),
+		History:      make([]keyRotationRecord, len(keyRotationHistory)),
+	}
+	copy(status.History, keyRotationHistory)
+	if !keyGraceUntil.IsZero() {
+		status.GraceUntil = formatTime(keyGraceUntil)
+	}
+	if !keyCreated.IsZero() {
+		status.KeyCreated = formatTime(keyCreated)
+		if keyRotationDays > 0 {
+			status.NextRotation = formatTime(keyCreated.Add(time.Duration(keyRotationDays) * 24 * time.Hour))
+		}
+	}
+	SendResponseJSON(w, http.StatusOK, status)
+}
+
+// Rotate the key now
+func (km KeyManager) doRotateKey(w http.ResponseWriter, r *http.Request) {
+	// only allow 'POST' calls
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+	if debugOnly {
+		sendJSONError(w, http.StatusConflict, "Key rotation is not available in debug mode")
+		return
+	}
+
+	rec := km.rotateMountainConsoleKey("Requested")
+	if !rec.Success {
+		sendJSONError(w, http.StatusInternalServerError, rec.Error)
+		return
+	}
+	SendResponseJSON(w, http.StatusOK, rec)
+}
@@ -0,0 +1,143 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestLatestKeyVersion(t *testing.T) {
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{`{"1":"a"}`, "1"},
+		{`{"1":"a","2":"b","10":"c"}`, "10"},
+		{`{}`, "1"},
+	}
+	for _, tc := range tests {
+		if got := latestKeyVersion(gjson.Parse(tc.keys)); got != tc.expected {
+			t.Errorf("%s: Expected: %s. Got: %s.", tc.keys, tc.expected, got)
+		}
+	}
+}
+
+func TestKeyRotationDue(t *testing.T) {
+	defer func() { keyRotationDays = 0 }()
+	now := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+
+	keyRotationDays = 0
+	if keyRotationDue(now.AddDate(-1, 0, 0), now) {
+		t.Errorf("Expected no rotation when disabled")
+	}
+
+	keyRotationDays = 90
+	tests := []struct {
+		created  time.Time
+		expected bool
+	}{
+		{now.AddDate(0, 0, -89), false},
+		{now.AddDate(0, 0, -90), true},
+		{time.Time{}, false},
+	}
+	for _, tc := range tests {
+		if got := keyRotationDue(tc.created, now); got != tc.expected {
+			t.Errorf("%s: Expected: %t. Got: %t.", tc.created, tc.expected, got)
+		}
+	}
+}
+
+func TestKeyTooOld(t *testing.T) {
+	defer func() { keyMaxAgeDays = 365 }()
+	now := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	keyMaxAgeDays = 30
+
+	if got := keyAgeDays(now.AddDate(0, 0, -45), now); got != 45 {
+		t.Errorf("Expected: 45. Got: %d.", got)
+	}
+	if keyTooOld(now.AddDate(0, 0, -29), now) {
+		t.Errorf("Expected a 29 day old key to be within policy")
+	}
+	if !keyTooOld(now.AddDate(0, 0, -30), now) {
+		t.Errorf("Expected a 30 day old key to be too old")
+	}
+
+	keyMaxAgeDays = 0
+	if keyTooOld(now.AddDate(-5, 0, 0), now) {
+		t.Errorf("Expected no warning when the policy is disabled")
+	}
+}
+
+// Mock to count the pods the mountain consoles are reconnected in
+type K8ReconnectMock struct {
+	K8Manager
+	execs *int
+}
+
+func (K8ReconnectMock) getConsoleNodePods() (podNames []string, err error) {
+	return []string{"cray-console-node-0", "cray-console-node-1"}, nil
+}
+
+func (m K8ReconnectMock) execInPod(podName, container string, cmd []string) (string, error) {
+	*m.execs++
+	return "", nil
+}
+
+func TestReconnectMountainConsoles(t *testing.T) {
+	execs := 0
+	km := KeyManager{k8Service: K8ReconnectMock{execs: &execs}}
+	km.reconnectMountainConsoles()
+	if execs != 2 {
+		t.Errorf("Expected the consoles reconnected in 2 pods. Got: %d.", execs)
+	}
+}
+
+func TestKeyGraceOver(t *testing.T) {
+	oldPending, oldGrace := keyRotationPending, keyGraceUntil
+	defer func() { keyRotationPending, keyGraceUntil = oldPending, oldGrace }()
+	now := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	waiting := map[string]nodeConsoleInfo{"x1000c0s0b0n0": {NodeName: "x1000c0s0b0n0"}}
+
+	tests := []struct {
+		name     string
+		until    time.Time
+		pending  map[string]nodeConsoleInfo
+		expected bool
+	}{
+		{"nothing rotated", time.Time{}, map[string]nodeConsoleInfo{}, false},
+		{"bmcs waiting inside the window", now.Add(time.Hour), waiting, false},
+		{"every bmc has the key", now.Add(time.Hour), map[string]nodeConsoleInfo{}, true},
+		{"window over with bmcs waiting", now.Add(-time.Minute), waiting, true},
+	}
+	for _, tc := range tests {
+		keyGraceUntil, keyRotationPending = tc.until, tc.pending
+		if got := keyGraceOver(now); got != tc.expected {
+			t.Errorf("%s: Expected: %t. Got: %t.", tc.name, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to track the result of the mountain console
+//  key deployment for each node so only the failed nodes need to be retried
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Key deployment states
+const keyDeploySucceeded string = "succeeded"
+const keyDeployFailed string = "failed"
+
+// Result of the last key deployment to a node
+type nodeKeyStatus struct {
+	Xname   string `json:"xname"`
+	Bmc     string `json:"bmc"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Updated string `json:"updated"`
+}
+
+// Last key deployment result by node xname
+var nodeKeyStatuses map[string]nodeKeyStatus = make(map[string]nodeKeyStatus)
+var nodeKeyStatusesMutex sync.Mutex
+
+// KeyStatusResponse - key deployment results for the mountain nodes
+type KeyStatusResponse struct {
+	NumSucceeded int             `json:"numsucceeded"`
+	NumFailed    int             `json:"numfailed"`
+	Nodes        []nodeKeyStatus `json:"nodes"`
+}
+
+// Record the result of a key deployment to a node
+func recordNodeKeyStatus(node nodeConsoleInfo, succeeded bool, reason string) {
+	ks := nodeKeyStatus{
+		Xname:   node.NodeName,
+		Bmc:     node.BmcName,
+		Status:  keyDeploySucceeded,
+		Updated: formatTime(time.Now()),
+	}
+	if !succeeded {
+		ks.Status = keyDeployFailed
+		ks.Reason = reason
+	}
+	nodeKeyStatusesMutex.Lock()
+	nodeKeyStatuses[node.NodeName] = ks
+	nodeKeyStatusesMutex.Unlock()
+}
+
+// Forget nodes that are no longer present
+func removeNodeKeyStatus(nodes []nodeConsoleInfo) {
+	nodeKeyStatusesMutex.Lock()
+	defer nodeKeyStatusesMutex.Unlock()
+	for _, n := range nodes {
+		delete(nodeKeyStatuses, n.NodeName)
+	}
+}
+
+// Get the key deployment results, optionally only with the given status
+func getNodeKeyStatuses(status string) KeyStatusResponse {
+	nodeKeyStatusesMutex.Lock()
+	defer nodeKeyStatusesMutex.Unlock()
+	resp := KeyStatusResponse{Nodes: []nodeKeyStatus{}}
+	for _, ks := range nodeKeyStatuses {
+		if ks.Status == keyDeploySucceeded {
+			resp.NumSucceeded++
+		} else {
+			resp.NumFailed++
+		}
+		if status == "" || ks.Status == status {
+			resp.Nodes = append(resp.Nodes, ks)
+		}
+	}
+	sort.Slice(resp.Nodes, func(i, j int) bool { return resp.Nodes[i].Xname < resp.Nodes[j].Xname })
+	return resp
+}
+
+// Report the key deployment result of each mountain node
+// NOTE: `?status=failed` only lists the nodes that failed
+func (KeyManager) doGetKeyStatus(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" && status != keyDeploySucceeded && status != keyDeployFailed {
+		sendJSONError(w, http.StatusBadRequest,
+			fmt.Sprintf("Invalid status %s, expected %s or %s", status, keyDeploySucceeded, keyDeployFailed))
+		return
+	}
+	SendResponseJSON(w, http.StatusOK, getNodeKeyStatuses(status))
+}
+
+// Redeploy the key to only the nodes where it failed
+func (KeyManager) doRetryFailedKeys(w http.ResponseWriter, r *http.Request) {
+	// only allow 'POST' calls
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	// NOTE - not thread safe, but should be ok
+	failed := make(map[string]nodeConsoleInfo)
+	for _, ks := range getNodeKeyStatuses(keyDeployFailed).Nodes {
+		if n, found := nodeCache[ks.Xname]; found {
+			failed[n.NodeName] = n
+		}
+	}
+	if len(failed) > 0 {
+		log.Printf("Retrying key deployment for %d failed nodes", len(failed))
+		doMountainCredsUpdate(failed)
+	}
+	SendResponseJSON(w, http.StatusOK, getNodeKeyStatuses(keyDeployFailed))
+}
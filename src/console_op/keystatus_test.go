@@ -0,0 +1,57 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"testing"
+)
+
+func TestNodeKeyStatuses(t *testing.T) {
+	defer func() { nodeKeyStatuses = make(map[string]nodeKeyStatus) }()
+	n0 := nodeConsoleInfo{NodeName: "x1000c0s0b0n0", BmcName: "x1000c0s0b0", Class: "Mountain"}
+	n1 := nodeConsoleInfo{NodeName: "x1000c0s1b0n0", BmcName: "x1000c0s1b0", Class: "Mountain"}
+	recordNodeKeyStatus(n0, true, "")
+	recordNodeKeyStatus(n1, false, "422 Target 'x1000c0s1b0' in bad HSM state: Unknown")
+
+	all := getNodeKeyStatuses("")
+	if all.NumSucceeded != 1 || all.NumFailed != 1 || len(all.Nodes) != 2 {
+		t.Errorf("Expected: 1, 1, 2. Got: %d, %d, %d.", all.NumSucceeded, all.NumFailed, len(all.Nodes))
+	}
+	failed := getNodeKeyStatuses(keyDeployFailed)
+	if len(failed.Nodes) != 1 || failed.Nodes[0].Xname != n1.NodeName {
+		t.Errorf("Expected only %s to have failed. Got: %v.", n1.NodeName, failed.Nodes)
+	}
+
+	// a later success replaces the failure
+	recordNodeKeyStatus(n1, true, "")
+	if got := getNodeKeyStatuses(keyDeployFailed); len(got.Nodes) != 0 {
+		t.Errorf("Expected: 0. Got: %d.", len(got.Nodes))
+	}
+
+	removeNodeKeyStatus([]nodeConsoleInfo{n0})
+	if got := getNodeKeyStatuses(""); len(got.Nodes) != 1 {
+		t.Errorf("Expected: 1. Got: %d.", len(got.Nodes))
+	}
+}
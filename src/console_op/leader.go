@@ -0,0 +1,164 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to run more than one operator replica where
+//  only the elected leader runs the background loops
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// Global vars to control leader election between operator replicas
+var leaderElection bool = false
+var leaderLeaseName string = "cray-console-operator"
+var leaderLeaseDurationSec int = 15
+var leaderRenewDeadlineSec int = 10
+var leaderRetryPeriodSec int = 2
+
+// A read-only replica only serves read requests and never reconciles, so
+// serving capacity can be added without adding writers
+var readOnlyMode bool = false
+
+// Set to 1 while this replica is the leader
+// NOTE: always the leader when leader election is not enabled
+var isLeader int32 = 0
+
+// Identity of the current leader if known
+var currentLeader atomic.Value
+
+// Check if this replica is the leader
+func amLeader() bool {
+	return atomic.LoadInt32(&isLeader) == 1
+}
+
+// Get the identity of the current leader
+func getCurrentLeader() string {
+	if v, ok := currentLeader.Load().(string); ok {
+		return v
+	}
+	return "Unknown"
+}
+
+// Take part in leader election until the context is cancelled, running the
+// given function with a context that is cancelled if leadership is lost
+func (k8s K8Manager) runLeaderElection(ctx context.Context, lead func(context.Context)) {
+	// keep campaigning after losing leadership until shut down
+	for {
+		k8s.runLeaseElection(ctx, leaderLeaseName,
+			func(lctx context.Context) {
+				atomic.StoreInt32(&isLeader, 1)
+				setShardOwned(0, true)
+				defer func() {
+					setShardOwned(0, false)
+					atomic.StoreInt32(&isLeader, 0)
+				}()
+				lead(lctx)
+			},
+			func(identity string) {
+				currentLeader.Store(identity)
+			})
+		if !sleepCtx(ctx, time.Duration(leaderRetryPeriodSec)*time.Second) {
+			return
+		}
+	}
+}
+
+// Campaign for the named lease, running the lead function with a context
+// that is cancelled if the lease is lost.  Returns when the lease is lost or
+// the context is cancelled.
+func (k8s K8Manager) runLeaseElection(ctx context.Context, leaseName string, lead func(context.Context), newLeader func(string)) {
+	// NOTE: the pod name is the host name of the container
+	id, err := os.Hostname()
+	if err != nil {
+		log.Panicf("ERROR: unable to get host name for leader election: %s", err)
+	}
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      leaseName,
+			Namespace: operatorNamespace,
+		},
+		Client:     k8s.clientset.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: id},
+	}
+
+	// NOTE: the lead function is started in the background by the election,
+	//  calling the once after the election returns either keeps it from
+	//  starting or waits for it to stop, so two sets of work are never
+	//  running at the same time
+	var leading sync.Once
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		ReleaseOnCancel: true,
+		LeaseDuration:   time.Duration(leaderLeaseDurationSec) * time.Second,
+		RenewDeadline:   time.Duration(leaderRenewDeadlineSec) * time.Second,
+		RetryPeriod:     time.Duration(leaderRetryPeriodSec) * time.Second,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(lctx context.Context) {
+				log.Printf("Acquired lease %s: %s", leaseName, id)
+				leading.Do(func() { lead(lctx) })
+			},
+			OnStoppedLeading: func() {
+				log.Printf("Lost lease %s: %s", leaseName, id)
+			},
+			OnNewLeader: func(identity string) {
+				log.Printf("Lease %s held by: %s", leaseName, identity)
+				if newLeader != nil {
+					newLeader(identity)
+				}
+			},
+		},
+	})
+	leading.Do(func() {})
+}
+
+// Only allow read requests on a standby or read-only replica
+func leaderOnlyWrites(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if readOnlyMode && r.Method != http.MethodGet && r.Method != http.MethodHead {
+			sendJSONErrorCode(w, http.StatusServiceUnavailable, errReadOnlyReplica,
+				"This replica is read-only, send changes to the leader")
+			return
+		}
+		if !amLeader() && r.Method != http.MethodGet && r.Method != http.MethodHead {
+			sendJSONErrorCode(w, http.StatusServiceUnavailable, errStandbyReplica,
+				fmt.Sprintf("This replica is a standby, send changes to the leader: %s", getCurrentLeader()))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,68 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLeaderOnlyWrites(t *testing.T) {
+	handler := leaderOnlyWrites(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer atomic.StoreInt32(&isLeader, 0)
+	defer func() { readOnlyMode = false }()
+
+	tests := []struct {
+		leader   bool
+		readOnly bool
+		method   string
+		expected int
+	}{
+		{true, false, http.MethodPost, http.StatusOK},
+		{false, false, http.MethodPost, http.StatusServiceUnavailable},
+		{false, false, http.MethodGet, http.StatusOK},
+		{false, true, http.MethodGet, http.StatusOK},
+		{false, true, http.MethodPut, http.StatusServiceUnavailable},
+		{true, true, http.MethodDelete, http.StatusServiceUnavailable},
+	}
+	for _, tc := range tests {
+		if tc.leader {
+			atomic.StoreInt32(&isLeader, 1)
+		} else {
+			atomic.StoreInt32(&isLeader, 0)
+		}
+		readOnlyMode = tc.readOnly
+		req := httptest.NewRequest(tc.method, "/console-operator/v1/freeze", nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != tc.expected {
+			t.Errorf("%s leader:%t readonly:%t Expected: %d. Got: %d.", tc.method, tc.leader, tc.readOnly, tc.expected, rr.Code)
+		}
+	}
+}
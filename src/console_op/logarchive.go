@@ -0,0 +1,254 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to archive the console logs of a node with a
+//  checksum manifest so the archived logs can be shown to be unmodified
+
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Name of the manifest in the archive, in the format `sha256sum -c` reads
+const logManifestName string = "SHA256SUMS"
+
+// LogManifestFile - the checksum of one console log file
+type LogManifestFile struct {
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	Modified string `json:"modified"`
+	Sha256   string `json:"sha256"`
+}
+
+// LogManifest - the checksums of all the console log files of a node
+type LogManifest struct {
+	Xname    string            `json:"xname"`
+	Created  string            `json:"created"`
+	Redacted bool              `json:"redacted"`
+	Files    []LogManifestFile `json:"files"`
+}
+
+// Get the console log files of a node, the current log last
+func nodeConsoleLogFiles(dir, xname string) ([]consoleLogFile, error) {
+	byNode, err := consoleLogFilesByNode(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := byNode[xname]
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].rotated != files[j].rotated {
+			return files[i].rotated
+		}
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	return files, nil
+}
+
+// Checksum a file with the redaction rules applied, returning what was read
+// so it matches the archived copy even if the file grows while it is read
+func checksumLogFile(path string, w io.Writer) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	cw := &countingWriter{w: io.MultiWriter(h, w)}
+	if strings.HasSuffix(path, ".gz") && len(redactionRules) > 0 {
+		// NOTE: compressed logs are redacted as text and compressed again
+		var zr *gzip.Reader
+		if zr, err = gzip.NewReader(f); err != nil {
+			return "", 0, err
+		}
+		zw := gzip.NewWriter(cw)
+		if _, err = redactCopy(zw, zr); err == nil {
+			err = zw.Close()
+		}
+	} else {
+		_, err = redactCopy(cw, f)
+	}
+	if err != nil {
+		return "", cw.n, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), cw.n, nil
+}
+
+// Writer that counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// Build the checksum manifest of the console log files of a node
+func buildLogManifest(dir, xname string, now time.Time) (LogManifest, error) {
+	man := LogManifest{Xname: xname, Created: formatTime(now), Redacted: len(redactionRules) > 0, Files: []LogManifestFile{}}
+	files, err := nodeConsoleLogFiles(dir, xname)
+	if err != nil {
+		return man, err
+	}
+	for _, f := range files {
+		sum, size, err := checksumLogFile(f.path, ioutil.Discard)
+		if err != nil {
+			return man, err
+		}
+		man.Files = append(man.Files, LogManifestFile{
+			Name:     filepath.Base(f.path),
+			Size:     size,
+			Modified: formatTime(f.modTime),
+			Sha256:   sum,
+		})
+	}
+	return man, nil
+}
+
+// Write the console log files of a node and their manifest to a tar.gz
+// NOTE: each file is read once into a temp file so the checksum and the
+// archived data are the same bytes
+func writeLogArchive(dir, xname string, now time.Time, out io.Writer) (LogManifest, error) {
+	man := LogManifest{Xname: xname, Created: formatTime(now), Redacted: len(redactionRules) > 0, Files: []LogManifestFile{}}
+	files, err := nodeConsoleLogFiles(dir, xname)
+	if err != nil {
+		return man, err
+	}
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		tmp, err := ioutil.TempFile("", "consolelog")
+		if err != nil {
+			return man, err
+		}
+		sum, size, err := checksumLogFile(f.path, tmp)
+		if err == nil {
+			_, err = tmp.Seek(0, io.SeekStart)
+		}
+		if err == nil {
+			name := filepath.Base(f.path)
+			err = tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: size, ModTime: f.modTime})
+			if err == nil {
+				_, err = io.CopyN(tw, tmp, size)
+			}
+			man.Files = append(man.Files, LogManifestFile{Name: name, Size: size, Modified: formatTime(f.modTime), Sha256: sum})
+		}
+		tmp.Close()
+		os.Remove(tmp.Name())
+		if err != nil {
+			return man, err
+		}
+	}
+	sums := logManifestSums(man)
+	if err = tw.WriteHeader(&tar.Header{Name: logManifestName, Mode: 0644, Size: int64(len(sums)), ModTime: now}); err != nil {
+		return man, err
+	}
+	if _, err = tw.Write([]byte(sums)); err != nil {
+		return man, err
+	}
+	if err = tw.Close(); err != nil {
+		return man, err
+	}
+	return man, gz.Close()
+}
+
+// Format the manifest the way sha256sum does
+func logManifestSums(man LogManifest) string {
+	var sb strings.Builder
+	for _, f := range man.Files {
+		fmt.Fprintf(&sb, "%s  %s\n", f.Sha256, f.Name)
+	}
+	return sb.String()
+}
+
+// Report the checksums of the console log files of a node
+func (LogManager) doGetLogManifest(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	xname := chi.URLParam(r, "xname")
+	man, err := buildLogManifest(consoleLogDir, xname, time.Now())
+	if err != nil {
+		sendJSONError(w, http.StatusInternalServerError,
+			fmt.Sprintf("Unable to checksum the console logs for %s: %s", xname, err))
+		return
+	}
+	if len(man.Files) == 0 {
+		sendJSONError(w, http.StatusNotFound,
+			fmt.Sprintf("No console logs for %s", xname))
+		return
+	}
+	SendResponseJSON(w, http.StatusOK, man)
+}
+
+// Send a tar.gz of the console log files of a node with a SHA256SUMS
+// manifest in it
+func (LogManager) doGetLogArchive(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	xname := chi.URLParam(r, "xname")
+	if files, err := nodeConsoleLogFiles(consoleLogDir, xname); err != nil || len(files) == 0 {
+		sendJSONError(w, http.StatusNotFound,
+			fmt.Sprintf("No console logs for %s", xname))
+		return
+	}
+	now := time.Now()
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=console.%s-%s.tar.gz", xname, now.UTC().Format("20060102T150405Z")))
+	w.WriteHeader(http.StatusOK)
+	// NOTE: the status has been sent by now so errors can only be logged
+	if _, err := writeLogArchive(consoleLogDir, xname, now, w); err != nil {
+		log.Printf("Unable to archive the console logs for %s: %s", xname, err)
+	}
+}
@@ -0,0 +1,96 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogArchiveManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logarchive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "console.x1000c0s0b0n0"), []byte("current\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "console.x1000c0s0b0n0-20260101"), []byte("rotated\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "console.x1000c0s0b0n1"), []byte("other\n"), 0644)
+
+	now := time.Now()
+	man, err := buildLogManifest(dir, "x1000c0s0b0n0", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(man.Files) != 2 || man.Files[0].Name != "console.x1000c0s0b0n0-20260101" {
+		t.Errorf("Expected the rotated file first of 2. Got: %v.", man.Files)
+	}
+	// sha256 of "current\n"
+	if man.Files[1].Sha256 != "48aa6cae8c70abdb28631d22b316e6d9f9d0768ec2911de7090e248b2afe6ca1" {
+		t.Errorf("Unexpected checksum: %s", man.Files[1].Sha256)
+	}
+
+	var buf bytes.Buffer
+	archived, err := writeLogArchive(dir, "x1000c0s0b0n0", now, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	var names []string
+	var sums string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+		if hdr.Name == logManifestName {
+			b, _ := ioutil.ReadAll(tr)
+			sums = string(b)
+		}
+	}
+	if len(names) != 3 || names[2] != logManifestName {
+		t.Errorf("Expected 2 logs and the manifest. Got: %v.", names)
+	}
+	for i, f := range man.Files {
+		if archived.Files[i].Sha256 != f.Sha256 || !strings.Contains(sums, f.Sha256+"  "+f.Name) {
+			t.Errorf("Expected %s %s in the manifest. Got: %s.", f.Sha256, f.Name, sums)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to compress the rotated console logs once they
+//  are old enough to keep the shared volume from filling up
+
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Compress rotated console logs older than this many days - 0 disables it
+var logCompressDays int = 0
+
+// How often to look for rotated logs to compress
+const logCompressCheckPeriod = time.Hour
+
+// LogCompressionRun - the result of a pass over the rotated logs
+type LogCompressionRun struct {
+	Started        string `json:"started"`
+	Completed      string `json:"completed"`
+	NumFiles       int    `json:"numfiles"`
+	ReclaimedBytes int64  `json:"reclaimedbytes"`
+	NumErrors      int    `json:"numerrors"`
+}
+
+// LogCompressionResponse - the compression settings and the last run
+type LogCompressionResponse struct {
+	Enabled             bool              `json:"enabled"`
+	Days                int               `json:"days"`
+	TotalReclaimedBytes int64             `json:"totalreclaimedbytes"`
+	LastRun             LogCompressionRun `json:"lastrun"`
+}
+
+// Results of the compression runs
+var lastLogCompression LogCompressionRun
+var totalLogReclaimedBytes int64 = 0
+var logCompressionMutex sync.Mutex
+
+// Gzip a file in place keeping its modification time, returning the bytes
+// saved
+func compressLogFile(path string, modTime time.Time) (int64, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return 0, err
+	}
+	// NOTE: written to a temp name first so a partial file is never taken
+	//  for a compressed log
+	tmpPath := path + ".gz.tmp"
+	out, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, fi.Mode())
+	if err != nil {
+		return 0, err
+	}
+	zw := gzip.NewWriter(out)
+	_, err = io.Copy(zw, in)
+	if err == nil {
+		err = zw.Close()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+	zfi, err := os.Stat(tmpPath)
+	if err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+	if err = os.Rename(tmpPath, path+".gz"); err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+	os.Chtimes(path+".gz", modTime, modTime)
+	if err = os.Remove(path); err != nil {
+		return 0, err
+	}
+	return fi.Size() - zfi.Size(), nil
+}
+
+// Compress the rotated logs older than the given age
+func compressRotatedLogs(dir string, age time.Duration, now time.Time) LogCompressionRun {
+	run := LogCompressionRun{Started: formatTime(now)}
+	byNode, err := consoleLogFilesByNode(dir)
+	if err != nil {
+		log.Printf("Unable to read the console log directory %s: %s", dir, err)
+		run.NumErrors++
+	}
+	for _, files := range byNode {
+		for _, f := range files {
+			if !f.rotated || strings.HasSuffix(f.path, ".gz") || strings.HasSuffix(f.path, ".tmp") ||
+				now.Sub(f.modTime) < age {
+				continue
+			}
+			saved, err := compressLogFile(f.path, f.modTime)
+			if err != nil {
+				log.Printf("Unable to compress console log %s: %s", f.path, err)
+				run.NumErrors++
+				continue
+			}
+			run.NumFiles++
+			run.ReclaimedBytes += saved
+		}
+	}
+	run.Completed = formatTime(time.Now())
+	return run
+}
+
+// Compress the old rotated logs and record the result
+func runLogCompression() LogCompressionRun {
+	run := compressRotatedLogs(consoleLogDir, time.Duration(logCompressDays)*24*time.Hour, time.Now())
+	if run.NumFiles > 0 || run.NumErrors > 0 {
+		log.Printf("Compressed %d rotated console logs reclaiming %d bytes with %d errors",
+			run.NumFiles, run.ReclaimedBytes, run.NumErrors)
+	}
+	logCompressionMutex.Lock()
+	lastLogCompression = run
+	totalLogReclaimedBytes += run.ReclaimedBytes
+	logCompressionMutex.Unlock()
+	return run
+}
+
+// Loop to compress the old rotated logs
+// NOTE: the console-node pods share the log volume with the operator so
+// the leader compresses the logs for all of them
+func (LogManager) watchLogCompression(ctx context.Context) {
+	if logCompressDays <= 0 {
+		return
+	}
+	for {
+		if !debugOnly && !reconcileStopped() {
+			runLogCompression()
+		}
+		if !sleepCtx(ctx, logCompressCheckPeriod) {
+			log.Printf("Stopping console log compression")
+			return
+		}
+	}
+}
+
+// Get the compression settings and the last run
+func getLogCompression() LogCompressionResponse {
+	logCompressionMutex.Lock()
+	defer logCompressionMutex.Unlock()
+	return LogCompressionResponse{
+		Enabled:             logCompressDays > 0,
+		Days:                logCompressDays,
+		TotalReclaimedBytes: totalLogReclaimedBytes,
+		LastRun:             lastLogCompression,
+	}
+}
+
+// Report the console log compression and the space it reclaimed
+func (LogManager) doGetLogCompression(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	SendResponseJSON(w, http.StatusOK, getLogCompression())
+}
+
+// Compress the old rotated logs now
+func (LogManager) doCompressLogs(w http.ResponseWriter, r *http.Request) {
+	// only allow 'POST' calls
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	if logCompressDays <= 0 {
+		sendJSONError(w, http.StatusBadRequest, "Console log compression is not enabled")
+		return
+	}
+	runLogCompression()
+	SendResponseJSON(w, http.StatusOK, getLogCompression())
+}
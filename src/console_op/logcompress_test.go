@@ -0,0 +1,79 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompressRotatedLogs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logcompress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	now := time.Now()
+	old := now.Add(-10 * 24 * time.Hour)
+	data := []byte(strings.Repeat("console output line\n", 1000))
+	for _, f := range []string{"console.x1000c0s0b0n0", "console.x1000c0s0b0n0-old", "console.x1000c0s0b0n0-new"} {
+		ioutil.WriteFile(filepath.Join(dir, f), data, 0644)
+	}
+	os.Chtimes(filepath.Join(dir, "console.x1000c0s0b0n0"), old, old)
+	os.Chtimes(filepath.Join(dir, "console.x1000c0s0b0n0-old"), old, old)
+
+	run := compressRotatedLogs(dir, 7*24*time.Hour, now)
+	if run.NumFiles != 1 || run.NumErrors != 0 || run.ReclaimedBytes <= 0 {
+		t.Errorf("Expected one file compressed. Got: %v.", run)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "console.x1000c0s0b0n0-old")); !os.IsNotExist(err) {
+		t.Errorf("Expected the uncompressed log to be removed.")
+	}
+	f, err := os.Open(filepath.Join(dir, "console.x1000c0s0b0n0-old.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := ioutil.ReadAll(zr); string(got) != string(data) {
+		t.Errorf("Expected the compressed log to match the original.")
+	}
+	if fi, _ := f.Stat(); !fi.ModTime().Equal(old) {
+		t.Errorf("Expected: %s. Got: %s.", old, fi.ModTime())
+	}
+
+	// nothing left to do
+	if run = compressRotatedLogs(dir, 7*24*time.Hour, now); run.NumFiles != 0 {
+		t.Errorf("Expected: 0. Got: %d.", run.NumFiles)
+	}
+}
@@ -0,0 +1,227 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to export a console log with every line in
+//  the CSM timestamped format so it lines up with other system telemetry
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Timestamp conman puts at the start of each line with the timestamp log
+// option, e.g. "2026-01-02 03:04:05 "
+var conmanLineTimeRegex = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}) ?`)
+
+// Periodic conman timestamp marker, e.g.
+// "<ConMan> Console [x3000c0s19b1n0] log at 2026-01-02 03:00:00 UTC."
+var conmanMarkerTimeRegex = regexp.MustCompile(`^<ConMan> Console \[[^\]]*\] log at (\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})`)
+
+const conmanTimeLayout string = "2006-01-02 15:04:05"
+
+// Read the lines of a console log along with the time of each line
+// NOTE: lines without their own time get the last time seen in the log,
+// lines before any time is seen get the first time in the log, or the
+// given time if the log has none
+func scanConsoleLog(in io.Reader, noTime time.Time, fn func(t time.Time, line string)) error {
+	var last time.Time
+	var pending []string = nil
+	scanner := bufio.NewScanner(in)
+	// NOTE: console output can have very long lines
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if m := conmanLineTimeRegex.FindStringSubmatch(line); m != nil {
+			if t, err := time.ParseInLocation(conmanTimeLayout, m[1], time.UTC); err == nil {
+				last = t
+				line = line[len(m[0]):]
+			}
+		} else if m := conmanMarkerTimeRegex.FindStringSubmatch(line); m != nil {
+			if t, err := time.ParseInLocation(conmanTimeLayout, m[1], time.UTC); err == nil {
+				last = t
+			}
+		}
+		if last.IsZero() {
+			pending = append(pending, line)
+			continue
+		}
+		for _, p := range pending {
+			fn(last, p)
+		}
+		pending = nil
+		fn(last, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	for _, p := range pending {
+		fn(noTime, p)
+	}
+	return nil
+}
+
+// Rewrite the lines of a console log as "<RFC3339 UTC> <xname> <text>"
+func exportCSMLog(xname string, in io.Reader, out io.Writer, noTime time.Time) error {
+	bw := bufio.NewWriter(out)
+	err := scanConsoleLog(in, noTime, func(t time.Time, line string) {
+		fmt.Fprintf(bw, "%s %s %s\n", formatTime(t), xname, redactLine(line))
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Send the current console log of a node in the CSM timestamped format
+func (LogManager) doExportLog(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	xname := chi.URLParam(r, "xname")
+	f, err := os.Open(consoleLogPath(xname))
+	if err != nil {
+		sendJSONError(w, http.StatusNotFound,
+			fmt.Sprintf("No console log for %s", xname))
+		return
+	}
+	defer f.Close()
+
+	// NOTE: the log is timestamped by conman in the time zone of the
+	//  console-node pods, which is UTC
+	noTime := time.Now()
+	if fi, err := f.Stat(); err == nil {
+		noTime = fi.ModTime()
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	if err := exportCSMLog(xname, f, w, noTime); err != nil {
+		log.Printf("Unable to export the console log for %s: %s", xname, err)
+	}
+}
+
+// Most lines sent by a tail
+const maxTailLines int = 10000
+
+// Header holding the offset to ask for the next output from
+const logOffsetHeader string = "X-Log-Offset"
+
+// Get the start of the last given number of lines in a chunk of output
+func lastLinesStart(data []byte, lines int) int {
+	end := len(data)
+	if end > 0 && data[end-1] == '\n' {
+		end--
+	}
+	for i := end - 1; i >= 0; i-- {
+		if data[i] == '\n' {
+			lines--
+			if lines == 0 {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+// Send the end of the console log of a node, `?lines=N` for the last N lines
+// or `?offset=M` for the output after an offset from the X-Log-Offset header
+// of an earlier call
+func (LogManager) doTailLog(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	lines := 20
+	var offset int64 = -1
+	var err error
+	if v := r.URL.Query().Get("lines"); v != "" {
+		if lines, err = strconv.Atoi(v); err != nil || lines < 1 || lines > maxTailLines {
+			sendJSONError(w, http.StatusBadRequest,
+				fmt.Sprintf("Invalid lines %s, expected 1 to %d", v, maxTailLines))
+			return
+		}
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		if offset, err = strconv.ParseInt(v, 10, 64); err != nil || offset < 0 {
+			sendJSONError(w, http.StatusBadRequest,
+				fmt.Sprintf("Invalid offset %s", v))
+			return
+		}
+	}
+
+	xname := chi.URLParam(r, "xname")
+	path := consoleLogPath(xname)
+	fi, err := os.Stat(path)
+	if err != nil {
+		sendJSONError(w, http.StatusNotFound,
+			fmt.Sprintf("No console log for %s", xname))
+		return
+	}
+	size := fi.Size()
+	from := offset
+	if offset < 0 {
+		// NOTE: assume lines of up to 1k when looking for the last lines
+		from = size - int64(lines)*1024
+		if from < 0 {
+			from = 0
+		}
+	} else if offset > size {
+		// rotated since the last call
+		from = 0
+	}
+	data, err := readLogRange(path, from, size)
+	if err != nil {
+		sendJSONError(w, http.StatusInternalServerError,
+			fmt.Sprintf("Unable to read the console log for %s: %s", xname, err))
+		return
+	}
+	if offset < 0 {
+		data = data[lastLinesStart(data, lines):]
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	w.Header().Set(logOffsetHeader, strconv.FormatInt(size, 10))
+	w.WriteHeader(http.StatusOK)
+	redactCopy(w, bytes.NewReader(data))
+}
@@ -0,0 +1,70 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportCSMLog(t *testing.T) {
+	in := strings.Join([]string{
+		"early output",
+		"<ConMan> Console [x1000c0s0b0n0] log at 2026-01-02 03:00:00 UTC.",
+		"booting",
+		"2026-01-02 03:04:05 login: ",
+		"after\r",
+	}, "\n") + "\n"
+	var out bytes.Buffer
+	if err := exportCSMLog("x1000c0s0b0n0", strings.NewReader(in), &out, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"2026-01-02T03:00:00Z x1000c0s0b0n0 early output",
+		"2026-01-02T03:00:00Z x1000c0s0b0n0 <ConMan> Console [x1000c0s0b0n0] log at 2026-01-02 03:00:00 UTC.",
+		"2026-01-02T03:00:00Z x1000c0s0b0n0 booting",
+		"2026-01-02T03:04:05Z x1000c0s0b0n0 login: ",
+		"2026-01-02T03:04:05Z x1000c0s0b0n0 after",
+	}
+	got := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(got) != len(expected) {
+		t.Fatalf("Expected: %d. Got: %d.", len(expected), len(got))
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Expected: %s. Got: %s.", expected[i], got[i])
+		}
+	}
+
+	// a log without any times uses the given time
+	out.Reset()
+	noTime := time.Date(2026, 5, 6, 7, 8, 9, 0, time.UTC)
+	exportCSMLog("x1000c0s0b0n0", strings.NewReader("text\n"), &out, noTime)
+	if out.String() != "2026-05-06T07:08:09Z x1000c0s0b0n0 text\n" {
+		t.Errorf("Unexpected export: %s", out.String())
+	}
+}
@@ -0,0 +1,221 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to keep the console logs of each node under
+//  a disk quota so one noisy node can not fill the shared volume
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Maximum MB of console logs, current and rotated, kept for a node - 0
+// disables the quota
+var logQuotaMB int = 0
+
+// Quota actions
+const logQuotaRemovedRotated string = "removedrotated"
+const logQuotaTruncated string = "truncated"
+
+// LogQuotaOffender - a node whose console logs went over the quota
+type LogQuotaOffender struct {
+	Xname       string `json:"xname"`
+	SizeBytes   int64  `json:"sizebytes"`
+	Action      string `json:"action"`
+	FreedBytes  int64  `json:"freedbytes"`
+	NumExceeded int    `json:"numexceeded"`
+	Last        string `json:"last"`
+}
+
+// LogQuotaResponse - the quota and the nodes that went over it
+type LogQuotaResponse struct {
+	QuotaBytes int64              `json:"quotabytes"`
+	Offenders  []LogQuotaOffender `json:"offenders"`
+}
+
+// Nodes that went over the quota by xname
+var logQuotaOffenders map[string]LogQuotaOffender = make(map[string]LogQuotaOffender)
+var logQuotaMutex sync.Mutex
+
+// A console log file of a node
+type consoleLogFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+	rotated bool
+}
+
+// Group the console log files in the directory by node
+func consoleLogFilesByNode(dir string) (map[string][]consoleLogFile, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	byNode := make(map[string][]consoleLogFile)
+	for _, fi := range files {
+		xname, rotated, ok := parseConsoleLogName(fi.Name())
+		if !ok || fi.IsDir() {
+			continue
+		}
+		byNode[xname] = append(byNode[xname], consoleLogFile{
+			path:    filepath.Join(dir, fi.Name()),
+			size:    fi.Size(),
+			modTime: fi.ModTime(),
+			rotated: rotated,
+		})
+	}
+	return byNode, nil
+}
+
+// Work out which files to remove and whether the current log needs to be
+// truncated to bring a node under the quota
+// NOTE: the oldest rotated files go first, the current log is only
+// truncated when it is over the quota by itself
+func planLogQuota(files []consoleLogFile, quota int64) (remove []consoleLogFile, truncate *consoleLogFile) {
+	var total int64 = 0
+	var rotated []consoleLogFile = nil
+	for i, f := range files {
+		total += f.size
+		if f.rotated {
+			rotated = append(rotated, f)
+		} else {
+			truncate = &files[i]
+		}
+	}
+	if total <= quota {
+		return nil, nil
+	}
+	sort.Slice(rotated, func(i, j int) bool { return rotated[i].modTime.Before(rotated[j].modTime) })
+	for _, f := range rotated {
+		if total <= quota {
+			break
+		}
+		remove = append(remove, f)
+		total -= f.size
+	}
+	if total <= quota {
+		truncate = nil
+	}
+	return remove, truncate
+}
+
+// Bring the console logs of every node under the quota
+func enforceLogQuota(dir string, quota int64) {
+	byNode, err := consoleLogFilesByNode(dir)
+	if err != nil {
+		log.Printf("Unable to read the console log directory %s: %s", dir, err)
+		return
+	}
+	for xname, files := range byNode {
+		remove, truncate := planLogQuota(files, quota)
+		if len(remove) == 0 && truncate == nil {
+			continue
+		}
+		var size int64 = 0
+		for _, f := range files {
+			size += f.size
+		}
+		var freed int64 = 0
+		action := logQuotaRemovedRotated
+		for _, f := range remove {
+			if err := os.Remove(f.path); err != nil {
+				log.Printf("Unable to remove console log %s: %s", f.path, err)
+				continue
+			}
+			freed += f.size
+		}
+		// NOTE: conman appends to the log so it keeps writing at the new end
+		//  of the file after it is truncated
+		if truncate != nil {
+			if err := os.Truncate(truncate.path, 0); err != nil {
+				log.Printf("Unable to truncate console log %s: %s", truncate.path, err)
+			} else {
+				freed += truncate.size
+				action = logQuotaTruncated
+			}
+		}
+		log.Printf("Console logs for %s over the quota at %d bytes, %s freed %d bytes", xname, size, action, freed)
+		recordLogQuotaOffender(xname, size, action, freed, time.Now())
+		recordEvent(eventOnOperator, corev1.EventTypeWarning, "LogQuotaExceeded",
+			fmt.Sprintf("Console logs for %s over the quota at %d bytes, %d bytes freed", xname, size, freed))
+	}
+}
+
+// Record a node going over the quota
+func recordLogQuotaOffender(xname string, size int64, action string, freed int64, now time.Time) {
+	logQuotaMutex.Lock()
+	defer logQuotaMutex.Unlock()
+	off := logQuotaOffenders[xname]
+	off.Xname = xname
+	off.SizeBytes = size
+	off.Action = action
+	off.FreedBytes = freed
+	off.NumExceeded++
+	off.Last = formatTime(now)
+	logQuotaOffenders[xname] = off
+}
+
+// Get the nodes that went over the quota, the most frequent first
+func getLogQuotaOffenders() []LogQuotaOffender {
+	logQuotaMutex.Lock()
+	defer logQuotaMutex.Unlock()
+	offenders := []LogQuotaOffender{}
+	for _, off := range logQuotaOffenders {
+		offenders = append(offenders, off)
+	}
+	sort.Slice(offenders, func(i, j int) bool {
+		if offenders[i].NumExceeded != offenders[j].NumExceeded {
+			return offenders[i].NumExceeded > offenders[j].NumExceeded
+		}
+		return offenders[i].Xname < offenders[j].Xname
+	})
+	return offenders
+}
+
+// Report the console log quota and the nodes that went over it
+func (LogManager) doGetLogQuota(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	SendResponseJSON(w, http.StatusOK, LogQuotaResponse{
+		QuotaBytes: int64(logQuotaMB) * 1024 * 1024,
+		Offenders:  getLogQuotaOffenders(),
+	})
+}
@@ -0,0 +1,74 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPlanLogQuota(t *testing.T) {
+	now := time.Now()
+	cur := consoleLogFile{path: "console.x1", size: 40}
+	old := consoleLogFile{path: "console.x1-1", size: 50, modTime: now.Add(-2 * time.Hour), rotated: true}
+	newer := consoleLogFile{path: "console.x1-2", size: 30, modTime: now.Add(-time.Hour), rotated: true}
+
+	// under the quota
+	if rm, tr := planLogQuota([]consoleLogFile{cur, old, newer}, 200); len(rm) != 0 || tr != nil {
+		t.Errorf("Expected nothing to do. Got: %v %v.", rm, tr)
+	}
+	// removing the oldest rotated file is enough
+	if rm, tr := planLogQuota([]consoleLogFile{cur, newer, old}, 100); len(rm) != 1 || rm[0].path != old.path || tr != nil {
+		t.Errorf("Expected only %s to be removed. Got: %v %v.", old.path, rm, tr)
+	}
+	// the current log is over by itself
+	if rm, tr := planLogQuota([]consoleLogFile{cur, old, newer}, 30); len(rm) != 2 || tr == nil || tr.path != cur.path {
+		t.Errorf("Expected all rotated removed and %s truncated. Got: %v %v.", cur.path, rm, tr)
+	}
+}
+
+func TestEnforceLogQuota(t *testing.T) {
+	defer func() { logQuotaOffenders = make(map[string]LogQuotaOffender) }()
+	dir, err := ioutil.TempDir("", "logquota")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	big := make([]byte, 1000)
+	ioutil.WriteFile(filepath.Join(dir, "console.x1000c0s0b0n0"), big, 0644)
+	ioutil.WriteFile(filepath.Join(dir, "console.x1000c0s0b0n1"), big[:10], 0644)
+
+	enforceLogQuota(dir, 100)
+	if fi, err := os.Stat(filepath.Join(dir, "console.x1000c0s0b0n0")); err != nil || fi.Size() != 0 {
+		t.Errorf("Expected the noisy log to be truncated. Got: %v %v.", fi, err)
+	}
+	offenders := getLogQuotaOffenders()
+	if len(offenders) != 1 || offenders[0].Xname != "x1000c0s0b0n0" || offenders[0].Action != logQuotaTruncated {
+		t.Errorf("Expected x1000c0s0b0n0 truncated. Got: %v.", offenders)
+	}
+}
@@ -0,0 +1,133 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to find nodes by something other than their
+//  xname - operators often only have a NID from the workload manager
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Matches a nid alias like 'nid001234'
+var nidAliasRegex = regexp.MustCompile(`^nid(\d+)$`)
+
+// Parse a nid alias into the NID - false if it is not a nid alias
+func parseNidAlias(name string) (int, bool) {
+	m := nidAliasRegex.FindStringSubmatch(strings.ToLower(strings.TrimSpace(name)))
+	if m == nil {
+		return 0, false
+	}
+	nid, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, false
+	}
+	return nid, true
+}
+
+// Get the members of an hsm group
+// NOTE: with more than one hsm source the group may be defined in any of
+// them, the members of every source that has it are combined
+func getHSMGroupMembers(label, reqID string) (map[string]struct{}, error) {
+	type response struct {
+		Ids []string `json:"ids"`
+	}
+	var members map[string]struct{} = nil
+	for _, src := range hsmSources {
+		URL := fmt.Sprintf("%s/groups/%s/members", src.URL, url.PathEscape(label))
+		data, sc, err := getURL(URL, requestIDHeaders(reqID))
+		if err != nil {
+			logError(errHSM, "Unable to get members of group %s from hsm %s: %s", label, src.URL, err)
+			continue
+		}
+		if sc != http.StatusOK {
+			continue
+		}
+		var rp response
+		if err = json.Unmarshal(data, &rp); err != nil {
+			logError(errHSM, "Error unmarshalling group members: %s", err)
+			continue
+		}
+		if members == nil {
+			members = make(map[string]struct{})
+		}
+		for _, id := range rp.Ids {
+			members[strings.ToLower(id)] = struct{}{}
+		}
+	}
+	if members == nil {
+		return nil, fmt.Errorf("Group %s not found in hsm", label)
+	}
+	return members, nil
+}
+
+// Find the nodes matching a lookup, all the given fields must match
+// NOTE: the xname field may also hold a nid alias and the group members are
+// only used when the query has a group
+func findNodes(query GetNodeData, nodes map[string]nodeConsoleInfo, groupMembers map[string]struct{}) ([]nodeConsoleInfo, error) {
+	xname := strings.TrimSpace(query.XName)
+	nid := query.NID
+	if n, ok := parseNidAlias(xname); ok {
+		if nid != 0 && nid != n {
+			return nil, fmt.Errorf("Conflicting nid %d and alias %s", nid, xname)
+		}
+		nid = n
+		xname = ""
+	}
+	if xname == "" && nid == 0 && query.Role == "" && query.BmcFqdn == "" && query.Group == "" {
+		return nil, fmt.Errorf("Expected at least one of xname, nid, role, bmcfqdn or group")
+	}
+
+	var found []nodeConsoleInfo = nil
+	for _, n := range nodes {
+		if xname != "" && !strings.EqualFold(n.NodeName, xname) {
+			continue
+		}
+		if nid != 0 && n.NID != nid {
+			continue
+		}
+		if query.Role != "" && !strings.EqualFold(n.Role, query.Role) {
+			continue
+		}
+		if query.BmcFqdn != "" && !strings.EqualFold(n.BmcFqdn, query.BmcFqdn) {
+			continue
+		}
+		if query.Group != "" {
+			if _, found := groupMembers[strings.ToLower(n.NodeName)]; !found {
+				continue
+			}
+		}
+		found = append(found, n)
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].NodeName < found[j].NodeName })
+	return found, nil
+}
@@ -0,0 +1,99 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"testing"
+)
+
+func TestParseNidAlias(t *testing.T) {
+	tests := []struct {
+		name     string
+		nid      int
+		expected bool
+	}{
+		{"nid001234", 1234, true},
+		{"NID000001", 1, true},
+		{"nid", 0, false},
+		{"x3000c0s1b0n0", 0, false},
+		{"nid12a", 0, false},
+	}
+	for _, tc := range tests {
+		nid, ok := parseNidAlias(tc.name)
+		if ok != tc.expected || nid != tc.nid {
+			t.Errorf("%s: Expected: %d, %t. Got: %d, %t.", tc.name, tc.nid, tc.expected, nid, ok)
+		}
+	}
+}
+
+func TestFindNodes(t *testing.T) {
+	nodes := map[string]nodeConsoleInfo{
+		"x3000c0s1b0n0": {NodeName: "x3000c0s1b0n0", BmcFqdn: "x3000c0s1b0.hmn", NID: 1, Role: "Compute"},
+		"x3000c0s1b0n1": {NodeName: "x3000c0s1b0n1", BmcFqdn: "x3000c0s1b0.hmn", NID: 2, Role: "Compute"},
+		"x3000c0s3b0n0": {NodeName: "x3000c0s3b0n0", BmcFqdn: "x3000c0s3b0.hmn", NID: 100001, Role: "Application"},
+	}
+	groupMembers := map[string]struct{}{"x3000c0s1b0n1": {}, "x3000c0s3b0n0": {}}
+	tests := []struct {
+		query    GetNodeData
+		expected int
+	}{
+		{GetNodeData{Group: "slurm-compute"}, 2},
+		{GetNodeData{Group: "slurm-compute", Role: "Compute"}, 1},
+		{GetNodeData{XName: "nid000002"}, 1},
+		{GetNodeData{NID: 100001}, 1},
+		{GetNodeData{Role: "compute"}, 2},
+		{GetNodeData{BmcFqdn: "x3000c0s1b0.hmn"}, 2},
+		{GetNodeData{BmcFqdn: "x3000c0s1b0.hmn", NID: 2}, 1},
+		{GetNodeData{Role: "Storage"}, 0},
+	}
+	for _, tc := range tests {
+		found, err := findNodes(tc.query, nodes, groupMembers)
+		if err != nil {
+			t.Errorf("%+v: Unexpected error: %s", tc.query, err)
+		}
+		if len(found) != tc.expected {
+			t.Errorf("%+v: Expected: %d. Got: %d.", tc.query, tc.expected, len(found))
+		}
+	}
+
+	if _, err := findNodes(GetNodeData{}, nodes, nil); err == nil {
+		t.Errorf("Expected an error for an empty query")
+	}
+	if _, err := findNodes(GetNodeData{XName: "nid000001", NID: 2}, nodes, nil); err == nil {
+		t.Errorf("Expected an error for a conflicting nid")
+	}
+}
+
+func TestFilterInventory(t *testing.T) {
+	inv := []dataNodeInfo{
+		{NodeName: "x3000c0s1b0n0"},
+		{NodeName: "x3000c0s1b0n1"},
+		{NodeName: "x1000c0s0b0n0"},
+	}
+	members := map[string]struct{}{"x3000c0s1b0n1": {}, "x1000c0s0b0n0": {}}
+	if got := filterInventory(inv, members); len(got) != 2 {
+		t.Errorf("Expected: 2. Got: %d.", len(got))
+	}
+}
@@ -0,0 +1,350 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to take the consoles of a set of nodes out of
+//  monitoring during a scheduled maintenance window and put them back after
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// How often to check the node maintenance windows
+const nodeMaintenanceCheckPeriod = time.Minute
+
+// A maintenance window for a set of nodes
+type nodeMaintenance struct {
+	ID     string
+	Name   string
+	Xnames map[string]struct{} // lower case
+	Group  string
+	Window maintenanceWindow
+}
+
+// NodeMaintenanceRequest - input data to schedule a node maintenance window
+// NOTE: the window is either an RFC3339 start and end separated by '/' or a
+// daily 'HH:MM-HH:MM' in UTC, the same as MAINTENANCE_WINDOWS
+type NodeMaintenanceRequest struct {
+	Name   string   `json:"name"`
+	Xnames []string `json:"xnames"`
+	Group  string   `json:"group"`
+	Window string   `json:"window"`
+}
+
+// NodeMaintenanceWindow - a scheduled node maintenance window
+type NodeMaintenanceWindow struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Xnames []string `json:"xnames"`
+	Group  string   `json:"group,omitempty"`
+	Window string   `json:"window"`
+	Active bool     `json:"active"`
+}
+
+// NodeMaintenanceResponse - the scheduled windows and the consoles they
+// have taken out of monitoring
+type NodeMaintenanceResponse struct {
+	Windows  []NodeMaintenanceWindow `json:"windows"`
+	Disabled []string                `json:"disabled"`
+}
+
+// The scheduled windows by id and the consoles taken out of monitoring
+var nodeMaintenanceWindows map[string]nodeMaintenance = make(map[string]nodeMaintenance)
+var nodeMaintenanceDisabled map[string]nodeConsoleInfo = make(map[string]nodeConsoleInfo)
+var nodeMaintenanceNextID int = 1
+var nodeMaintenanceMutex sync.Mutex
+
+// Check if the console of a node has been taken out of monitoring
+func inNodeMaintenance(xname string) bool {
+	nodeMaintenanceMutex.Lock()
+	defer nodeMaintenanceMutex.Unlock()
+	_, found := nodeMaintenanceDisabled[xname]
+	return found
+}
+
+// Drop the nodes whose consoles are out of monitoring
+func withoutMaintenanceNodes(nodes []nodeConsoleInfo) []nodeConsoleInfo {
+	var kept []nodeConsoleInfo = nil
+	for _, n := range nodes {
+		if !inNodeMaintenance(n.NodeName) {
+			kept = append(kept, n)
+		}
+	}
+	return kept
+}
+
+// Add a node maintenance window, returning its id
+func addNodeMaintenance(req NodeMaintenanceRequest) (string, error) {
+	if len(req.Xnames) == 0 && req.Group == "" {
+		return "", fmt.Errorf("Either xnames or group is required")
+	}
+	windows, err := parseMaintenanceWindows(req.Window)
+	if err != nil {
+		return "", err
+	}
+	if len(windows) != 1 {
+		return "", fmt.Errorf("Exactly one window is required")
+	}
+	nm := nodeMaintenance{Name: req.Name, Xnames: make(map[string]struct{}), Group: req.Group, Window: windows[0]}
+	for _, xname := range req.Xnames {
+		nm.Xnames[strings.ToLower(strings.TrimSpace(xname))] = struct{}{}
+	}
+
+	nodeMaintenanceMutex.Lock()
+	defer nodeMaintenanceMutex.Unlock()
+	nm.ID = strconv.Itoa(nodeMaintenanceNextID)
+	nodeMaintenanceNextID++
+	nodeMaintenanceWindows[nm.ID] = nm
+	return nm.ID, nil
+}
+
+// Remove a node maintenance window
+// NOTE: its consoles are put back on the next check
+func removeNodeMaintenance(id string) bool {
+	nodeMaintenanceMutex.Lock()
+	defer nodeMaintenanceMutex.Unlock()
+	if _, found := nodeMaintenanceWindows[id]; !found {
+		return false
+	}
+	delete(nodeMaintenanceWindows, id)
+	return true
+}
+
+// Get the nodes that should be out of monitoring now, dropping the one time
+// windows that are over
+func activeMaintenanceNodes(now time.Time) map[string]struct{} {
+	nodeMaintenanceMutex.Lock()
+	var active []nodeMaintenance = nil
+	for id, nm := range nodeMaintenanceWindows {
+		if !nm.Window.Daily && !now.Before(nm.Window.End) {
+			log.Printf("Node maintenance window %s (%s) is over", id, nm.Name)
+			delete(nodeMaintenanceWindows, id)
+			continue
+		}
+		if nm.Window.contains(now) {
+			active = append(active, nm)
+		}
+	}
+	nodeMaintenanceMutex.Unlock()
+
+	// NOTE: the group members are looked up while the window is active so
+	//  changes to the group are picked up
+	xnames := make(map[string]struct{})
+	for _, nm := range active {
+		for xname := range nm.Xnames {
+			xnames[xname] = struct{}{}
+		}
+		if nm.Group != "" {
+			members, err := getHSMGroupMembers(nm.Group, "")
+			if err != nil {
+				logError(errHSM, "Unable to get the members of maintenance group %s: %s", nm.Group, err)
+				continue
+			}
+			for xname := range members {
+				xnames[xname] = struct{}{}
+			}
+		}
+	}
+	return xnames
+}
+
+// Work out which consoles to take out of monitoring and which to put back
+func updateNodeMaintenance(want map[string]struct{}, nodes map[string]nodeConsoleInfo) (disable, enable []nodeConsoleInfo) {
+	nodeMaintenanceMutex.Lock()
+	defer nodeMaintenanceMutex.Unlock()
+	for xname, n := range nodes {
+		_, inWindow := want[strings.ToLower(xname)]
+		_, disabled := nodeMaintenanceDisabled[xname]
+		if inWindow && !disabled {
+			disable = append(disable, n)
+			nodeMaintenanceDisabled[xname] = n
+		} else if !inWindow && disabled {
+			enable = append(enable, n)
+		}
+	}
+	// nodes that are gone from hsm are just forgotten
+	for xname := range nodeMaintenanceDisabled {
+		if _, found := nodes[xname]; !found {
+			delete(nodeMaintenanceDisabled, xname)
+		}
+	}
+	return disable, enable
+}
+
+// Mark consoles as back in monitoring once console-data has them again
+func nodeMaintenanceEnabled(nodes []nodeConsoleInfo) {
+	nodeMaintenanceMutex.Lock()
+	defer nodeMaintenanceMutex.Unlock()
+	for _, n := range nodes {
+		delete(nodeMaintenanceDisabled, n.NodeName)
+	}
+}
+
+// Main loop to take consoles in and out of monitoring for the windows
+func watchNodeMaintenance(ctx context.Context, ds DataService) {
+	for {
+		if !debugOnly && !reconcileStopped() {
+			checkNodeMaintenance(ds, time.Now())
+		}
+		if !sleepCtx(ctx, nodeMaintenanceCheckPeriod) {
+			log.Printf("Stopping node maintenance checks")
+			return
+		}
+	}
+}
+
+// Take the consoles of the nodes in an active window out of console-data
+// and add back the ones whose window is over
+func checkNodeMaintenance(ds DataService, now time.Time) {
+	want := activeMaintenanceNodes(now)
+	// NOTE - not thread safe, but should be ok
+	nodes := make(map[string]nodeConsoleInfo, len(nodeCache))
+	for xname, n := range nodeCache {
+		nodes[xname] = n
+	}
+	disable, enable := updateNodeMaintenance(want, nodes)
+	if len(disable) > 0 {
+		log.Printf("Taking %d consoles out of monitoring for maintenance", len(disable))
+		ds.dataRemoveNodes(disable)
+		recordEvent(eventOnOperator, corev1.EventTypeNormal, "NodeMaintenanceStarted",
+			fmt.Sprintf("Took %d consoles out of monitoring for a maintenance window", len(disable)))
+	}
+	if len(enable) > 0 {
+		// NOTE: if this fails the consoles stay out and are retried next time
+		if !ds.dataAddNodes(enable) {
+			log.Printf("Unable to put %d consoles back after maintenance, will retry", len(enable))
+			return
+		}
+		nodeMaintenanceEnabled(enable)
+		log.Printf("Put %d consoles back in monitoring after maintenance", len(enable))
+		recordEvent(eventOnOperator, corev1.EventTypeNormal, "NodeMaintenanceEnded",
+			fmt.Sprintf("Put %d consoles back in monitoring after a maintenance window", len(enable)))
+	}
+}
+
+// Get the scheduled windows and the consoles out of monitoring
+func getNodeMaintenance(now time.Time) NodeMaintenanceResponse {
+	nodeMaintenanceMutex.Lock()
+	defer nodeMaintenanceMutex.Unlock()
+	resp := NodeMaintenanceResponse{Windows: []NodeMaintenanceWindow{}, Disabled: []string{}}
+	for _, nm := range nodeMaintenanceWindows {
+		w := NodeMaintenanceWindow{ID: nm.ID, Name: nm.Name, Xnames: []string{}, Group: nm.Group,
+			Window: nm.Window.String(), Active: nm.Window.contains(now)}
+		for xname := range nm.Xnames {
+			w.Xnames = append(w.Xnames, xname)
+		}
+		sort.Strings(w.Xnames)
+		resp.Windows = append(resp.Windows, w)
+	}
+	sort.Slice(resp.Windows, func(i, j int) bool {
+		a, _ := strconv.Atoi(resp.Windows[i].ID)
+		b, _ := strconv.Atoi(resp.Windows[j].ID)
+		return a < b
+	})
+	for xname := range nodeMaintenanceDisabled {
+		resp.Disabled = append(resp.Disabled, xname)
+	}
+	sort.Strings(resp.Disabled)
+	return resp
+}
+
+// Report the node maintenance windows
+func (FreezeManager) doGetNodeMaintenance(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	SendResponseJSON(w, http.StatusOK, getNodeMaintenance(time.Now()))
+}
+
+// Schedule a node maintenance window
+func (FreezeManager) doAddNodeMaintenance(w http.ResponseWriter, r *http.Request) {
+	// only allow 'POST' calls
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	var req NodeMaintenanceRequest
+	reqBody, err := ioutil.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		sendJSONError(w, http.StatusBadRequest,
+			fmt.Sprintf("There was an error reading the request body: %s", err))
+		return
+	}
+	if err = json.Unmarshal(reqBody, &req); err != nil {
+		sendJSONError(w, http.StatusBadRequest,
+			fmt.Sprintf("There was an error decoding the request body: %s", err))
+		return
+	}
+	id, err := addNodeMaintenance(req)
+	if err != nil {
+		sendJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	log.Printf("Node maintenance window %s (%s) scheduled: %s", id, req.Name, req.Window)
+	SendResponseJSON(w, http.StatusOK, map[string]string{"id": id})
+}
+
+// Remove a node maintenance window
+func (FreezeManager) doDeleteNodeMaintenance(w http.ResponseWriter, r *http.Request) {
+	// only allow 'DELETE' calls
+	if r.Method != http.MethodDelete {
+		w.Header().Set("Allow", "DELETE")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if !removeNodeMaintenance(id) {
+		sendJSONError(w, http.StatusNotFound, fmt.Sprintf("No maintenance window %s", id))
+		return
+	}
+	log.Printf("Node maintenance window %s removed", id)
+
+	// write the response
+	w.WriteHeader(http.StatusOK)
+}
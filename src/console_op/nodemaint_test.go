@@ -0,0 +1,103 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func resetNodeMaintenance() {
+	nodeMaintenanceWindows = make(map[string]nodeMaintenance)
+	nodeMaintenanceDisabled = make(map[string]nodeConsoleInfo)
+	nodeMaintenanceNextID = 1
+}
+
+func TestAddNodeMaintenance(t *testing.T) {
+	defer resetNodeMaintenance()
+	tests := []struct {
+		req NodeMaintenanceRequest
+		ok  bool
+	}{
+		{NodeMaintenanceRequest{Xnames: []string{"x3000c0s19b0n0"}, Window: "02:00-04:00"}, true},
+		{NodeMaintenanceRequest{Group: "blades", Window: "2026-03-01T02:00:00Z/2026-03-01T04:00:00Z"}, true},
+		{NodeMaintenanceRequest{Window: "02:00-04:00"}, false},
+		{NodeMaintenanceRequest{Xnames: []string{"x3000c0s19b0n0"}, Window: ""}, false},
+		{NodeMaintenanceRequest{Xnames: []string{"x3000c0s19b0n0"}, Window: "02:00-04:00,05:00-06:00"}, false},
+	}
+	for i, tc := range tests {
+		if _, err := addNodeMaintenance(tc.req); (err == nil) != tc.ok {
+			t.Errorf("Test %d Expected ok: %t. Got: %v.", i, tc.ok, err)
+		}
+	}
+	if resp := getNodeMaintenance(time.Now()); len(resp.Windows) != 2 || resp.Windows[0].ID != "1" {
+		t.Errorf("Unexpected windows: %v", resp.Windows)
+	}
+	if !removeNodeMaintenance("1") || removeNodeMaintenance("1") {
+		t.Errorf("Expected window 1 to be removed once")
+	}
+}
+
+func TestNodeMaintenanceWindow(t *testing.T) {
+	defer resetNodeMaintenance()
+	nodes := map[string]nodeConsoleInfo{
+		"x3000c0s19b0n0": {NodeName: "x3000c0s19b0n0"},
+		"x3000c0s17b0n0": {NodeName: "x3000c0s17b0n0"},
+	}
+	addNodeMaintenance(NodeMaintenanceRequest{Xnames: []string{"X3000c0s19b0n0"},
+		Window: "2026-03-01T02:00:00Z/2026-03-01T04:00:00Z"})
+
+	// before the window nothing changes
+	before := time.Date(2026, 3, 1, 1, 0, 0, 0, time.UTC)
+	disable, enable := updateNodeMaintenance(activeMaintenanceNodes(before), nodes)
+	if len(disable) != 0 || len(enable) != 0 {
+		t.Errorf("Unexpected disable: %v enable: %v", disable, enable)
+	}
+
+	// during the window the console is taken out
+	during := time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC)
+	disable, enable = updateNodeMaintenance(activeMaintenanceNodes(during), nodes)
+	if len(disable) != 1 || disable[0].NodeName != "x3000c0s19b0n0" || len(enable) != 0 {
+		t.Errorf("Unexpected disable: %v enable: %v", disable, enable)
+	}
+	if !inNodeMaintenance("x3000c0s19b0n0") {
+		t.Errorf("Expected x3000c0s19b0n0 to be in maintenance")
+	}
+	kept := withoutMaintenanceNodes([]nodeConsoleInfo{nodes["x3000c0s19b0n0"], nodes["x3000c0s17b0n0"]})
+	if len(kept) != 1 || kept[0].NodeName != "x3000c0s17b0n0" {
+		t.Errorf("Unexpected nodes kept: %v", kept)
+	}
+
+	// after the window the console is put back and the window is dropped
+	after := time.Date(2026, 3, 1, 5, 0, 0, 0, time.UTC)
+	disable, enable = updateNodeMaintenance(activeMaintenanceNodes(after), nodes)
+	if len(disable) != 0 || len(enable) != 1 || enable[0].NodeName != "x3000c0s19b0n0" {
+		t.Errorf("Unexpected disable: %v enable: %v", disable, enable)
+	}
+	nodeMaintenanceEnabled(enable)
+	if inNodeMaintenance("x3000c0s19b0n0") || len(getNodeMaintenance(after).Windows) != 0 {
+		t.Errorf("Expected no maintenance, got: %v", getNodeMaintenance(after))
+	}
+}
@@ -1,7 +1,7 @@
 //
 //  MIT License
 //
-//  (C) Copyright 2019-2024 Hewlett Packard Enterprise Development LP
+//  (C) Copyright 2019-2024, 2026 Hewlett Packard Enterprise Development LP
 //
 //  Permission is hereby granted, free of charge, to any person obtaining a
 //  copy of this software and associated documentation files (the "Software"),
@@ -31,24 +31,34 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"os"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 type NodeService interface {
-	getRedfishEndpoints() ([]redfishEndpoint, error)
-	getStateComponents() ([]stateComponent, error)
+	getRedfishEndpoints(hsmURL string) ([]redfishEndpoint, error)
+	getStateComponents(hsmURL string) ([]stateComponent, error)
 	getCurrentNodesFromHSM() (nodes []nodeConsoleInfo)
 	updateNodeCounts(numMtnNodes, numRvrNodes int)
+	updatePlacementHints(nodes map[string]nodeConsoleInfo)
 }
 
 // Implements NodeService
 type NodeManager struct {
-	k8Service K8Service
+	k8Service  K8Service
+	slsService SlsService
 }
 
+// Global var to record if the last query of hsm succeeded so an empty system
+// can be told apart from hsm being unavailable
+var hsmQuerySucceeded bool = false
+
 // Inject dependencies
-func NewNodeManager(k8Service K8Service) NodeService {
-	return &NodeManager{k8Service: k8Service}
+func NewNodeManager(k8Service K8Service, slsService SlsService) NodeService {
+	return &NodeManager{k8Service: k8Service, slsService: slsService}
 }
 
 // Struct to hold all node level information needed to form a console connection
@@ -66,6 +76,7 @@ type nodeConsoleInfo struct {
 	Class    string // river/mtn class
 	NID      int    // NID of the node
 	Role     string // role of the node
+	Source   string `json:",omitempty"` // hsm the node came from if more than one
 }
 
 // Function to determine if a node is Mountain hardware
@@ -85,8 +96,8 @@ func (node nodeConsoleInfo) isParadise() bool {
 
 // Provide a function to convert struct to string
 func (nc nodeConsoleInfo) String() string {
-	return fmt.Sprintf("NodeName:%s, BmcName:%s, BmcFqdn:%s, Class:%s, NID:%d, Role:%s",
-		nc.NodeName, nc.BmcName, nc.BmcFqdn, nc.Class, nc.NID, nc.Role)
+	return fmt.Sprintf("NodeName:%s, BmcName:%s, BmcFqdn:%s, Class:%s, NID:%d, Role:%s, Source:%s",
+		nc.NodeName, nc.BmcName, nc.BmcFqdn, nc.Class, nc.NID, nc.Role, nc.Source)
 }
 
 // Struct to hold hsm redfish endpoint information
@@ -118,16 +129,16 @@ func (sc stateComponent) String() string {
 }
 
 // Query hsm for redfish endpoint information
-func (NodeManager) getRedfishEndpoints() ([]redfishEndpoint, error) {
+func (NodeManager) getRedfishEndpoints(hsmURL string) ([]redfishEndpoint, error) {
 	type response struct {
 		RedfishEndpoints []redfishEndpoint
 	}
 
 	// Query hsm to get the redfish endpoints
-	URL := "http://cray-smd/hsm/v2/Inventory/RedfishEndpoints"
+	URL := hsmURL + "/Inventory/RedfishEndpoints"
 	data, _, err := getURL(URL, nil)
 	if err != nil {
-		log.Printf("Unable to get redfish endpoints from hsm:%s", err)
+		logError(errHSM, "Unable to get redfish endpoints from hsm:%s", err)
 		return nil, err
 	}
 
@@ -135,7 +146,7 @@ func (NodeManager) getRedfishEndpoints() ([]redfishEndpoint, error) {
 	rp := response{}
 	err = json.Unmarshal(data, &rp)
 	if err != nil {
-		log.Printf("Error unmarshalling data: %s", err)
+		logError(errHSM, "Error unmarshalling data: %s", err)
 		return nil, err
 	}
 
@@ -143,17 +154,17 @@ func (NodeManager) getRedfishEndpoints() ([]redfishEndpoint, error) {
 }
 
 // Query hsm for state component information
-func (NodeManager) getStateComponents() ([]stateComponent, error) {
+func (NodeManager) getStateComponents(hsmURL string) ([]stateComponent, error) {
 	// get the component states from hsm - includes river/mountain information
 	type response struct {
 		Components []stateComponent
 	}
 
 	// get the state components from hsm
-	URL := "http://cray-smd/hsm/v2/State/Components"
+	URL := hsmURL + "/State/Components"
 	data, _, err := getURL(URL, nil)
 	if err != nil {
-		log.Printf("Unable to get state component information from hsm:%s", err)
+		logError(errHSM, "Unable to get state component information from hsm:%s", err)
 		return nil, err
 	}
 
@@ -162,7 +173,7 @@ func (NodeManager) getStateComponents() ([]stateComponent, error) {
 	err = json.Unmarshal(data, &rp)
 	if err != nil {
 		// handle error
-		log.Printf("Error unmarshalling data: %s", err)
+		logError(errHSM, "Error unmarshalling data: %s", err)
 		return nil, nil
 	}
 
@@ -170,7 +181,7 @@ func (NodeManager) getStateComponents() ([]stateComponent, error) {
 }
 
 // Query hsm for Paradise (xd224) nodes
-func (NodeManager) getParadiseNodes() (map[string]struct{}, error) {
+func (NodeManager) getParadiseNodes(hsmURL string) (map[string]struct{}, error) {
 	// Paradise nodes are identified by having the manufacturer as 'Foxconn' and
 	// the model as either 'HPE Cray Supercomputing XD224' or '1A62WCB00-600-G'.
 	// There are a limited number of units that were sent to the field with the
@@ -197,10 +208,10 @@ func (NodeManager) getParadiseNodes() (map[string]struct{}, error) {
 	// Query hsm to get the Paradise nodes
 	// NOTE: this only pulls the Foxconn BMCs from the inventory so there is a bit of
 	//  server side filtering going on
-	URL := "http://cray-smd/hsm/v2/Inventory/Hardware?Manufacturer=Foxconn&Type=Node"
+	URL := hsmURL + "/Inventory/Hardware?Manufacturer=Foxconn&Type=Node"
 	data, _, err := getURL(URL, nil)
 	if err != nil {
-		log.Printf("Unable to get hardware inventory from hsm:%s", err)
+		logError(errHSM, "Unable to get hardware inventory from hsm:%s", err)
 		return nil, err
 	}
 
@@ -208,7 +219,7 @@ func (NodeManager) getParadiseNodes() (map[string]struct{}, error) {
 	rp := []HsmHardwareInventoryItem{}
 	err = json.Unmarshal(data, &rp)
 	if err != nil {
-		log.Printf("Error unmarshalling data: %s", err)
+		logError(errHSM, "Error unmarshalling data: %s", err)
 		return nil, err
 	}
 
@@ -224,19 +235,115 @@ func (NodeManager) getParadiseNodes() (map[string]struct{}, error) {
 	return nodes, nil
 }
 
+// An hsm instance to pull nodes from
+type hsmSource struct {
+	Name string
+	URL  string
+}
+
+// The hsm instances nodes are pulled from
+var hsmSources = []hsmSource{{Name: "", URL: "http://cray-smd/hsm/v2"}}
+
+// Parse a comma separated list of 'name=url' hsm sources
+func parseHSMSources(val string) ([]hsmSource, error) {
+	var sources []hsmSource = nil
+	names := make(map[string]struct{})
+	for _, src := range strings.Split(val, ",") {
+		src = strings.TrimSpace(src)
+		if src == "" {
+			continue
+		}
+		parts := strings.SplitN(src, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || !strings.HasPrefix(parts[1], "http") {
+			return nil, fmt.Errorf("Invalid hsm source, expected name=url: %s", src)
+		}
+		if _, found := names[parts[0]]; found {
+			return nil, fmt.Errorf("Duplicate hsm source name: %s", parts[0])
+		}
+		names[parts[0]] = struct{}{}
+		sources = append(sources, hsmSource{Name: parts[0], URL: strings.TrimSuffix(parts[1], "/")})
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("No hsm sources found in: %s", val)
+	}
+	return sources, nil
+}
+
+// Read the hsm source configuration
+func readHSMSources() {
+	registerSetting("HSM_SOURCES", func() string {
+		var srcs []string = nil
+		for _, src := range hsmSources {
+			srcs = append(srcs, src.Name+"="+src.URL)
+		}
+		return strings.Join(srcs, ",")
+	}, ConfigSetting{})
+	val := os.Getenv("HSM_SOURCES")
+	if val == "" {
+		return
+	}
+	sources, err := parseHSMSources(val)
+	if err != nil {
+		logError(errConfig, "Error: ignoring HSM_SOURCES: %s", err)
+		return
+	}
+	for _, src := range sources {
+		log.Printf("HSM source %s: %s", src.Name, src.URL)
+	}
+	hsmSources = sources
+}
+
+// Get the nodes from all the hsm sources
+// NOTE: with more than one source each node is tagged with the source it
+// came from and if a source can not be reached the nodes already known from
+// it are kept so they are not removed from console-data
 func (nm NodeManager) getCurrentNodesFromHSM() (nodes []nodeConsoleInfo) {
+	if len(hsmSources) == 1 {
+		return nm.getCurrentNodesFromSource(hsmSources[0])
+	}
+
+	allSucceeded := true
+	seen := make(map[string]string)
+	for _, src := range hsmSources {
+		srcNodes := nm.getCurrentNodesFromSource(src)
+		if !hsmQuerySucceeded {
+			allSucceeded = false
+			log.Printf("Keeping the known nodes from hsm source %s", src.Name)
+			for _, n := range nodeCache {
+				if n.Source == src.Name {
+					srcNodes = append(srcNodes, n)
+				}
+			}
+		}
+		for _, n := range srcNodes {
+			if other, found := seen[n.NodeName]; found {
+				log.Printf("Warning: node %s is in hsm sources %s and %s, using %s", n.NodeName, other, src.Name, other)
+				continue
+			}
+			seen[n.NodeName] = src.Name
+			n.Source = src.Name
+			nodes = append(nodes, n)
+		}
+	}
+	hsmQuerySucceeded = allSucceeded
+	return nodes
+}
+
+// Get the nodes from a single hsm source
+func (nm NodeManager) getCurrentNodesFromSource(src hsmSource) (nodes []nodeConsoleInfo) {
 	// Get the BMC IP addresses and user, and password for individual nodes.
 	// conman is only set up for River nodes.
-	log.Printf("Starting to get current nodes on the system")
+	log.Printf("Starting to get current nodes on the system from %s", src.URL)
 
-	rfEndpoints, err := nm.getRedfishEndpoints()
+	hsmQuerySucceeded = false
+	rfEndpoints, err := nm.getRedfishEndpoints(src.URL)
 	if err != nil {
 		log.Printf("Unable to build configuration file - error fetching redfish endpoints: %s", err)
 		return nil
 	}
 
 	// get the state information to find mountain/river designation
-	stComps, err := nm.getStateComponents()
+	stComps, err := nm.getStateComponents(src.URL)
 	if err != nil {
 		log.Printf("Unable to build configuration file - error fetching state components: %s", err)
 		return nil
@@ -244,12 +351,15 @@ func (nm NodeManager) getCurrentNodesFromHSM() (nodes []nodeConsoleInfo) {
 
 	// get the paradise nodes
 	// NOTE: this returns a pseudo-set to speed up lookups
-	paradiseNodes, err := nm.getParadiseNodes()
+	paradiseNodes, err := nm.getParadiseNodes(src.URL)
 	if err != nil {
 		// log the error but don't die - most systems will not have Paradise nodes anyway
 		log.Printf("Unable to identify if there are any Paradise nodes on the system. %s", err)
 	}
 
+	// the required hsm information is available
+	hsmQuerySucceeded = true
+
 	// create a lookup map for the redfish information
 	rfMap := make(map[string]redfishEndpoint)
 	for _, rf := range rfEndpoints {
@@ -298,9 +408,24 @@ func (nm NodeManager) updateNodeCounts(numMtnNodes, numRvrNodes int) {
 	log.Printf("Mountain current: %d, max per node: %d", numMtnNodes, maxMtnNodesPerPod)
 	log.Printf("River    current: %d, max per node: %d", numRvrNodes, maxRvrNodesPerPod)
 
+	// leave everything alone during planned maintenance
+	if frozen, reason := scalingFrozen(time.Now()); frozen {
+		log.Printf("Scaling changes frozen (%s), skipping count update", reason)
+		return
+	}
+
 	// bail if there hasn't been anything reported yet - don't want to change
 	// replica count when hsm hasn't been populated (or contacted) yet
 	if numMtnNodes+numRvrNodes == 0 {
+		// if enabled, reclaim the console-node pods when hsm really has no
+		// nodes - they will be scaled back up when nodes are discovered
+		if scaleToZero && hsmQuerySucceeded {
+			log.Printf("No nodes found, scaling console-node to zero pods")
+			totalRvrNodes = 0
+			totalMtnNodes = 0
+			nm.k8Service.updateReplicaCount(0)
+			return
+		}
 		log.Printf("No nodes found, skipping count update")
 		return
 	}
@@ -321,9 +446,38 @@ func (nm NodeManager) updateNodeCounts(numMtnNodes, numRvrNodes int) {
 		newNumPods = numRvrReq
 	}
 
+	// add more pods if the current ones are running low on cpu or memory
+	// NOTE: if metrics are not available just go with the node counts
+	if resourceScaling {
+		if usage, err := nm.k8Service.getConsoleNodePodUsage(); err == nil {
+			resourceNodePods = calcResourceRequiredPods(usage, scaleCPUPercent, scaleMemPercent)
+			if resourceNodePods > newNumPods {
+				log.Printf("Increasing console-node pods from %d to %d based on resource usage", newNumPods, resourceNodePods)
+				newNumPods = resourceNodePods
+			}
+		}
+	}
+
+	// keep within the site bounds here as well so the per pod targets match
+	// the number of pods that will actually be running
+	newNumPods = boundNodePods(newNumPods)
+	if newNumPods < numMtnReq || newNumPods < numRvrReq {
+		log.Printf("Warning: MAX_NODE_PODS:%d is less than needed, pods will be assigned more than the maximum nodes per pod", maxNodePods)
+	}
+
 	// update the number of nodes / pod based on number of pods
 	nm.k8Service.updateReplicaCount(newNumPods)
 
+	// NOTE: pods that are starting or stopping can not pick up new targets,
+	//  wait for the next check once the replicas are all ready.  The first
+	//  targets are always written since the pods need them to start.
+	if numMtnNodesPerPod >= 0 && numRvrNodesPerPod >= 0 {
+		if done, err := nm.k8Service.isRolloutComplete(); err != nil || !done {
+			log.Printf("Waiting for console-node rollout to complete before updating targets")
+			return
+		}
+	}
+
 	// update the number of mtn + river consoles to watch per pod
 	// NOTE: adding a little slop to how many each pod wants
 	// needed for worst case where a replica can acquire more nodes
@@ -345,4 +499,156 @@ func (nm NodeManager) updateNodeCounts(numMtnNodes, numRvrNodes int) {
 			nm.k8Service.updateNodesPerPod(newMtn, newRvr)
 		}
 	}
+
+	// split the nodes by the capacity of the running pods if configured
+	// NOTE: the even split above is still written as the shared target for
+	//  any console-node that does not read its own file
+	if capacityDistribution {
+		nm.updatePodTargets(numMtnNodes, numRvrNodes)
+	}
+}
+
+// Recalculate and publish the per pod targets based on pod capacity
+func (nm NodeManager) updatePodTargets(numMtnNodes, numRvrNodes int) {
+	capacity, err := nm.k8Service.getConsoleNodePodCapacity()
+	if err != nil {
+		log.Printf("Unable to get console-node pod capacity, keeping the even split: %s", err)
+		return
+	}
+	targets := calcPodTargets(numMtnNodes, numRvrNodes, capacity)
+	if canaryTargets {
+		targets = nm.canaryPodTargets(targets)
+	}
+	for _, pt := range targets {
+		log.Printf("Pod %s capacity: %d, targets- Mtn: %d, Rvr: %d", pt.PodName, pt.Capacity, pt.TargetNumMtnNodes, pt.TargetNumRvrNodes)
+	}
+	// NOTE: a pod being restarted stays held at no consoles
+	nm.k8Service.updatePodTargets(cordonPodTargets(targets, getCordonedPod()))
+
+	podTargetsMutex.Lock()
+	podTargets = targets
+	podTargetsMutex.Unlock()
+}
+
+// Struct to hold the target number of nodes for a single console-node pod
+type podTarget struct {
+	PodName           string `json:"podname"`
+	Capacity          int64  `json:"capacity"`
+	TargetNumRvrNodes int    `json:"targetnumrvrnodes"`
+	TargetNumMtnNodes int    `json:"targetnummtnnodes"`
+}
+
+// Current per pod targets when distributing by capacity
+var podTargets []podTarget = nil
+var podTargetsMutex sync.Mutex
+
+// Split the nodes between the pods in proportion to the capacity of each pod
+// NOTE: pods without a known capacity are given the average of the others,
+// the same slop as the even split is added, and no pod is given more than
+// the larger of the max per pod and the even split
+func calcPodTargets(numMtnNodes, numRvrNodes int, capacity map[string]int64) []podTarget {
+	if len(capacity) == 0 {
+		return nil
+	}
+
+	// find the average capacity to fill in for unknown pods
+	var known, numKnown int64
+	for _, c := range capacity {
+		if c > 0 {
+			known += c
+			numKnown++
+		}
+	}
+	var avg int64 = 1
+	if numKnown > 0 {
+		avg = known / numKnown
+	}
+	var total int64
+	for _, c := range capacity {
+		if c <= 0 {
+			c = avg
+		}
+		total += c
+	}
+
+	numPods := float64(len(capacity))
+	evenMtn := int(math.Ceil(float64(numMtnNodes)/numPods) + 1)
+	evenRvr := int(math.Ceil(float64(numRvrNodes)/numPods) + 1)
+	capMtn := maxMtnNodesPerPod
+	if evenMtn > capMtn {
+		capMtn = evenMtn
+	}
+	capRvr := maxRvrNodesPerPod
+	if evenRvr > capRvr {
+		capRvr = evenRvr
+	}
+
+	// keep a stable order for reporting
+	podNames := make([]string, 0, len(capacity))
+	for pn := range capacity {
+		podNames = append(podNames, pn)
+	}
+	sort.Strings(podNames)
+
+	targets := make([]podTarget, 0, len(podNames))
+	for _, pn := range podNames {
+		c := capacity[pn]
+		if c <= 0 {
+			c = avg
+		}
+		share := float64(c) / float64(total)
+		pt := podTarget{PodName: pn, Capacity: c}
+		pt.TargetNumMtnNodes = int(math.Ceil(float64(numMtnNodes)*share) + 1)
+		pt.TargetNumRvrNodes = int(math.Ceil(float64(numRvrNodes)*share) + 1)
+		if pt.TargetNumMtnNodes > capMtn {
+			pt.TargetNumMtnNodes = capMtn
+		}
+		if pt.TargetNumRvrNodes > capRvr {
+			pt.TargetNumRvrNodes = capRvr
+		}
+		targets = append(targets, pt)
+	}
+	return targets
+}
+
+// Get a copy of the current per pod targets
+func getPodTargets() []podTarget {
+	podTargetsMutex.Lock()
+	defer podTargetsMutex.Unlock()
+	targets := make([]podTarget, len(podTargets))
+	copy(targets, podTargets)
+	return targets
+}
+
+// Calculate how many console-node pods are needed to keep the total cpu and
+// memory used by the pods under the given percent of the per pod limits
+func calcResourceRequiredPods(usage []podResourceUsage, cpuPct, memPct int) int {
+	var cpuUsage, memUsage, cpuLimit, memLimit int64
+	for _, pu := range usage {
+		cpuUsage += pu.CPUUsage
+		memUsage += pu.MemUsage
+		// use the smallest limit so the pods with the least room are covered
+		if pu.CPULimit > 0 && (cpuLimit == 0 || pu.CPULimit < cpuLimit) {
+			cpuLimit = pu.CPULimit
+		}
+		if pu.MemLimit > 0 && (memLimit == 0 || pu.MemLimit < memLimit) {
+			memLimit = pu.MemLimit
+		}
+	}
+
+	// NOTE: without a limit there is no way to tell if a pod is overloaded
+	reqPods := 0
+	if cpuLimit > 0 && cpuPct > 0 {
+		n := int(math.Ceil(float64(cpuUsage) / (float64(cpuLimit) * float64(cpuPct) / 100)))
+		if n > reqPods {
+			reqPods = n
+		}
+	}
+	if memLimit > 0 && memPct > 0 {
+		n := int(math.Ceil(float64(memUsage) / (float64(memLimit) * float64(memPct) / 100)))
+		if n > reqPods {
+			reqPods = n
+		}
+	}
+	return reqPods
 }
@@ -0,0 +1,119 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"testing"
+)
+
+func TestCalcResourceRequiredPods(t *testing.T) {
+	tests := []struct {
+		name     string
+		usage    []podResourceUsage
+		expected int
+	}{
+		{"no pods", nil, 0},
+		{"no limits", []podResourceUsage{{PodName: "p0", CPUUsage: 900, MemUsage: 900}}, 0},
+		// 3 pods using 2400m total with a 1000m limit at 80% needs 3 pods
+		{"cpu at threshold", []podResourceUsage{
+			{PodName: "p0", CPUUsage: 800, CPULimit: 1000},
+			{PodName: "p1", CPUUsage: 800, CPULimit: 1000},
+			{PodName: "p2", CPUUsage: 800, CPULimit: 1000},
+		}, 3},
+		// 3 pods using 2700m total needs a 4th pod
+		{"cpu over threshold", []podResourceUsage{
+			{PodName: "p0", CPUUsage: 900, CPULimit: 1000},
+			{PodName: "p1", CPUUsage: 900, CPULimit: 1000},
+			{PodName: "p2", CPUUsage: 900, CPULimit: 1000},
+		}, 4},
+		// memory drives the count, smallest limit is used
+		{"memory over threshold", []podResourceUsage{
+			{PodName: "p0", CPUUsage: 100, CPULimit: 1000, MemUsage: 1000, MemLimit: 1000},
+			{PodName: "p1", CPUUsage: 100, CPULimit: 1000, MemUsage: 1000, MemLimit: 2000},
+		}, 3},
+	}
+	for _, tc := range tests {
+		if got := calcResourceRequiredPods(tc.usage, 80, 80); got != tc.expected {
+			t.Errorf("%s: Expected: %d. Got: %d.", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestCalcPodTargets(t *testing.T) {
+	// no pods means no targets
+	if targets := calcPodTargets(100, 100, nil); targets != nil {
+		t.Errorf("Expected: nil. Got: %v.", targets)
+	}
+
+	// p1 has three times the capacity of p0, p2 has no known capacity so is
+	// treated as the average of the others
+	capacity := map[string]int64{"p0": 1000, "p1": 3000, "p2": 0}
+	targets := calcPodTargets(300, 60, capacity)
+	expected := []podTarget{
+		{PodName: "p0", Capacity: 1000, TargetNumMtnNodes: 51, TargetNumRvrNodes: 11},
+		{PodName: "p1", Capacity: 3000, TargetNumMtnNodes: 151, TargetNumRvrNodes: 31},
+		{PodName: "p2", Capacity: 2000, TargetNumMtnNodes: 101, TargetNumRvrNodes: 21},
+	}
+	if len(targets) != len(expected) {
+		t.Fatalf("Expected: %d targets. Got: %d.", len(expected), len(targets))
+	}
+	for i, pt := range targets {
+		if pt != expected[i] {
+			t.Errorf("Expected: %v. Got: %v.", expected[i], pt)
+		}
+	}
+
+	// no pod is given more than the max per pod
+	targets = calcPodTargets(maxMtnNodesPerPod*2, 0, map[string]int64{"p0": 1, "p1": 1, "p2": 100})
+	if targets[2].TargetNumMtnNodes != maxMtnNodesPerPod {
+		t.Errorf("Expected: %d. Got: %d.", maxMtnNodesPerPod, targets[2].TargetNumMtnNodes)
+	}
+}
+
+func TestParseHSMSources(t *testing.T) {
+	sources, err := parseHSMSources("prod=http://cray-smd/hsm/v2, test=http://test-smd.test/hsm/v2/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := []hsmSource{
+		{Name: "prod", URL: "http://cray-smd/hsm/v2"},
+		{Name: "test", URL: "http://test-smd.test/hsm/v2"},
+	}
+	if len(sources) != len(expected) {
+		t.Fatalf("Expected: %d sources. Got: %d.", len(expected), len(sources))
+	}
+	for i, src := range sources {
+		if src != expected[i] {
+			t.Errorf("Expected: %v. Got: %v.", expected[i], src)
+		}
+	}
+
+	// bad sources are rejected
+	for _, bad := range []string{"", "http://cray-smd/hsm/v2", "a=http://x,a=http://y", "a=cray-smd"} {
+		if _, err := parseHSMSources(bad); err == nil {
+			t.Errorf("Expected an error for: %s", bad)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to track how fast each node writes console
+//  output so the nodes flooding their consoles can be found
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Time the output rates are averaged over
+const outputRateWindow = 10 * time.Minute
+
+// Most new output read from a log to count lines in one check, beyond this
+// the line count is estimated
+const maxRateScanBytes int64 = 8 * 1024 * 1024
+
+// Output seen in one check
+type outputSample struct {
+	time  time.Time
+	bytes int64
+	lines int64
+}
+
+// NodeOutputRate - the console output rate of a node
+type NodeOutputRate struct {
+	Xname          string  `json:"xname"`
+	BytesPerMinute float64 `json:"bytesperminute"`
+	LinesPerMinute float64 `json:"linesperminute"`
+	TotalBytes     int64   `json:"totalbytes"`
+}
+
+// Output samples and totals by xname
+var outputSamples map[string][]outputSample = make(map[string][]outputSample)
+var outputTotals map[string]int64 = make(map[string]int64)
+var outputRatesMutex sync.Mutex
+
+// Get a copy of what is known about the console log files
+func getConsoleLogFileStates() map[string]consoleLogFileState {
+	consoleLogMutex.Lock()
+	defer consoleLogMutex.Unlock()
+	states := make(map[string]consoleLogFileState)
+	for path, st := range consoleLogFiles {
+		states[path] = st
+	}
+	return states
+}
+
+// Count the lines written to a log between two offsets
+func countLogLines(path string, from, to int64) int64 {
+	start := from
+	if to-start > maxRateScanBytes {
+		start = to - maxRateScanBytes
+	}
+	data, err := readLogRange(path, start, to)
+	if err != nil || len(data) == 0 {
+		return 0
+	}
+	lines := int64(bytes.Count(data, []byte{'\n'}))
+	if start != from {
+		lines = lines * (to - from) / int64(len(data))
+	}
+	return lines
+}
+
+// Record the output written to each log since the last check
+func recordOutputRates(prev, current map[string]consoleLogFileState, now time.Time) {
+	for path, st := range current {
+		p, found := prev[path]
+		if !found {
+			continue
+		}
+		from := p.size
+		if st.inode != p.inode || st.size < p.size {
+			// rotated, all of the new file is new output
+			from = 0
+		}
+		if st.size == from {
+			addOutputSample(path, outputSample{time: now})
+			continue
+		}
+		addOutputSample(path, outputSample{
+			time:  now,
+			bytes: st.size - from,
+			lines: countLogLines(path, from, st.size),
+		})
+	}
+}
+
+// Add a sample for a log, dropping the ones outside the window
+func addOutputSample(path string, s outputSample) {
+	xname, _, _ := parseConsoleLogName(filepath.Base(path))
+	outputRatesMutex.Lock()
+	defer outputRatesMutex.Unlock()
+	samples := append(outputSamples[xname], s)
+	for len(samples) > 0 && s.time.Sub(samples[0].time) > outputRateWindow {
+		samples = samples[1:]
+	}
+	outputSamples[xname] = samples
+	outputTotals[xname] += s.bytes
+}
+
+// Get the output rates of the nodes, the fastest first, limited to the top
+// given number if it is more than 0
+func getOutputRates(top int) []NodeOutputRate {
+	outputRatesMutex.Lock()
+	defer outputRatesMutex.Unlock()
+	rates := []NodeOutputRate{}
+	for xname, samples := range outputSamples {
+		rate := NodeOutputRate{Xname: xname, TotalBytes: outputTotals[xname]}
+		// NOTE: the first sample only marks the start of the window
+		if len(samples) > 1 {
+			minutes := samples[len(samples)-1].time.Sub(samples[0].time).Minutes()
+			var b, l int64 = 0, 0
+			for _, s := range samples[1:] {
+				b += s.bytes
+				l += s.lines
+			}
+			if minutes > 0 {
+				rate.BytesPerMinute = float64(b) / minutes
+				rate.LinesPerMinute = float64(l) / minutes
+			}
+		}
+		rates = append(rates, rate)
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i].BytesPerMinute != rates[j].BytesPerMinute {
+			return rates[i].BytesPerMinute > rates[j].BytesPerMinute
+		}
+		return rates[i].Xname < rates[j].Xname
+	})
+	if top > 0 && len(rates) > top {
+		rates = rates[:top]
+	}
+	return rates
+}
+
+// Format the output rates in the prometheus text format
+func outputRateMetrics(rates []NodeOutputRate) string {
+	var sb strings.Builder
+	sb.WriteString("# HELP console_output_bytes_per_minute Console output rate of a node.\n")
+	sb.WriteString("# TYPE console_output_bytes_per_minute gauge\n")
+	for _, r := range rates {
+		fmt.Fprintf(&sb, "console_output_bytes_per_minute{xname=%q} %g\n", r.Xname, r.BytesPerMinute)
+	}
+	sb.WriteString("# HELP console_output_lines_per_minute Console output lines per minute of a node.\n")
+	sb.WriteString("# TYPE console_output_lines_per_minute gauge\n")
+	for _, r := range rates {
+		fmt.Fprintf(&sb, "console_output_lines_per_minute{xname=%q} %g\n", r.Xname, r.LinesPerMinute)
+	}
+	sb.WriteString("# HELP console_output_bytes_total Console output written by a node since the operator started.\n")
+	sb.WriteString("# TYPE console_output_bytes_total counter\n")
+	for _, r := range rates {
+		fmt.Fprintf(&sb, "console_output_bytes_total{xname=%q} %d\n", r.Xname, r.TotalBytes)
+	}
+	return sb.String()
+}
+
+// Report the console output rates, `?top=N` only reports the N fastest nodes
+func (LogManager) doGetOutputRates(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	top := 0
+	if v := r.URL.Query().Get("top"); v != "" {
+		var err error
+		if top, err = strconv.Atoi(v); err != nil || top < 1 {
+			sendJSONError(w, http.StatusBadRequest,
+				fmt.Sprintf("Invalid top %s, expected a positive number", v))
+			return
+		}
+	}
+	SendResponseJSON(w, http.StatusOK, getOutputRates(top))
+}
+
+// Report the console output rates as prometheus metrics
+func (LogManager) doGetMetrics(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(outputRateMetrics(getOutputRates(0))))
+}
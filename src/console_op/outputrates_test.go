@@ -0,0 +1,71 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOutputRates(t *testing.T) {
+	defer func() {
+		outputSamples = make(map[string][]outputSample)
+		outputTotals = make(map[string]int64)
+	}()
+	dir, err := ioutil.TempDir("", "outputrates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	noisy := filepath.Join(dir, "console.x1000c0s0b0n0")
+	quiet := filepath.Join(dir, "console.x1000c0s0b0n1")
+	ioutil.WriteFile(noisy, []byte(strings.Repeat("0123456789\n", 20)), 0644)
+	ioutil.WriteFile(quiet, []byte("0123456789\n"), 0644)
+
+	now := time.Now()
+	start := map[string]consoleLogFileState{noisy: {inode: 1, size: 0}, quiet: {inode: 2, size: 0}}
+	recordOutputRates(nil, start, now)
+	recordOutputRates(start, start, now)
+	current := map[string]consoleLogFileState{noisy: {inode: 1, size: 220}, quiet: {inode: 2, size: 11}}
+	recordOutputRates(start, current, now.Add(2*time.Minute))
+
+	rates := getOutputRates(0)
+	if len(rates) != 2 || rates[0].Xname != "x1000c0s0b0n0" {
+		t.Fatalf("Expected x1000c0s0b0n0 to be the top talker. Got: %v.", rates)
+	}
+	if rates[0].BytesPerMinute != 110 || rates[0].LinesPerMinute != 10 || rates[0].TotalBytes != 220 {
+		t.Errorf("Expected: 110, 10, 220. Got: %g, %g, %d.", rates[0].BytesPerMinute, rates[0].LinesPerMinute, rates[0].TotalBytes)
+	}
+	if top := getOutputRates(1); len(top) != 1 {
+		t.Errorf("Expected: 1. Got: %d.", len(top))
+	}
+	if m := outputRateMetrics(rates); !strings.Contains(m, `console_output_bytes_per_minute{xname="x1000c0s0b0n0"} 110`) {
+		t.Errorf("Unexpected metrics: %s", m)
+	}
+}
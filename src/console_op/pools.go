@@ -0,0 +1,298 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to manage several console-node workloads, each
+//  in its own namespace, as pools of console-node pods
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// A console-node workload, the namespace it runs in and its scaling state
+// NOTE: a pod is only known by its name so the pod names must be unique
+// across the pools - the workloads of the pools need different names
+type consoleNodePool struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Weight    int    `json:"weight"`
+	Replicas  int    `json:"replicas"`
+	Wanted    int    `json:"wanted"`
+	Error     string `json:"error,omitempty"`
+}
+
+// The console-node pools - the first is the one set by CONSOLE_NODE_KIND,
+// CONSOLE_NODE_NAME and CONSOLE_NODE_NAMESPACE and the rest come from
+// CONSOLE_NODE_POOLS
+var consoleNodePools []consoleNodePool = nil
+var consoleNodePoolsMutex sync.Mutex
+
+// Namespace of each console-node pod seen the last time the pods were listed
+var podNamespaces map[string]string = make(map[string]string)
+var podNamespacesMutex sync.Mutex
+
+// Parse a list of extra pools in the form 'namespace/kind/name[=weight],...'
+func parseConsoleNodePools(val string) ([]consoleNodePool, error) {
+	var pools []consoleNodePool = nil
+	for _, p := range strings.Split(val, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		pool := consoleNodePool{Weight: 1}
+		if pos := strings.LastIndex(p, "="); pos >= 0 {
+			w, err := strconv.Atoi(p[pos+1:])
+			if err != nil || w < 1 {
+				return nil, fmt.Errorf("Invalid weight for console-node pool: %s", p)
+			}
+			pool.Weight = w
+			p = p[:pos]
+		}
+		parts := strings.Split(p, "/")
+		if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("Invalid console-node pool, expected namespace/kind/name: %s", p)
+		}
+		if parts[1] != "StatefulSet" && parts[1] != "Deployment" {
+			return nil, fmt.Errorf("Invalid kind for console-node pool %s: %s", p, parts[1])
+		}
+		pool.Namespace, pool.Kind, pool.Name = parts[0], parts[1], parts[2]
+		pools = append(pools, pool)
+	}
+	return pools, nil
+}
+
+// Read the console-node pools, the configured workload is always the first
+func readConsoleNodePools() {
+	registerSetting("CONSOLE_NODE_POOLS", func() string {
+		var pools []string = nil
+		for _, p := range getConsoleNodePools()[1:] {
+			pools = append(pools, fmt.Sprintf("%s/%s/%s=%d", p.Namespace, p.Kind, p.Name, p.Weight))
+		}
+		return strings.Join(pools, ",")
+	}, ConfigSetting{})
+	pools := []consoleNodePool{{Namespace: consoleNodeNamespace, Kind: consoleNodeKind, Name: consoleNodeName, Weight: 1}}
+	if val := os.Getenv("CONSOLE_NODE_POOLS"); val != "" {
+		extra, err := parseConsoleNodePools(val)
+		if err != nil {
+			logError(errConfig, "Error reading CONSOLE_NODE_POOLS, only using %s: %s", consoleNodeName, err)
+			extra = nil
+		}
+		names := map[string]struct{}{consoleNodeName: {}}
+		for _, p := range extra {
+			if _, found := names[p.Name]; found {
+				logError(errConfig, "Console-node pool %s/%s has the name of another pool, skipping it", p.Namespace, p.Name)
+				continue
+			}
+			names[p.Name] = struct{}{}
+			log.Printf("Console-node pool: %s %s in namespace %s, weight %d", p.Kind, p.Name, p.Namespace, p.Weight)
+			pools = append(pools, p)
+		}
+	}
+	consoleNodePoolsMutex.Lock()
+	consoleNodePools = pools
+	consoleNodePoolsMutex.Unlock()
+}
+
+// Get a copy of the console-node pools
+func getConsoleNodePools() []consoleNodePool {
+	consoleNodePoolsMutex.Lock()
+	defer consoleNodePoolsMutex.Unlock()
+	if len(consoleNodePools) == 0 {
+		return []consoleNodePool{{Namespace: consoleNodeNamespace, Kind: consoleNodeKind, Name: consoleNodeName, Weight: 1}}
+	}
+	pools := make([]consoleNodePool, len(consoleNodePools))
+	copy(pools, consoleNodePools)
+	return pools
+}
+
+// Record the scaling state of a pool
+func setPoolState(name string, replicas, wanted int, err error) {
+	consoleNodePoolsMutex.Lock()
+	defer consoleNodePoolsMutex.Unlock()
+	for i := range consoleNodePools {
+		if consoleNodePools[i].Name == name {
+			consoleNodePools[i].Replicas = replicas
+			consoleNodePools[i].Wanted = wanted
+			consoleNodePools[i].Error = ""
+			if err != nil {
+				consoleNodePools[i].Error = err.Error()
+			}
+		}
+	}
+}
+
+// Split the console-node pods between the pools by their weights
+// NOTE: the pods left over from rounding go to the first pools
+func splitPoolReplicas(numPods int, pools []consoleNodePool) []int {
+	split := make([]int, len(pools))
+	totalWeight := 0
+	for _, p := range pools {
+		totalWeight += p.Weight
+	}
+	if totalWeight == 0 {
+		return split
+	}
+	assigned := 0
+	for i, p := range pools {
+		split[i] = numPods * p.Weight / totalWeight
+		assigned += split[i]
+	}
+	for i := 0; assigned < numPods; i = (i + 1) % len(pools) {
+		split[i]++
+		assigned++
+	}
+	return split
+}
+
+// Remember the namespace of the pods found in a pool
+func setPodNamespaces(namespace string, pods []corev1.Pod) {
+	podNamespacesMutex.Lock()
+	defer podNamespacesMutex.Unlock()
+	for _, pod := range pods {
+		podNamespaces[pod.GetName()] = namespace
+	}
+}
+
+// Namespace a console-node pod runs in - a pod that has not been seen yet
+// is looked for in the first pool
+func podNamespace(podName string) string {
+	podNamespacesMutex.Lock()
+	ns, found := podNamespaces[podName]
+	podNamespacesMutex.Unlock()
+	if found {
+		return ns
+	}
+	return getConsoleNodePools()[0].Namespace
+}
+
+// Get the workload of a console-node pool
+func (k8s K8Manager) getPoolWorkload(pool consoleNodePool) (*consoleNodeWorkload, error) {
+	var wl consoleNodeWorkload
+	var err error
+	if pool.Kind == "Deployment" {
+		var dep *appsv1.Deployment
+		dep, err = k8s.clientset.AppsV1().Deployments(pool.Namespace).Get(pool.Name, metav1.GetOptions{})
+		if err == nil {
+			wl = consoleNodeWorkload{Replicas: 1, Generation: dep.Generation,
+				ObservedGeneration: dep.Status.ObservedGeneration, StatusReplicas: dep.Status.Replicas,
+				ReadyReplicas: dep.Status.ReadyReplicas, Selector: dep.Spec.Selector, UID: dep.GetUID(), deployment: dep}
+			if dep.Spec.Replicas != nil {
+				wl.Replicas = *dep.Spec.Replicas
+			}
+		}
+	} else {
+		var ss *appsv1.StatefulSet
+		ss, err = k8s.clientset.AppsV1().StatefulSets(pool.Namespace).Get(pool.Name, metav1.GetOptions{})
+		if err == nil {
+			wl = consoleNodeWorkload{Replicas: 1, Generation: ss.Generation,
+				ObservedGeneration: ss.Status.ObservedGeneration, StatusReplicas: ss.Status.Replicas,
+				ReadyReplicas: ss.Status.ReadyReplicas, Selector: ss.Spec.Selector, UID: ss.GetUID(), statefulSet: ss}
+			if ss.Spec.Replicas != nil {
+				wl.Replicas = *ss.Spec.Replicas
+			}
+		}
+	}
+
+	if errors.IsNotFound(err) {
+		log.Printf("%s %s not found in %s namespace\n", pool.Kind, pool.Name, pool.Namespace)
+		return nil, err
+	} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+		logError(errK8s, "Error getting %s %s in %s namespace: %v\n", pool.Kind, pool.Name, pool.Namespace, statusError.ErrStatus.Message)
+		return nil, err
+	} else if err != nil {
+		log.Printf("Unknown error getting %s %s in %s namespace: %s", pool.Kind, pool.Name, pool.Namespace, err.Error())
+		return nil, err
+	}
+	wl.Namespace = pool.Namespace
+	return &wl, nil
+}
+
+// Find the running pods of a console-node pool
+func (k8s K8Manager) getRunningPoolPods(pool consoleNodePool) ([]corev1.Pod, error) {
+	// use the selector of the workload to find the pods it owns
+	wl, err := k8s.getPoolWorkload(pool)
+	if err != nil {
+		return nil, err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(wl.Selector)
+	if err != nil {
+		logError(errK8s, "Error parsing the %s selector: %s", pool.Name, err)
+		return nil, err
+	}
+	pods, err := k8s.clientset.CoreV1().Pods(pool.Namespace).List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		logError(errK8s, "Error listing %s pods: %s", pool.Name, err)
+		return nil, err
+	}
+
+	var running []corev1.Pod = nil
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodRunning {
+			running = append(running, pod)
+		}
+	}
+	setPodNamespaces(pool.Namespace, running)
+	return running, nil
+}
+
+// Scale one console-node pool
+func (k8s K8Manager) scalePool(pool consoleNodePool, replicas int) error {
+	wl, err := k8s.getPoolWorkload(pool)
+	if err != nil {
+		setPoolState(pool.Name, pool.Replicas, replicas, err)
+		return err
+	}
+	currReplicas := wl.Replicas
+	log.Printf("Current %s replicas: %d, Requested replicas: %d", pool.Name, currReplicas, replicas)
+	if int32(replicas) == currReplicas {
+		setPoolState(pool.Name, int(currReplicas), replicas, nil)
+		return nil
+	}
+	newReplicas, err := k8s.setConsoleNodeReplicas(wl, int32(replicas))
+	if err != nil {
+		logError(errScaling, "Error updating %s: %s", pool.Name, err.Error())
+		k8s.recordEvent(eventOnConsoleNode, corev1.EventTypeWarning, "ScaleFailed",
+			fmt.Sprintf("Unable to scale %s/%s from %d to %d replicas: %s", pool.Namespace, pool.Name, currReplicas, replicas, err))
+		setPoolState(pool.Name, int(currReplicas), replicas, err)
+		return err
+	}
+	log.Printf("  Updated %s %s to %d replicas", pool.Kind, pool.Name, newReplicas)
+	k8s.recordEvent(eventOnConsoleNode, corev1.EventTypeNormal, "Scaled",
+		fmt.Sprintf("Scaled %s/%s from %d to %d replicas for %d mountain and %d river nodes",
+			pool.Namespace, pool.Name, currReplicas, newReplicas, totalMtnNodes, totalRvrNodes))
+	setPoolState(pool.Name, int(newReplicas), replicas, nil)
+	return nil
+}
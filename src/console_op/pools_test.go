@@ -0,0 +1,90 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseConsoleNodePools(t *testing.T) {
+	pools, err := parseConsoleNodePools("tenant-a/Deployment/console-node-a=2, tenant-b/StatefulSet/console-node-b")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := []consoleNodePool{
+		{Namespace: "tenant-a", Kind: "Deployment", Name: "console-node-a", Weight: 2},
+		{Namespace: "tenant-b", Kind: "StatefulSet", Name: "console-node-b", Weight: 1},
+	}
+	if !reflect.DeepEqual(pools, expected) {
+		t.Errorf("Expected: %v. Got: %v.", expected, pools)
+	}
+
+	for _, bad := range []string{"tenant-a/console-node-a", "tenant-a/DaemonSet/console-node-a",
+		"/StatefulSet/console-node-a", "tenant-a/StatefulSet/console-node-a=0", "tenant-a/StatefulSet/=2"} {
+		if _, err := parseConsoleNodePools(bad); err == nil {
+			t.Errorf("Expected an error for: %s", bad)
+		}
+	}
+}
+
+func TestSplitPoolReplicas(t *testing.T) {
+	pools := []consoleNodePool{{Name: "a", Weight: 1}, {Name: "b", Weight: 2}, {Name: "c", Weight: 1}}
+	tests := []struct {
+		numPods  int
+		expected []int
+	}{
+		{0, []int{0, 0, 0}},
+		{1, []int{1, 0, 0}},
+		{4, []int{1, 2, 1}},
+		{6, []int{2, 3, 1}},
+		{8, []int{2, 4, 2}},
+	}
+	for _, tc := range tests {
+		if got := splitPoolReplicas(tc.numPods, pools); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("%d pods: Expected: %v. Got: %v.", tc.numPods, tc.expected, got)
+		}
+	}
+}
+
+func TestPodNamespace(t *testing.T) {
+	oldPools := consoleNodePools
+	defer func() { consoleNodePools = oldPools }()
+	consoleNodePools = []consoleNodePool{
+		{Namespace: "services", Kind: "StatefulSet", Name: "cray-console-node", Weight: 1},
+		{Namespace: "tenant-a", Kind: "StatefulSet", Name: "console-node-a", Weight: 1},
+	}
+
+	setPodNamespaces("tenant-a", []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "console-node-a-0"}}})
+	if ns := podNamespace("console-node-a-0"); ns != "tenant-a" {
+		t.Errorf("Expected: tenant-a. Got: %s.", ns)
+	}
+	if ns := podNamespace("cray-console-node-0"); ns != "services" {
+		t.Errorf("Expected the first pool namespace. Got: %s.", ns)
+	}
+}
@@ -0,0 +1,65 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to serve the go runtime profiles for looking
+//  into cpu and memory problems on a live system
+
+package main
+
+import (
+	"log"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Serve the runtime profiles on the admin socket
+// NOTE: the profiles are never served on the service port, so this does
+// nothing unless ADMIN_SOCKET is set too
+var pprofEnabled bool = false
+
+// Put the profiling routes on the admin router if profiling is enabled
+func setupProfilingRoutes(admin chi.Router) {
+	if !pprofEnabled {
+		return
+	}
+	if adminSocket == "" {
+		log.Printf("Not serving the runtime profiles, PPROF needs ADMIN_SOCKET to be set")
+		return
+	}
+	log.Printf("Serving the runtime profiles on %s", adminSocket)
+	admin.Get("/console-operator/debug/pprof/", pprof.Index)
+	admin.Get("/console-operator/debug/pprof/cmdline", pprof.Cmdline)
+	admin.Get("/console-operator/debug/pprof/profile", pprof.Profile)
+	admin.Get("/console-operator/debug/pprof/symbol", pprof.Symbol)
+	admin.Get("/console-operator/debug/pprof/trace", pprof.Trace)
+	admin.Get("/console-operator/debug/pprof/{profile}", doGetProfile)
+}
+
+// Serve one of the named profiles - heap, goroutine, allocs, block, mutex
+// or threadcreate
+func doGetProfile(w http.ResponseWriter, r *http.Request) {
+	pprof.Handler(chi.URLParam(r, "profile")).ServeHTTP(w, r)
+}
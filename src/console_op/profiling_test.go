@@ -0,0 +1,62 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestProfilingRoutes(t *testing.T) {
+	defer func(e bool, s string) { pprofEnabled, adminSocket = e, s }(pprofEnabled, adminSocket)
+	get := func(r chi.Router, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	// not served without the admin socket
+	pprofEnabled, adminSocket = true, ""
+	r := chi.NewRouter()
+	setupProfilingRoutes(r)
+	if w := get(r, "/console-operator/debug/pprof/heap"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected: %d. Got: %d.", http.StatusNotFound, w.Code)
+	}
+
+	pprofEnabled, adminSocket = true, "/tmp/admin.sock"
+	r = chi.NewRouter()
+	setupProfilingRoutes(r)
+	if w := get(r, "/console-operator/debug/pprof/goroutine?debug=1"); w.Code != http.StatusOK ||
+		!strings.Contains(w.Body.String(), "goroutine profile") {
+		t.Errorf("Expected the goroutine profile, got: %d %s", w.Code, w.Body.String())
+	}
+	if w := get(r, "/console-operator/debug/pprof/"); w.Code != http.StatusOK {
+		t.Errorf("Expected: %d. Got: %d.", http.StatusOK, w.Code)
+	}
+}
@@ -0,0 +1,148 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to mask secrets that show up on consoles
+//  before console output is handed out by the operator
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+)
+
+// A redaction rule as configured, matches of the regex are replaced with
+// the mask
+type redactionRule struct {
+	Regex string `json:"regex"`
+	Mask  string `json:"mask"`
+}
+
+// A configured rule ready to use
+type compiledRedactionRule struct {
+	rule  redactionRule
+	regex *regexp.Regexp
+}
+
+// The redaction rules in the order they are applied
+var redactionRules []compiledRedactionRule = nil
+
+// Mask used when a rule does not give one
+const defaultRedactionMask string = "********"
+
+// Read the redaction rules from the environment as a json list of
+// {"regex": "...", "mask": "..."}
+func readRedactionRules() {
+	registerSetting("REDACTION_RULES", func() string {
+		rules := []redactionRule{}
+		for _, cr := range redactionRules {
+			rules = append(rules, cr.rule)
+		}
+		data, _ := json.Marshal(rules)
+		return string(data)
+	}, ConfigSetting{})
+	v := os.Getenv("REDACTION_RULES")
+	if v == "" {
+		return
+	}
+	var rules []redactionRule
+	if err := json.Unmarshal([]byte(v), &rules); err != nil {
+		log.Printf("Unable to parse REDACTION_RULES, no redaction will be done: %s", err)
+		return
+	}
+	redactionRules = compileRedactionRules(rules)
+	log.Printf("Using %d console output redaction rules", len(redactionRules))
+}
+
+// Compile the redaction rules, skipping the ones that are not valid
+func compileRedactionRules(rules []redactionRule) []compiledRedactionRule {
+	var compiled []compiledRedactionRule = nil
+	for _, r := range rules {
+		re, err := regexp.Compile(r.Regex)
+		if err != nil {
+			log.Printf("Skipping invalid redaction rule %s: %s", r.Regex, err)
+			continue
+		}
+		if r.Mask == "" {
+			r.Mask = defaultRedactionMask
+		}
+		compiled = append(compiled, compiledRedactionRule{rule: r, regex: re})
+	}
+	return compiled
+}
+
+// Apply the redaction rules to a line of console output
+func redactLine(line string) string {
+	for _, r := range redactionRules {
+		line = r.regex.ReplaceAllString(line, r.rule.Mask)
+	}
+	return line
+}
+
+// Copy console output applying the redaction rules to each line
+func redactCopy(dst io.Writer, src io.Reader) (int64, error) {
+	if len(redactionRules) == 0 {
+		return io.Copy(dst, src)
+	}
+	var n int64 = 0
+	br := bufio.NewReader(src)
+	for {
+		line, err := br.ReadString('\n')
+		if len(line) > 0 {
+			wn, werr := io.WriteString(dst, redactLine(line))
+			n += int64(wn)
+			if werr != nil {
+				return n, werr
+			}
+		}
+		if err == io.EOF {
+			return n, nil
+		} else if err != nil {
+			return n, err
+		}
+	}
+}
+
+// Report the redaction rules in use
+func (LogManager) doGetRedactionRules(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	rules := []redactionRule{}
+	for _, cr := range redactionRules {
+		rules = append(rules, cr.rule)
+	}
+	SendResponseJSON(w, http.StatusOK, rules)
+}
@@ -0,0 +1,60 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRedaction(t *testing.T) {
+	defer func() { redactionRules = nil }()
+	redactionRules = compileRedactionRules([]redactionRule{
+		{Regex: `(?i)(password:\s*)\S+`, Mask: "${1}XXXX"},
+		{Regex: `token=[A-Za-z0-9]+`},
+		{Regex: `(invalid`},
+	})
+	if len(redactionRules) != 2 {
+		t.Errorf("Expected: 2. Got: %d.", len(redactionRules))
+	}
+	if got := redactLine("New Password: hunter2"); got != "New Password: XXXX" {
+		t.Errorf("Expected: New Password: XXXX. Got: %s.", got)
+	}
+
+	var out bytes.Buffer
+	in := "curl ?token=abc123\nplain\nno newline token=zzz"
+	if _, err := redactCopy(&out, strings.NewReader(in)); err != nil {
+		t.Fatal(err)
+	}
+	expected := "curl ?" + defaultRedactionMask + "\nplain\nno newline " + defaultRedactionMask
+	if out.String() != expected {
+		t.Errorf("Expected: %s. Got: %s.", expected, out.String())
+	}
+
+	if vc := vectorLogConfig("/var/log/conman", ""); !strings.Contains(vc, "token=[A-Za-z0-9]+") {
+		t.Errorf("Expected the redaction rules in the vector config: %s", vc)
+	}
+}
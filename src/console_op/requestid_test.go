@@ -0,0 +1,77 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestIDs(t *testing.T) {
+	var seen string
+	h := requestIDs(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = getRequestID(r)
+	}))
+
+	// the caller's id is kept
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/console-operator/info", nil)
+	req.Header.Set(requestIDHeader, "abc123")
+	h.ServeHTTP(w, req)
+	if seen != "abc123" || w.Header().Get(requestIDHeader) != "abc123" {
+		t.Errorf("Expected: abc123. Got: %s %s.", seen, w.Header().Get(requestIDHeader))
+	}
+
+	// a new id is made when the caller did not send one
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/console-operator/info", nil))
+	if len(seen) != 32 || w.Header().Get(requestIDHeader) != seen {
+		t.Errorf("Expected a new request id, got: %s", seen)
+	}
+}
+
+func TestRequestIDForwarded(t *testing.T) {
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(requestIDHeader)
+	}))
+	defer ts.Close()
+
+	if _, _, err := getURL(ts.URL, requestIDHeaders("abc123")); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if got != "abc123" {
+		t.Errorf("Expected: abc123. Got: %s.", got)
+	}
+
+	// calls made outside of a request still get an id
+	if _, _, err := postURL(ts.URL, nil, nil); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(got) != 32 {
+		t.Errorf("Expected a new request id, got: %s", got)
+	}
+}
@@ -0,0 +1,401 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to restart the console-node pods one at a
+//  time, moving the consoles of each pod to its peers before it goes away
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Time allowed for a pod to hand off its consoles, come back and have its
+// consoles picked up again
+var rollingRestartPodTimeout time.Duration = 10 * time.Minute
+
+// How often to check on the restarted pod
+var rollingRestartPollPeriod time.Duration = 5 * time.Second
+
+// RollingRestartPod - the restart of one console-node pod
+type RollingRestartPod struct {
+	PodName  string `json:"podname"`
+	Status   string `json:"status"` // pending, draining, restarting, done or failed
+	Consoles int    `json:"consoles"`
+	Error    string `json:"error,omitempty"`
+}
+
+// RollingRestartStatus - the current or last rolling restart
+type RollingRestartStatus struct {
+	Running   bool                `json:"running"`
+	Started   string              `json:"started"`
+	Completed string              `json:"completed"`
+	Pods      []RollingRestartPod `json:"pods"`
+}
+
+var rollingRestart RollingRestartStatus = RollingRestartStatus{Pods: []RollingRestartPod{}}
+var rollingRestartMutex sync.Mutex
+
+// Rolling restarts asked for, run by the leader
+var rollingRestartRequests = make(chan []string, 1)
+
+// Pod held at no consoles while it is restarted
+// NOTE: this goes through the per pod target files so needs a console-node
+// that reads them, an older pod keeps its consoles and the restart times out
+// before the pod is deleted
+var cordonedPod string = ""
+
+// Get the xnames of the consoles held by a pod
+func podConsoles(inv []dataNodeInfo, podName string) []string {
+	var xnames []string = nil
+	for _, n := range inv {
+		if n.NodeConsoleName != "" && fmt.Sprintf("%s-%s", consoleNodeName, n.NodeConsoleName) == podName {
+			xnames = append(xnames, n.NodeName)
+		}
+	}
+	sort.Strings(xnames)
+	return xnames
+}
+
+// Check if all the given consoles have been picked up by a pod
+func consolesAssigned(inv []dataNodeInfo, xnames []string) bool {
+	assigned := make(map[string]bool, len(inv))
+	for _, n := range inv {
+		assigned[n.NodeName] = n.NodeConsoleName != ""
+	}
+	for _, xname := range xnames {
+		if !assigned[xname] {
+			return false
+		}
+	}
+	return true
+}
+
+// Get the pod held at no consoles, empty if there is none
+func getCordonedPod() string {
+	rollingRestartMutex.Lock()
+	defer rollingRestartMutex.Unlock()
+	return cordonedPod
+}
+
+// Hold a pod at no consoles and spread its share over the other pods
+func cordonPodTargets(targets []podTarget, pod string) []podTarget {
+	if pod == "" {
+		return targets
+	}
+	var mtn, rvr, numPeers int
+	found := false
+	for _, pt := range targets {
+		if pt.PodName == pod {
+			mtn, rvr = pt.TargetNumMtnNodes, pt.TargetNumRvrNodes
+			found = true
+		} else {
+			numPeers++
+		}
+	}
+	cordoned := make([]podTarget, 0, len(targets)+1)
+	for _, pt := range targets {
+		if pt.PodName == pod {
+			pt.TargetNumMtnNodes, pt.TargetNumRvrNodes = 0, 0
+		} else if numPeers > 0 {
+			pt.TargetNumMtnNodes += (mtn + numPeers - 1) / numPeers
+			pt.TargetNumRvrNodes += (rvr + numPeers - 1) / numPeers
+		}
+		cordoned = append(cordoned, pt)
+	}
+	if !found {
+		cordoned = append(cordoned, podTarget{PodName: pod})
+	}
+	return cordoned
+}
+
+// Targets of the pods as they are without a restart
+func currentPodTargets(pods []string) []podTarget {
+	if capacityDistribution {
+		if targets := getPodTargets(); len(targets) > 0 {
+			return targets
+		}
+	}
+	targets := make([]podTarget, 0, len(pods))
+	for _, pod := range pods {
+		targets = append(targets, podTarget{PodName: pod,
+			TargetNumMtnNodes: numMtnNodesPerPod, TargetNumRvrNodes: numRvrNodesPerPod})
+	}
+	return targets
+}
+
+// Hold a pod at no consoles through the per pod target files
+func (dm DataManager) cordonPod(pod string, pods []string) {
+	rollingRestartMutex.Lock()
+	cordonedPod = pod
+	rollingRestartMutex.Unlock()
+	log.Printf("Cordoning %s", pod)
+	dm.k8Service.updatePodTargets(cordonPodTargets(currentPodTargets(pods), pod))
+}
+
+// Put the pod targets back the way they were before the cordon
+// NOTE: without capacity distribution the per pod files are removed so the
+// pods go back to the shared target file
+func (dm DataManager) uncordonPod(pod string) {
+	rollingRestartMutex.Lock()
+	cordonedPod = ""
+	rollingRestartMutex.Unlock()
+	log.Printf("Uncordoning %s", pod)
+	var targets []podTarget = nil
+	if capacityDistribution {
+		targets = getPodTargets()
+	}
+	dm.k8Service.updatePodTargets(targets)
+}
+
+// Record the progress of the restart of a pod
+func setRollingRestartPod(i int, status string, consoles int, err error) {
+	rollingRestartMutex.Lock()
+	defer rollingRestartMutex.Unlock()
+	rollingRestart.Pods[i].Status = status
+	rollingRestart.Pods[i].Consoles = consoles
+	if err != nil {
+		rollingRestart.Pods[i].Error = err.Error()
+	}
+}
+
+// Loop on the leader to run the rolling restarts that are asked for
+func (dm DataManager) watchRollingRestarts(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Stopping rolling restart watch")
+			return
+		case pods := <-rollingRestartRequests:
+			dm.runRollingRestart(ctx, pods)
+		}
+	}
+}
+
+// Restart the pods one at a time, stopping at the first one that fails
+func (dm DataManager) runRollingRestart(ctx context.Context, pods []string) {
+	defer func() {
+		rollingRestartMutex.Lock()
+		rollingRestart.Running = false
+		rollingRestart.Completed = formatTime(time.Now())
+		rollingRestartMutex.Unlock()
+	}()
+	for i, pod := range pods {
+		if ctx.Err() != nil || reconcileStopped() {
+			log.Printf("Reconciling stopped, ending the rolling restart")
+			return
+		}
+		consoles, err := dm.restartConsoleNodePod(ctx, i, pod, pods)
+		if err != nil {
+			logError(errK8s, "Rolling restart of %s failed: %s", pod, err)
+			setRollingRestartPod(i, "failed", consoles, err)
+			recordEvent(eventOnConsoleNode, corev1.EventTypeWarning, "RollingRestartFailed",
+				fmt.Sprintf("Rolling restart stopped at %s: %s", pod, err))
+			return
+		}
+		setRollingRestartPod(i, "done", consoles, nil)
+	}
+	recordEvent(eventOnConsoleNode, corev1.EventTypeNormal, "RollingRestartDone",
+		fmt.Sprintf("Restarted %d console-node pods", len(pods)))
+}
+
+// Poll until the check passes, returning false if the time runs out or the
+// restart is stopped
+func pollRollingRestart(ctx context.Context, deadline time.Time, check func() bool) bool {
+	for time.Now().Before(deadline) {
+		if !sleepCtx(ctx, rollingRestartPollPeriod) {
+			return false
+		}
+		if check() {
+			return true
+		}
+	}
+	return false
+}
+
+// Cordon a pod, move its consoles to its peers, restart it, and wait for the
+// new pod to be ready and the consoles to be picked up again
+// NOTE: the consoles are released in console-data so the other pods pick
+// them up right away instead of after the heartbeat of this pod goes stale,
+// and the pod is only deleted once console-data shows it holds none
+func (dm DataManager) restartConsoleNodePod(ctx context.Context, i int, pod string, pods []string) (int, error) {
+	deadline := time.Now().Add(rollingRestartPodTimeout)
+	oldUID, _, err := dm.k8Service.getConsoleNodePodState(pod)
+	if err != nil {
+		return 0, err
+	}
+	inv, err := getDataInventory("")
+	if err != nil {
+		return 0, err
+	}
+	xnames := podConsoles(inv, pod)
+	var nodes []nodeConsoleInfo = nil
+	// NOTE - not thread safe, but should be ok
+	for _, xname := range xnames {
+		if n, found := nodeCache[xname]; found {
+			nodes = append(nodes, n)
+		}
+	}
+
+	// hold the pod at no consoles so it does not take back the released ones
+	setRollingRestartPod(i, "draining", len(nodes), nil)
+	dm.cordonPod(pod, pods)
+	cordoned := true
+	defer func() {
+		if cordoned {
+			dm.uncordonPod(pod)
+		}
+	}()
+	log.Printf("Rolling restart of %s, moving %d consoles to the other pods", pod, len(nodes))
+	if len(nodes) > 0 {
+		dm.dataRemoveNodes(nodes)
+		if !dm.dataAddNodes(nodes) {
+			return len(nodes), fmt.Errorf("Unable to release the consoles of %s in console-data", pod)
+		}
+	}
+	drained := func() bool {
+		inv, err := getDataInventory("")
+		return err == nil && len(podConsoles(inv, pod)) == 0
+	}
+	if !drained() && !pollRollingRestart(ctx, deadline, drained) {
+		if ctx.Err() != nil {
+			return len(nodes), fmt.Errorf("Rolling restart stopped while draining %s", pod)
+		}
+		return len(nodes), fmt.Errorf("Timed out after %s waiting for console-data to show no consoles on %s",
+			rollingRestartPodTimeout, pod)
+	}
+
+	setRollingRestartPod(i, "restarting", len(nodes), nil)
+	if err = dm.k8Service.deleteConsoleNodePod(pod); err != nil {
+		return len(nodes), err
+	}
+
+	// lift the cordon once the replacement pod exists so it takes consoles
+	replaced := func() bool {
+		uid, _, err := dm.k8Service.getConsoleNodePodState(pod)
+		return err == nil && uid != "" && uid != oldUID
+	}
+	if !pollRollingRestart(ctx, deadline, replaced) {
+		if ctx.Err() != nil {
+			return len(nodes), fmt.Errorf("Rolling restart stopped while restarting %s", pod)
+		}
+		return len(nodes), fmt.Errorf("Timed out after %s waiting for %s to come back", rollingRestartPodTimeout, pod)
+	}
+	dm.uncordonPod(pod)
+	cordoned = false
+
+	ready := func() bool {
+		_, ready, err := dm.k8Service.getConsoleNodePodState(pod)
+		if err != nil || !ready {
+			return false
+		}
+		inv, err := getDataInventory("")
+		return err == nil && consolesAssigned(inv, xnames)
+	}
+	if !ready() && !pollRollingRestart(ctx, deadline, ready) {
+		if ctx.Err() != nil {
+			return len(nodes), fmt.Errorf("Rolling restart stopped while waiting for %s", pod)
+		}
+		return len(nodes), fmt.Errorf("Timed out after %s waiting for %s to be ready and its consoles to be picked up",
+			rollingRestartPodTimeout, pod)
+	}
+	log.Printf("Rolling restart of %s done", pod)
+	return len(nodes), nil
+}
+
+// Get a copy of the rolling restart status
+func getRollingRestart() RollingRestartStatus {
+	rollingRestartMutex.Lock()
+	defer rollingRestartMutex.Unlock()
+	status := rollingRestart
+	status.Pods = append([]RollingRestartPod{}, rollingRestart.Pods...)
+	return status
+}
+
+// Start a rolling restart of the console-node pods
+// NOTE: the restart itself runs on the leader loop so it stops with it
+func (dm DataManager) doRollingRestart(w http.ResponseWriter, r *http.Request) {
+	// only allow 'POST' calls
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+	if reconcileStopped() {
+		sendJSONError(w, http.StatusServiceUnavailable, "Reconciling is stopped on this replica")
+		return
+	}
+
+	pods, err := dm.k8Service.getConsoleNodePods()
+	if err != nil {
+		sendJSONError(w, http.StatusInternalServerError,
+			fmt.Sprintf("Unable to get the console-node pods: %s", err))
+		return
+	}
+	sort.Strings(pods)
+
+	rollingRestartMutex.Lock()
+	if rollingRestart.Running {
+		rollingRestartMutex.Unlock()
+		sendJSONError(w, http.StatusConflict, "A rolling restart is already running")
+		return
+	}
+	select {
+	case rollingRestartRequests <- pods:
+	default:
+		rollingRestartMutex.Unlock()
+		sendJSONError(w, http.StatusConflict, "A rolling restart is already waiting to start")
+		return
+	}
+	rollingRestart = RollingRestartStatus{Running: true, Started: formatTime(time.Now()), Pods: []RollingRestartPod{}}
+	for _, pod := range pods {
+		rollingRestart.Pods = append(rollingRestart.Pods, RollingRestartPod{PodName: pod, Status: "pending"})
+	}
+	rollingRestartMutex.Unlock()
+
+	log.Printf("Starting a rolling restart of %d console-node pods", len(pods))
+	SendResponseJSON(w, http.StatusAccepted, getRollingRestart())
+}
+
+// Report the current or last rolling restart
+func (DataManager) doGetRollingRestart(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	SendResponseJSON(w, http.StatusOK, getRollingRestart())
+}
@@ -0,0 +1,223 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPodConsoles(t *testing.T) {
+	inv := []dataNodeInfo{
+		{NodeName: "x3000c0s19b0n0", NodeConsoleName: "1"},
+		{NodeName: "x3000c0s17b0n0", NodeConsoleName: "0"},
+		{NodeName: "x3000c0s15b0n0", NodeConsoleName: "1"},
+		{NodeName: "x3000c0s13b0n0", NodeConsoleName: ""},
+	}
+	xnames := podConsoles(inv, "cray-console-node-1")
+	if len(xnames) != 2 || xnames[0] != "x3000c0s15b0n0" || xnames[1] != "x3000c0s19b0n0" {
+		t.Errorf("Unexpected consoles: %v", xnames)
+	}
+	if !consolesAssigned(inv, xnames) {
+		t.Errorf("Expected the consoles to be assigned")
+	}
+	if consolesAssigned(inv, []string{"x3000c0s17b0n0", "x3000c0s13b0n0"}) {
+		t.Errorf("Expected x3000c0s13b0n0 to be unassigned")
+	}
+	if consolesAssigned(inv, []string{"x9000c0s0b0n0"}) {
+		t.Errorf("Expected an unknown node to be unassigned")
+	}
+}
+
+type K8RollingRestartMock struct {
+	// embed this so only mock methods as needed
+	K8Manager
+}
+
+func (K8RollingRestartMock) getConsoleNodePods() (podNames []string, err error) {
+	return []string{"cray-console-node-1", "cray-console-node-0"}, nil
+}
+
+func TestCordonPodTargets(t *testing.T) {
+	targets := []podTarget{
+		{PodName: "cray-console-node-0", TargetNumMtnNodes: 5, TargetNumRvrNodes: 10},
+		{PodName: "cray-console-node-1", TargetNumMtnNodes: 5, TargetNumRvrNodes: 10},
+		{PodName: "cray-console-node-2", TargetNumMtnNodes: 5, TargetNumRvrNodes: 10},
+	}
+	cordoned := cordonPodTargets(targets, "cray-console-node-1")
+	expected := []podTarget{
+		{PodName: "cray-console-node-0", TargetNumMtnNodes: 8, TargetNumRvrNodes: 15},
+		{PodName: "cray-console-node-1", TargetNumMtnNodes: 0, TargetNumRvrNodes: 0},
+		{PodName: "cray-console-node-2", TargetNumMtnNodes: 8, TargetNumRvrNodes: 15},
+	}
+	for i := range expected {
+		if cordoned[i] != expected[i] {
+			t.Errorf("Expected: %v. Got: %v.", expected[i], cordoned[i])
+		}
+	}
+	if targets[1].TargetNumMtnNodes != 5 {
+		t.Errorf("Expected the original targets to be left alone")
+	}
+
+	// no cordon leaves the targets as they are, an unknown pod is added at zero
+	if got := cordonPodTargets(targets, ""); len(got) != 3 || got[1] != targets[1] {
+		t.Errorf("Expected the targets unchanged. Got: %v", got)
+	}
+	if got := cordonPodTargets(targets, "cray-console-node-3"); len(got) != 4 || got[3] != (podTarget{PodName: "cray-console-node-3"}) {
+		t.Errorf("Expected the unknown pod held at zero. Got: %v", got)
+	}
+}
+
+// Mock of a console-node pod that is replaced when deleted
+type K8RestartPodMock struct {
+	K8Manager
+	mu      *sync.Mutex
+	uid     *string
+	targets *[][]podTarget
+}
+
+func (m K8RestartPodMock) getConsoleNodePodState(podName string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return *m.uid, true, nil
+}
+
+func (m K8RestartPodMock) deleteConsoleNodePod(podName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	*m.uid = "new"
+	return nil
+}
+
+func (m K8RestartPodMock) updatePodTargets(targets []podTarget) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	*m.targets = append(*m.targets, targets)
+}
+
+func TestRestartConsoleNodePod(t *testing.T) {
+	// console-data hands the released consoles to the other pod
+	var dataMutex sync.Mutex
+	assigned := map[string]string{"x3000c0s19b0n0": "1", "x3000c0s17b0n0": "0"}
+	data := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dataMutex.Lock()
+		defer dataMutex.Unlock()
+		switch r.Method {
+		case http.MethodGet:
+			var inv []dataNodeInfo
+			for xname, pod := range assigned {
+				inv = append(inv, dataNodeInfo{NodeName: xname, NodeConsoleName: pod})
+			}
+			json.NewEncoder(w).Encode(inv)
+		case http.MethodPut:
+			var nodes []nodeConsoleInfo
+			json.NewDecoder(r.Body).Decode(&nodes)
+			for _, n := range nodes {
+				assigned[n.NodeName] = "0"
+			}
+			w.Write([]byte("{}"))
+		default:
+			w.Write([]byte("{}"))
+		}
+	}))
+	defer data.Close()
+
+	oldAddr, oldPoll, oldCache := dataAddrBase, rollingRestartPollPeriod, nodeCache
+	oldMtn, oldRvr := numMtnNodesPerPod, numRvrNodesPerPod
+	defer func() {
+		dataAddrBase, rollingRestartPollPeriod, nodeCache = oldAddr, oldPoll, oldCache
+		numMtnNodesPerPod, numRvrNodesPerPod = oldMtn, oldRvr
+		rollingRestart = RollingRestartStatus{Pods: []RollingRestartPod{}}
+	}()
+	dataAddrBase = data.URL
+	rollingRestartPollPeriod = time.Millisecond
+	nodeCache = map[string]nodeConsoleInfo{"x3000c0s19b0n0": {NodeName: "x3000c0s19b0n0", Class: "River"}}
+	numMtnNodesPerPod, numRvrNodesPerPod = 2, 4
+	rollingRestart = RollingRestartStatus{Pods: []RollingRestartPod{{PodName: "cray-console-node-1"}}}
+
+	var mu sync.Mutex
+	uid := "old"
+	var targets [][]podTarget
+	dm := DataManager{k8Service: K8RestartPodMock{mu: &mu, uid: &uid, targets: &targets}}
+	pods := []string{"cray-console-node-0", "cray-console-node-1"}
+	consoles, err := dm.restartConsoleNodePod(context.Background(), 0, "cray-console-node-1", pods)
+	if err != nil || consoles != 1 {
+		t.Fatalf("Expected 1 console moved without an error. Got: %d, %v", consoles, err)
+	}
+	if uid != "new" {
+		t.Errorf("Expected the pod to be replaced")
+	}
+
+	// cordoned first, then put back to the shared targets
+	if len(targets) != 2 {
+		t.Fatalf("Expected a cordon and an uncordon. Got: %v", targets)
+	}
+	if targets[0][1] != (podTarget{PodName: "cray-console-node-1"}) || targets[0][0].TargetNumRvrNodes != 8 {
+		t.Errorf("Unexpected cordon targets: %v", targets[0])
+	}
+	if targets[1] != nil || getCordonedPod() != "" {
+		t.Errorf("Expected the cordon to be lifted. Got: %v", targets[1])
+	}
+}
+
+func TestRunRollingRestartStopped(t *testing.T) {
+	defer func() { rollingRestart = RollingRestartStatus{Pods: []RollingRestartPod{}} }()
+	rollingRestart = RollingRestartStatus{Running: true, Pods: []RollingRestartPod{{PodName: "cray-console-node-0", Status: "pending"}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// nothing is restarted once the leader loop is stopped
+	DataManager{k8Service: K8RollingRestartMock{}}.runRollingRestart(ctx, []string{"cray-console-node-0"})
+	if st := getRollingRestart(); st.Running || st.Pods[0].Status != "pending" {
+		t.Errorf("Expected the restart to end without touching the pod. Got: %v", st)
+	}
+}
+
+func TestRollingRestartConflict(t *testing.T) {
+	defer func() { rollingRestart = RollingRestartStatus{Pods: []RollingRestartPod{}} }()
+	rollingRestart = RollingRestartStatus{Running: true, Pods: []RollingRestartPod{{PodName: "cray-console-node-0", Status: "restarting"}}}
+	dm := DataManager{k8Service: K8RollingRestartMock{}}
+
+	// only one rolling restart at a time
+	w := httptest.NewRecorder()
+	dm.doRollingRestart(w, httptest.NewRequest(http.MethodPost, "/console-operator/node-pods/rolling-restart", nil))
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected: %d. Got: %d.", http.StatusConflict, w.Code)
+	}
+	if st := getRollingRestart(); !st.Running || len(st.Pods) != 1 || st.Pods[0].Status != "restarting" {
+		t.Errorf("Expected the running restart to be kept, got: %v", st)
+	}
+
+	w = httptest.NewRecorder()
+	dm.doGetRollingRestart(w, httptest.NewRequest(http.MethodPost, "/console-operator/node-pods/rolling-restart", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected: %d. Got: %d.", http.StatusMethodNotAllowed, w.Code)
+	}
+}
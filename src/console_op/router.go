@@ -1,7 +1,7 @@
 //
 //  MIT License
 //
-//  (C) Copyright 2023 Hewlett Packard Enterprise Development LP
+//  (C) Copyright 2023, 2026 Hewlett Packard Enterprise Development LP
 //
 //  Permission is hereby granted, free of charge, to any person obtaining a
 //  copy of this software and associated documentation files (the "Software"),
@@ -36,22 +36,87 @@ import (
 
 var router = chi.NewRouter()
 
-func setupRoutes(ds DataService, hs HealthService, dbs DebugService) {
+func setupRoutes(ds DataService, hs HealthService, dbs DebugService, ss SessionService, fs FreezeService, sts StateService, ts TenantService, ks KeyService, ls LogService) {
+	router.Use(requestIDs)
+	router.Use(gzipResponses)
+	router.Use(corsPolicy)
+	// a standby replica only serves reads
+	router.Use(leaderOnlyWrites)
+
 	// k8s routes
 	router.Get("/console-operator/liveness", hs.doLiveness)
 	router.Get("/console-operator/readiness", hs.doReadiness)
 	router.Get("/console-operator/health", hs.doHealth)
+	router.Get("/console-operator/status", hs.doStatus)
+	router.Get("/console-operator/config", doGetConfig)
+	router.Get("/console-operator/errorcodes", doGetErrorCodes)
+	router.Get("/console-operator/metrics", ls.doGetMetrics)
 
 	// debug only routes
-	router.Get("/console-operator/info", dbs.doInfo)
-	router.Delete("/console-operator/clearData", dbs.doClearData)
-	router.Post("/console-operator/suspend", dbs.doSuspend)
-	router.Post("/console-operator/resume", dbs.doResume)
-	router.Patch("/console-operator/v0/setMaxNodesPerPod", dbs.doSetMaxNodesPerPod)
+	// NOTE: these move to the admin socket when it is configured
+	admin := getAdminRouter()
+	if adminSocket != "" {
+		admin.Use(requestIDs)
+		admin.Use(leaderOnlyWrites)
+	}
+	admin.Get("/console-operator/info", dbs.doInfo)
+	admin.Delete("/console-operator/clearData", dbs.doClearData)
+	admin.Post("/console-operator/suspend", dbs.doSuspend)
+	admin.Post("/console-operator/resume", dbs.doResume)
+	admin.Get("/console-operator/zombies", dbs.doZombies)
+	admin.Get("/console-operator/dump", dbs.doStateDump)
+	setupProfilingRoutes(admin)
+	admin.Patch("/console-operator/v0/setMaxNodesPerPod", dbs.doSetMaxNodesPerPod)
 	router.Get("/console-operator/v0/getNodePod", ds.doGetNodePod)
 
+	// console-node pods
+	router.Get("/console-operator/node-pods/rolling-restart", ds.doGetRollingRestart)
+	router.Post("/console-operator/node-pods/rolling-restart", ds.doRollingRestart)
+
+	// node lookups
+	router.Get("/console-operator/nodes/{xname}/tenants", ts.doGetNodeTenants)
+	router.Get("/console-operator/nodes/{xname}/events", ls.doGetNodeEvents)
+
 	// v1
-	router.Get("/console-operator/v1/location/{podID}", ds.doGetPodLocation)
+	router.With(ts.tenantNodeAccess).Get("/console-operator/v1/location/{podID}", ds.doGetPodLocation)
 	router.Get("/console-operator/v1/replicas", ds.doGetPodReplicaCount)
 	router.Get("/console-operator/v1/currentTargets", ds.doGetCurrentTargets)
+	router.Get("/console-operator/v1/podAssignments", ds.doGetPodAssignments)
+	router.Get("/console-operator/v1/podAssignments/{podID}", ds.doGetPodAssignments)
+	router.Get("/console-operator/v1/sessions", ss.doGetSessions)
+	router.Get("/console-operator/v1/firmware", doGetFirmwareMaintenance)
+	router.Get("/console-operator/v1/freeze", fs.doGetFreeze)
+	router.Post("/console-operator/v1/freeze", fs.doSetFreeze)
+	router.Delete("/console-operator/v1/freeze", fs.doClearFreeze)
+	router.Get("/console-operator/v1/maintenance", fs.doGetNodeMaintenance)
+	router.Post("/console-operator/v1/maintenance", fs.doAddNodeMaintenance)
+	router.Delete("/console-operator/v1/maintenance/{id}", fs.doDeleteNodeMaintenance)
+	router.Get("/console-operator/v1/state", sts.doExportState)
+	router.Put("/console-operator/v1/state", sts.doImportState)
+	router.Post("/console-operator/v1/handoff", sts.doHandoff)
+	router.Delete("/console-operator/v1/handoff", sts.doReleaseHandoff)
+	router.Get("/console-operator/v1/keys/rotation", ks.doGetKeyRotation)
+	router.Post("/console-operator/v1/keys/rotation", ks.doRotateKey)
+	router.Get("/console-operator/v1/keys/status", ks.doGetKeyStatus)
+	router.Post("/console-operator/v1/keys/retry", ks.doRetryFailedKeys)
+	router.Get("/console-operator/v1/logs/locations", ls.doGetLogLocations)
+	router.Get("/console-operator/v1/logs/rotations", ls.doGetLogRotations)
+	router.Get("/console-operator/v1/logs/shipper", ls.doGetLogShipperConfig)
+	router.Get("/console-operator/v1/logs/quota", ls.doGetLogQuota)
+	router.Get("/console-operator/v1/logs/silent", ls.doGetSilentConsoles)
+	router.Get("/console-operator/v1/logs/redaction", ls.doGetRedactionRules)
+	router.Get("/console-operator/v1/logs/compression", ls.doGetLogCompression)
+	router.Post("/console-operator/v1/logs/compression", ls.doCompressLogs)
+	router.Get("/console-operator/v1/logs/rates", ls.doGetOutputRates)
+	// a caller acting for a tenant only reaches the logs of its own nodes
+	router.Group(func(r chi.Router) {
+		r.Use(ts.tenantNodeAccess)
+		r.Get("/console-operator/v1/logs/{xname}/manifest", ls.doGetLogManifest)
+		r.Get("/console-operator/v1/logs/{xname}/archive", ls.doGetLogArchive)
+		r.Get("/console-operator/v1/logs/{xname}/export", ls.doExportLog)
+		r.Get("/console-operator/v1/logs/{xname}/tail", ls.doTailLog)
+		r.Get("/console-operator/v1/logs/{xname}/snapshots", ls.doGetCrashSnapshots)
+		r.Get("/console-operator/v1/logs/{xname}/snapshots/{time}", ls.doGetCrashSnapshot)
+	})
+	router.Get(federationPrefix+"/{system}/*", ts.doFederationProxy)
 }
@@ -0,0 +1,302 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code needed to find stuck or duplicated console
+//  sessions running inside the console-node pods
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Global vars to control checking the console-node pods for stuck sessions
+var sessionCheckPeriodSec int = 300
+var sessionStuckMinutes int = 10
+var cleanupStuckSessions bool = false
+
+// Name of the container in the console-node pods running the consoles
+var consoleNodeContainer string = "cray-console-node"
+
+// The console session processes that are checked and if more than one for
+// the same xname is a problem.  Several users may legitimately be attached
+// with the conman client, but there should only be one connection to a bmc.
+// NOTE: conmand itself is not included since it is expected to be long
+// running and is not tied to an xname.
+var sessionCommands = map[string]bool{
+	"conman":   false,
+	"ipmitool": true,
+	"ssh":      true,
+}
+
+// Pattern to find the node or bmc xname a session process is connected to
+var sessionXnameRegex = regexp.MustCompile(`x[0-9]+c[0-9]+s[0-9]+b[0-9]+(n[0-9]+)?`)
+
+type SessionService interface {
+	watchConsoleSessions(ctx context.Context)
+	doGetSessions(w http.ResponseWriter, r *http.Request)
+}
+
+// Implements SessionService
+type SessionManager struct {
+	k8Service K8Service
+}
+
+// Constructor injection for dependencies
+func NewSessionManager(k8s K8Service) SessionService {
+	return &SessionManager{k8Service: k8s}
+}
+
+// Struct to hold a console session process with a problem
+type sessionProblem struct {
+	PodName    string `json:"podname"`
+	Xname      string `json:"xname"`
+	Pid        int    `json:"pid"`
+	Command    string `json:"command"`
+	State      string `json:"state"`
+	ElapsedSec int    `json:"elapsedsec"`
+	StuckSec   int    `json:"stucksec,omitempty"`
+	Problem    string `json:"problem"`
+	Cleaned    bool   `json:"cleaned"`
+}
+
+// Provide a function to convert struct to string
+func (sp sessionProblem) String() string {
+	return fmt.Sprintf("PodName:%s, Xname:%s, Pid:%d, Command:%s, State:%s, ElapsedSec:%d, Problem:%s",
+		sp.PodName, sp.Xname, sp.Pid, sp.Command, sp.State, sp.ElapsedSec, sp.Problem)
+}
+
+// Results of the most recent session check
+var sessionProblems []sessionProblem = nil
+var sessionCheckTime string = "Unknown"
+var sessionsMutex sync.Mutex
+
+// A session process seen blocked, stopped or a zombie on a check
+// NOTE: the start time tells a reused pid from the same process
+type sessionStateSeen struct {
+	StartSec int64
+	Since    time.Time
+}
+
+// Session processes in a bad state by pod and pid, kept across checks so a
+// process is only stuck once it has stayed in that state long enough
+var sessionBadStates = make(map[string]map[int]sessionStateSeen)
+
+// Number of consecutive failures to exec into console-node pods
+var numExecFailures int = 0
+
+// Main loop to periodically check the console-node pods for session problems
+func (sm SessionManager) watchConsoleSessions(ctx context.Context) {
+	for {
+		// NOTE: skip the check while shutting down, suspended or handed off
+		if !reconcileStopped() {
+			sm.checkConsoleSessions()
+		}
+		if !sleepCtx(ctx, time.Duration(sessionCheckPeriodSec)*time.Second) {
+			log.Printf("Stopping console session checks")
+			return
+		}
+	}
+}
+
+// Check all console-node pods for stuck or duplicate sessions
+func (sm SessionManager) checkConsoleSessions() {
+	log.Printf("Checking console-node pods for stuck sessions")
+	pods, err := sm.k8Service.getConsoleNodePods()
+	if err != nil {
+		logError(errConsoleSessions, "Unable to check console sessions - error getting console-node pods: %s", err)
+		return
+	}
+
+	var problems []sessionProblem = nil
+	checked := make(map[string]bool)
+	for _, pod := range pods {
+		// NOTE: 'etimes' is the elapsed time of the process in seconds
+		out, err := sm.k8Service.execInPod(pod, consoleNodeContainer, []string{"ps", "-eo", "pid,stat,etimes,args"})
+		sessionsMutex.Lock()
+		if err != nil {
+			numExecFailures++
+		} else {
+			numExecFailures = 0
+		}
+		sessionsMutex.Unlock()
+		if err != nil {
+			logError(errConsoleSessions, "Unable to get processes in pod %s: %s", pod, err)
+			continue
+		}
+
+		checked[pod] = true
+		if sessionBadStates[pod] == nil {
+			sessionBadStates[pod] = make(map[int]sessionStateSeen)
+		}
+		podProblems := findSessionProblems(pod, out, sessionBadStates[pod], time.Now())
+		if cleanupStuckSessions && len(podProblems) > 0 {
+			sm.cleanupSessions(pod, podProblems)
+		}
+		problems = append(problems, podProblems...)
+	}
+
+	// forget the processes of pods that are gone
+	for pod := range sessionBadStates {
+		if !checked[pod] {
+			delete(sessionBadStates, pod)
+		}
+	}
+
+	sessionsMutex.Lock()
+	sessionProblems = problems
+	sessionCheckTime = formatTime(time.Now())
+	sessionsMutex.Unlock()
+	log.Printf("Found %d console session problems", len(problems))
+}
+
+// Find stuck and duplicate console sessions in the process list of a pod
+// NOTE: 'seen' holds the processes of the pod in a bad state on earlier
+// checks and is updated for the next one
+func findSessionProblems(podName, psOutput string, seen map[int]sessionStateSeen, now time.Time) []sessionProblem {
+	// collect the session processes by command and xname
+	sessions := make(map[string][]sessionProblem)
+	var problems []sessionProblem = nil
+	bad := make(map[int]bool)
+	for _, line := range strings.Split(psOutput, "\n") {
+		cols := strings.Fields(line)
+		if len(cols) < 4 {
+			continue
+		}
+		// skip the header and anything that doesn't parse
+		pid, err := strconv.Atoi(cols[0])
+		if err != nil {
+			continue
+		}
+		elapsed, err := strconv.Atoi(cols[2])
+		if err != nil {
+			continue
+		}
+		cmd := filepath.Base(cols[3])
+		checkDuplicates, found := sessionCommands[cmd]
+		if !found {
+			continue
+		}
+		xname := sessionXnameRegex.FindString(strings.Join(cols[4:], " "))
+		if xname == "" {
+			continue
+		}
+		sp := sessionProblem{PodName: podName, Xname: xname, Pid: pid, Command: cmd,
+			State: cols[1], ElapsedSec: elapsed}
+
+		// NOTE: a process that has stayed blocked (D), stopped (T), or a
+		// zombie (Z) across checks for too long is considered stuck
+		if strings.ContainsAny(sp.State[:1], "DTZ") {
+			bad[pid] = true
+			start := now.Unix() - int64(elapsed)
+			prev, found := seen[pid]
+			// allow for the rounding of the elapsed time between checks
+			if !found || prev.StartSec < start-2 || prev.StartSec > start+2 {
+				prev = sessionStateSeen{StartSec: start, Since: now}
+				seen[pid] = prev
+			}
+			sp.StuckSec = int(now.Sub(prev.Since).Seconds())
+			if sp.StuckSec >= sessionStuckMinutes*60 {
+				sp.Problem = "stuck"
+				problems = append(problems, sp)
+				continue
+			}
+		}
+		if checkDuplicates {
+			key := cmd + ":" + xname
+			sessions[key] = append(sessions[key], sp)
+		}
+	}
+
+	// a process that left the bad state starts over if it goes back
+	for pid := range seen {
+		if !bad[pid] {
+			delete(seen, pid)
+		}
+	}
+
+	// more than one session process for the same console - the newest one
+	// is assumed to be the live connection and the rest are duplicates
+	for _, procs := range sessions {
+		if len(procs) < 2 {
+			continue
+		}
+		sort.Slice(procs, func(i, j int) bool { return procs[i].ElapsedSec < procs[j].ElapsedSec })
+		for _, sp := range procs[1:] {
+			sp.Problem = "duplicate"
+			problems = append(problems, sp)
+		}
+	}
+	return problems
+}
+
+// Kill the processes for stuck or duplicate sessions in a pod
+func (sm SessionManager) cleanupSessions(podName string, problems []sessionProblem) {
+	cmd := []string{"kill", "-9"}
+	for _, sp := range problems {
+		log.Printf("Cleaning up console session: %s", sp)
+		cmd = append(cmd, strconv.Itoa(sp.Pid))
+	}
+	if _, err := sm.k8Service.execInPod(podName, consoleNodeContainer, cmd); err != nil {
+		logError(errConsoleSessions, "Error cleaning up console sessions in pod %s: %s", podName, err)
+		return
+	}
+	for i := range problems {
+		problems[i].Cleaned = true
+	}
+}
+
+// GetSessionsResponse - results of the last console session check
+type GetSessionsResponse struct {
+	CheckTime string           `json:"checktime"`
+	Sessions  []sessionProblem `json:"sessions"`
+}
+
+// Report the stuck and duplicate console sessions found on the last check
+func (SessionManager) doGetSessions(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	var resp GetSessionsResponse
+	sessionsMutex.Lock()
+	resp.CheckTime = sessionCheckTime
+	resp.Sessions = sessionProblems
+	sessionsMutex.Unlock()
+	SendResponseJSON(w, http.StatusOK, resp)
+}
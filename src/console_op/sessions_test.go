@@ -0,0 +1,111 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// ps -eo pid,stat,etimes,args output from a console-node pod
+var sessionPsMock = `    PID STAT ELAPSED COMMAND
+      1 Ss       9000 /app/console_node
+     25 Sl       8990 conmand -F -v -c /etc/conman.conf
+     40 S        8000 ipmitool -I lanplus -H x3000c0s17b1 -U root -E sol activate
+     41 S         100 ipmitool -I lanplus -H x3000c0s17b1 -U root -E sol activate
+     50 D        7000 /usr/bin/ssh -i /var/log/console/conman.key root@x1000c0s0b0
+     60 S         500 conman -j x3000c0s19b0n0
+     61 S         400 conman -j x3000c0s19b0n0
+     70 S         300 ssh root@x1000c0s1b0
+bad line
+`
+
+func TestFindSessionProblems(t *testing.T) {
+	oldStuck := sessionStuckMinutes
+	defer func() { sessionStuckMinutes = oldStuck }()
+	sessionStuckMinutes = 10
+	seen := make(map[int]sessionStateSeen)
+	now := time.Now()
+
+	// the first time the blocked ssh session is seen it is not stuck yet,
+	// however long the process has been running
+	problems := findSessionProblems("cray-console-node-0", sessionPsMock, seen, now)
+	if len(problems) != 1 || problems[0].Pid != 40 {
+		t.Errorf("Expected only the duplicate on the first check. Got: %v", problems)
+	}
+	if _, found := seen[50]; !found {
+		t.Errorf("Expected pid 50 to be tracked")
+	}
+
+	// still blocked 10 minutes later - the process is 600 seconds older
+	later := now.Add(10 * time.Minute)
+	psLater := strings.Replace(sessionPsMock, "50 D        7000", "50 D        7600", 1)
+	problems = findSessionProblems("cray-console-node-0", psLater, seen, later)
+
+	// Expected results - the older ipmitool session is a duplicate, the ssh
+	// session blocked for 10 minutes is stuck, and multiple conman
+	// clients are allowed
+	expected := map[int]string{40: "duplicate", 50: "stuck"}
+	if len(problems) != len(expected) {
+		t.Errorf("Expected %d problems. Got: %d: %v", len(expected), len(problems), problems)
+	}
+	for _, sp := range problems {
+		if expected[sp.Pid] != sp.Problem {
+			t.Errorf("Pid %d expected: %s. Got: %s.", sp.Pid, expected[sp.Pid], sp.Problem)
+		}
+		if sp.PodName != "cray-console-node-0" {
+			t.Errorf("Expected: cray-console-node-0. Got: %s.", sp.PodName)
+		}
+	}
+}
+
+func TestFindSessionProblemsStateChanges(t *testing.T) {
+	oldStuck := sessionStuckMinutes
+	defer func() { sessionStuckMinutes = oldStuck }()
+	sessionStuckMinutes = 10
+	seen := make(map[int]sessionStateSeen)
+	now := time.Now()
+	blocked := "50 D 7000 /usr/bin/ssh root@x1000c0s0b0"
+	findSessionProblems("cray-console-node-0", blocked, seen, now)
+
+	// the process recovers so the tracking starts over
+	running := "50 S 7300 /usr/bin/ssh root@x1000c0s0b0"
+	findSessionProblems("cray-console-node-0", running, seen, now.Add(5*time.Minute))
+	if len(seen) != 0 {
+		t.Errorf("Expected pid 50 to no longer be tracked. Got: %v", seen)
+	}
+	findSessionProblems("cray-console-node-0", "50 D 7600 /usr/bin/ssh root@x1000c0s0b0", seen, now.Add(10*time.Minute))
+	problems := findSessionProblems("cray-console-node-0", "50 D 7900 /usr/bin/ssh root@x1000c0s0b0", seen, now.Add(15*time.Minute))
+	if len(problems) != 0 {
+		t.Errorf("Expected no stuck sessions 5 minutes after blocking again. Got: %v", problems)
+	}
+
+	// the pid is reused by a new process that is blocked
+	problems = findSessionProblems("cray-console-node-0", "50 D 10 /usr/bin/ssh root@x1000c0s0b0", seen, now.Add(25*time.Minute))
+	if len(problems) != 0 {
+		t.Errorf("Expected no stuck sessions for a reused pid. Got: %v", problems)
+	}
+}
@@ -0,0 +1,126 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to split the hardware watching between operator
+//  replicas by shards of the xname space
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Global vars to control sharding - sharding is off with a single shard
+// NOTE: shard 0 is held through the leader lease and its owner also does
+// the scaling, stale heartbeat, and session checks for the whole system
+var shardCount int = 1
+var shardMode string = "hash"
+
+// The shards owned by this replica
+var ownedShards = make(map[int]struct{})
+var ownedShardsMutex sync.Mutex
+
+// Pattern to find the cabinet of an xname
+var cabinetRegex = regexp.MustCompile(`^x[0-9]+`)
+
+// Find the shard an xname belongs to
+func shardOf(xname string, numShards int, mode string) int {
+	if numShards <= 1 {
+		return 0
+	}
+	key := xname
+	if mode == "cabinet" {
+		// keep all the nodes of a cabinet together
+		if cab := cabinetRegex.FindString(xname); cab != "" {
+			key = cab
+		}
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(numShards))
+}
+
+// Record a shard being gained or lost by this replica
+func setShardOwned(shard int, owned bool) {
+	ownedShardsMutex.Lock()
+	defer ownedShardsMutex.Unlock()
+	if owned {
+		ownedShards[shard] = struct{}{}
+	} else {
+		delete(ownedShards, shard)
+	}
+}
+
+// Check if this replica owns the given shard
+func ownsShard(shard int) bool {
+	if shardCount <= 1 {
+		return true
+	}
+	ownedShardsMutex.Lock()
+	defer ownedShardsMutex.Unlock()
+	_, found := ownedShards[shard]
+	return found
+}
+
+// Get the number of shards owned by this replica
+func numOwnedShards() int {
+	ownedShardsMutex.Lock()
+	defer ownedShardsMutex.Unlock()
+	return len(ownedShards)
+}
+
+// Check if a node is handled by this replica
+func nodeInMyShards(xname string) bool {
+	return ownsShard(shardOf(xname, shardCount, shardMode))
+}
+
+// Campaign for a shard other than 0 and hold it until the lease is lost
+// NOTE: a replica may hold more than one shard so every shard is covered
+// when there are fewer replicas than shards, but it waits longer before
+// trying for each shard it already holds so others get a chance first
+func (k8s K8Manager) runShardElection(ctx context.Context, shard int) {
+	leaseName := fmt.Sprintf("%s-shard-%d", leaderLeaseName, shard)
+	for {
+		wait := time.Duration(numOwnedShards()*leaderLeaseDurationSec) * time.Second
+		if !sleepCtx(ctx, wait) {
+			return
+		}
+		k8s.runLeaseElection(ctx, leaseName, func(lctx context.Context) {
+			log.Printf("Now handling shard %d of %d", shard, shardCount)
+			setShardOwned(shard, true)
+			<-lctx.Done()
+			setShardOwned(shard, false)
+			log.Printf("No longer handling shard %d of %d", shard, shardCount)
+		}, nil)
+		if !sleepCtx(ctx, time.Duration(leaderRetryPeriodSec)*time.Second) {
+			return
+		}
+	}
+}
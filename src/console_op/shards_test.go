@@ -0,0 +1,78 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestShardOf(t *testing.T) {
+	// everything is in shard 0 when not sharded
+	if s := shardOf("x1000c0s0b0n0", 1, "hash"); s != 0 {
+		t.Errorf("Expected: 0. Got: %d.", s)
+	}
+
+	// all the nodes of a cabinet stay together
+	s0 := shardOf("x1000c0s0b0n0", 8, "cabinet")
+	for _, xname := range []string{"x1000c1s0b0n0", "x1000c7s7b1n1", "x1000"} {
+		if s := shardOf(xname, 8, "cabinet"); s != s0 {
+			t.Errorf("%s: Expected: %d. Got: %d.", xname, s0, s)
+		}
+	}
+
+	// hashing spreads the nodes of a cabinet over the shards
+	seen := make(map[int]bool)
+	for i := 0; i < 64; i++ {
+		s := shardOf(fmt.Sprintf("x1000c0s%db0n0", i), 4, "hash")
+		if s < 0 || s >= 4 {
+			t.Errorf("Expected: shard in [0,4). Got: %d.", s)
+		}
+		seen[s] = true
+	}
+	if len(seen) != 4 {
+		t.Errorf("Expected: 4 shards used. Got: %d.", len(seen))
+	}
+}
+
+func TestNodeInMyShards(t *testing.T) {
+	defer func() { shardCount = 1 }()
+
+	// everything is ours when not sharded
+	if !nodeInMyShards("x1000c0s0b0n0") {
+		t.Errorf("Expected: node in shard when not sharded.")
+	}
+
+	shardCount = 4
+	shard := shardOf("x1000c0s0b0n0", shardCount, shardMode)
+	if nodeInMyShards("x1000c0s0b0n0") {
+		t.Errorf("Expected: node not in an unowned shard.")
+	}
+	setShardOwned(shard, true)
+	defer setShardOwned(shard, false)
+	if !nodeInMyShards("x1000c0s0b0n0") {
+		t.Errorf("Expected: node in owned shard %d.", shard)
+	}
+}
@@ -0,0 +1,232 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to find powered on nodes whose consoles have
+//  gone quiet, which usually means the node is hung
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Base url of the power control service
+var pcsAddrBase string = "http://cray-power-control"
+
+// Minutes a powered on node's console can be quiet before it is reported -
+// 0 disables the check
+var consoleSilenceMinutes int = 0
+
+// How often to look for quiet consoles
+const consoleSilenceCheckPeriod = time.Minute
+
+// SilentConsole - a powered on node whose console has been quiet
+type SilentConsole struct {
+	Xname         string `json:"xname"`
+	LastOutput    string `json:"lastoutput"`
+	SilentMinutes int    `json:"silentminutes"`
+	Since         string `json:"since"`
+}
+
+// Quiet consoles by xname
+var silentConsoles map[string]SilentConsole = make(map[string]SilentConsole)
+var silentConsolesMutex sync.Mutex
+
+// Get the power state of the given nodes from PCS
+func getPowerStates(xnames []string) (map[string]string, error) {
+	param, _ := json.Marshal(map[string][]string{"xname": xnames})
+	URL := pcsAddrBase + "/power-status"
+	data, sc, err := postURL(URL, param, nil)
+	if err != nil {
+		return nil, err
+	}
+	if sc != http.StatusOK {
+		return nil, fmt.Errorf("POST %s to pcs returned status: %d", URL, sc)
+	}
+	var resp struct {
+		Status []struct {
+			Xname      string `json:"xname"`
+			PowerState string `json:"powerState"`
+		} `json:"status"`
+	}
+	if err = json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+	states := make(map[string]string)
+	for _, s := range resp.Status {
+		states[strings.ToLower(s.Xname)] = strings.ToLower(s.PowerState)
+	}
+	return states, nil
+}
+
+// Find the nodes with console logs that have not been written to within the
+// window
+func quietConsoles(byNode map[string][]consoleLogFile, window time.Duration, now time.Time) map[string]time.Time {
+	quiet := make(map[string]time.Time)
+	for xname, files := range byNode {
+		for _, f := range files {
+			if !f.rotated && now.Sub(f.modTime) >= window {
+				quiet[xname] = f.modTime
+			}
+		}
+	}
+	return quiet
+}
+
+// Update the quiet consoles, returning the nodes that just went quiet and
+// the ones that started talking again
+func updateSilentConsoles(quiet map[string]time.Time, powerStates map[string]string, now time.Time) (silenced, resumed []string) {
+	silentConsolesMutex.Lock()
+	defer silentConsolesMutex.Unlock()
+	for xname, last := range quiet {
+		if powerStates[xname] != "on" {
+			continue
+		}
+		sc, found := silentConsoles[xname]
+		if !found {
+			sc = SilentConsole{Xname: xname, Since: formatTime(now)}
+			silenced = append(silenced, xname)
+		}
+		sc.LastOutput = formatTime(last)
+		sc.SilentMinutes = int(now.Sub(last) / time.Minute)
+		silentConsoles[xname] = sc
+	}
+	for xname := range silentConsoles {
+		if _, found := quiet[xname]; !found || powerStates[xname] != "on" {
+			delete(silentConsoles, xname)
+			resumed = append(resumed, xname)
+		}
+	}
+	sort.Strings(silenced)
+	sort.Strings(resumed)
+	return silenced, resumed
+}
+
+// Get the quiet consoles, the longest quiet first
+func getSilentConsoles() []SilentConsole {
+	silentConsolesMutex.Lock()
+	defer silentConsolesMutex.Unlock()
+	silent := []SilentConsole{}
+	for _, sc := range silentConsoles {
+		silent = append(silent, sc)
+	}
+	sort.Slice(silent, func(i, j int) bool {
+		if silent[i].SilentMinutes != silent[j].SilentMinutes {
+			return silent[i].SilentMinutes > silent[j].SilentMinutes
+		}
+		return silent[i].Xname < silent[j].Xname
+	})
+	return silent
+}
+
+// Loop to look for powered on nodes with quiet consoles
+func (LogManager) watchConsoleSilence(ctx context.Context) {
+	if consoleSilenceMinutes <= 0 {
+		return
+	}
+	for {
+		if !debugOnly && !reconcileStopped() {
+			checkConsoleSilence(time.Now())
+		}
+		if !sleepCtx(ctx, consoleSilenceCheckPeriod) {
+			log.Printf("Stopping console silence checks")
+			return
+		}
+	}
+}
+
+// Check the console logs against the power state of the nodes
+func checkConsoleSilence(now time.Time) {
+	byNode, err := consoleLogFilesByNode(consoleLogDir)
+	if err != nil {
+		log.Printf("Unable to read the console log directory %s: %s", consoleLogDir, err)
+		return
+	}
+	quiet := quietConsoles(byNode, time.Duration(consoleSilenceMinutes)*time.Minute, now)
+	// NOTE: a console is expected to be quiet while its bmc is updated or it
+	//  is out of monitoring for maintenance
+	for xname := range quiet {
+		if inFirmwareMaintenance(xname) || inNodeMaintenance(xname) {
+			delete(quiet, xname)
+		}
+	}
+	powerStates := make(map[string]string)
+	if len(quiet) > 0 {
+		var xnames []string = nil
+		for xname := range quiet {
+			xnames = append(xnames, xname)
+		}
+		// NOTE: without the power state a quiet console can not be told
+		//  apart from a node that is off, so nothing is changed
+		if powerStates, err = getPowerStates(xnames); err != nil {
+			logError(errPCS, "Unable to get the power state of quiet consoles from pcs: %s", err)
+			return
+		}
+	}
+	silenced, resumed := updateSilentConsoles(quiet, powerStates, now)
+	for _, xname := range silenced {
+		log.Printf("Console for %s has been quiet for over %d minutes while powered on", xname, consoleSilenceMinutes)
+		sendConsoleSilenceAlert(xname, "firing", now)
+	}
+	for _, xname := range resumed {
+		log.Printf("Console for %s is no longer quiet", xname)
+		sendConsoleSilenceAlert(xname, "resolved", now)
+	}
+}
+
+// Send an alert for a quiet console to the alert webhook if there is one
+func sendConsoleSilenceAlert(xname, status string, now time.Time) {
+	if alertWebhookURL == "" {
+		return
+	}
+	sendAlert(processAlert{
+		Source:    alertSource,
+		Alert:     "consolesilence",
+		Status:    status,
+		Threshold: consoleSilenceMinutes,
+		Message:   fmt.Sprintf("Console for %s quiet for %d minutes while powered on", xname, consoleSilenceMinutes),
+		Time:      formatTime(now),
+	})
+}
+
+// Report the powered on nodes whose consoles have been quiet
+func (LogManager) doGetSilentConsoles(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	SendResponseJSON(w, http.StatusOK, getSilentConsoles())
+}
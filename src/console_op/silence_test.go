@@ -0,0 +1,66 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConsoleSilence(t *testing.T) {
+	defer func() { silentConsoles = make(map[string]SilentConsole) }()
+	now := time.Now()
+	byNode := map[string][]consoleLogFile{
+		"x1000c0s0b0n0": {{path: "console.x1000c0s0b0n0", modTime: now.Add(-time.Hour)}},
+		"x1000c0s0b0n1": {{path: "console.x1000c0s0b0n1", modTime: now.Add(-time.Hour)}},
+		"x1000c0s0b1n0": {{path: "console.x1000c0s0b1n0", modTime: now.Add(-time.Minute)},
+			{path: "console.x1000c0s0b1n0-1", modTime: now.Add(-48 * time.Hour), rotated: true}},
+	}
+	quiet := quietConsoles(byNode, 30*time.Minute, now)
+	if len(quiet) != 2 {
+		t.Errorf("Expected: 2. Got: %d.", len(quiet))
+	}
+
+	// only the powered on node is silent
+	power := map[string]string{"x1000c0s0b0n0": "on", "x1000c0s0b0n1": "off"}
+	silenced, resumed := updateSilentConsoles(quiet, power, now)
+	if len(silenced) != 1 || silenced[0] != "x1000c0s0b0n0" || len(resumed) != 0 {
+		t.Errorf("Expected x1000c0s0b0n0 silenced. Got: %v %v.", silenced, resumed)
+	}
+	if sc := getSilentConsoles(); len(sc) != 1 || sc[0].SilentMinutes != 60 {
+		t.Errorf("Expected one console quiet for 60 minutes. Got: %v.", sc)
+	}
+
+	// still quiet is not reported again
+	if silenced, _ = updateSilentConsoles(quiet, power, now); len(silenced) != 0 {
+		t.Errorf("Expected: 0. Got: %d.", len(silenced))
+	}
+
+	// output again
+	delete(quiet, "x1000c0s0b0n0")
+	if _, resumed = updateSilentConsoles(quiet, power, now); len(resumed) != 1 {
+		t.Errorf("Expected: 1. Got: %d.", len(resumed))
+	}
+}
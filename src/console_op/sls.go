@@ -1,6 +1,6 @@
 // MIT License
 //
-// (C) Copyright 2023 Hewlett Packard Enterprise Development LP
+// (C) Copyright 2023, 2026 Hewlett Packard Enterprise Development LP
 //
 // Permission is hereby granted, free of charge, to any person obtaining a
 // copy of this software and associated documentation files (the "Software"),
@@ -25,7 +25,6 @@ package main
 
 import (
 	"encoding/json"
-	"log"
 )
 
 type SlsService interface {
@@ -67,7 +66,7 @@ func (sls SlsManager) getXnameAlias() (xnameNodeAlias []XnameNodeAlias, err erro
 	hwUrl := sls.baseUrl + "/hardware"
 	data, _, err := getURL(hwUrl, nil)
 	if err != nil {
-		log.Printf("Error: GET %s to hms-sls failed %s\n", hwUrl, err)
+		logError(errSLS, "Error: GET %s to hms-sls failed %s\n", hwUrl, err)
 		return nil, err
 	}
 
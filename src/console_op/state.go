@@ -0,0 +1,235 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to export and import the state of the console
+//  subsystem so it can be restored quickly after losing the console-data db
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Version of the exported state document
+const consoleStateVersion int = 1
+
+// ConsoleStateSettings - the settings in effect when the state was exported
+// NOTE: only the max nodes per pod can be changed at run time so only those
+// are applied on import, the rest are for reference
+type ConsoleStateSettings struct {
+	MaxMtnNodesPerPod    int  `json:"maxmtnnodesperpod"`
+	MaxRvrNodesPerPod    int  `json:"maxrvrnodesperpod"`
+	MinNodePods          int  `json:"minnodepods"`
+	MaxNodePods          int  `json:"maxnodepods"`
+	ScaleToZero          bool `json:"scaletozero"`
+	CapacityDistribution bool `json:"capacitydistribution"`
+	ResourceScaling      bool `json:"resourcescaling"`
+}
+
+// ConsoleState - the exported state of the console subsystem
+type ConsoleState struct {
+	Version           int                  `json:"version"`
+	Exported          string               `json:"exported"`
+	Nodes             []nodeConsoleInfo    `json:"nodes"`
+	Assignments       map[string]string    `json:"assignments"` // node xname -> pod name
+	NumNodePods       int                  `json:"numnodepods"`
+	TargetNumMtnNodes int                  `json:"targetnummtnnodes"`
+	TargetNumRvrNodes int                  `json:"targetnumrvrnodes"`
+	PodTargets        []podTarget          `json:"podtargets"`
+	Settings          ConsoleStateSettings `json:"settings"`
+}
+
+// ImportStateResponse - summary of what was restored
+type ImportStateResponse struct {
+	NumNodes       int    `json:"numnodes"`
+	NumPodTargets  int    `json:"numpodtargets"`
+	NumAssignments int    `json:"numassignments"`
+	Message        string `json:"message"`
+}
+
+type StateService interface {
+	doExportState(w http.ResponseWriter, r *http.Request)
+	doImportState(w http.ResponseWriter, r *http.Request)
+	doHandoff(w http.ResponseWriter, r *http.Request)
+	doReleaseHandoff(w http.ResponseWriter, r *http.Request)
+}
+
+// Implements StateService
+type StateManager struct {
+	dataService DataService
+	k8Service   K8Service
+}
+
+// Constructor injection for dependencies
+func NewStateManager(ds DataService, k8s K8Service) StateService {
+	return &StateManager{dataService: ds, k8Service: k8s}
+}
+
+// Gather the current state of the console subsystem
+// NOTE: the node to pod assignments come from console-data, if that is not
+// available the rest of the state is still exported
+func exportConsoleState() ConsoleState {
+	var st ConsoleState
+	st.Version = consoleStateVersion
+	st.Exported = formatTime(time.Now())
+
+	// NOTE - not thread safe, but should be ok
+	for _, n := range nodeCache {
+		st.Nodes = append(st.Nodes, n)
+	}
+	sort.Slice(st.Nodes, func(i, j int) bool { return st.Nodes[i].NodeName < st.Nodes[j].NodeName })
+
+	st.Assignments = make(map[string]string)
+	if inv, err := getDataInventory(""); err == nil {
+		for _, n := range inv {
+			if n.NodeConsoleName != "" {
+				st.Assignments[n.NodeName] = fmt.Sprintf("%s-%s", consoleNodeName, n.NodeConsoleName)
+			}
+		}
+	} else {
+		log.Printf("Exporting state without the node assignments: %s", err)
+	}
+
+	st.NumNodePods = numNodePods
+	st.TargetNumMtnNodes = numMtnNodesPerPod
+	st.TargetNumRvrNodes = numRvrNodesPerPod
+	st.PodTargets = getPodTargets()
+	st.Settings = ConsoleStateSettings{
+		MaxMtnNodesPerPod:    maxMtnNodesPerPod,
+		MaxRvrNodesPerPod:    maxRvrNodesPerPod,
+		MinNodePods:          minNodePods,
+		MaxNodePods:          maxNodePods,
+		ScaleToZero:          scaleToZero,
+		CapacityDistribution: capacityDistribution,
+		ResourceScaling:      resourceScaling,
+	}
+	return st
+}
+
+// Check an imported state document before anything is changed
+func validateConsoleState(st ConsoleState) error {
+	if st.Version != consoleStateVersion {
+		return fmt.Errorf("Unsupported state version: %d, expected: %d", st.Version, consoleStateVersion)
+	}
+	for _, n := range st.Nodes {
+		if n.NodeName == "" || n.BmcName == "" {
+			return fmt.Errorf("Incomplete node in state: %s", n)
+		}
+	}
+	if st.Settings.MaxMtnNodesPerPod < minNodesPerPodLimit || st.Settings.MaxMtnNodesPerPod > maxMtnNodesPerPodLimit {
+		return fmt.Errorf("Invalid max mountain nodes per pod: %d", st.Settings.MaxMtnNodesPerPod)
+	}
+	if st.Settings.MaxRvrNodesPerPod < minNodesPerPodLimit || st.Settings.MaxRvrNodesPerPod > maxRvrNodesPerPodLimit {
+		return fmt.Errorf("Invalid max river nodes per pod: %d", st.Settings.MaxRvrNodesPerPod)
+	}
+	return nil
+}
+
+// Export the state of the console subsystem as a single document
+func (StateManager) doExportState(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	SendResponseJSON(w, http.StatusOK, exportConsoleState())
+}
+
+// Restore the state of the console subsystem from an exported document
+// NOTE: the nodes are pushed back into console-data and the targets are
+// written out so the console-node pods can start acquiring again right away.
+// console-data has no way to set which pod holds a node so the pods
+// re-acquire their nodes - the exported assignments are only reported.
+func (sm StateManager) doImportState(w http.ResponseWriter, r *http.Request) {
+	// only allow 'PUT' calls
+	if r.Method != http.MethodPut {
+		w.Header().Set("Allow", "PUT")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	reqBody, err := ioutil.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		sendJSONError(w, http.StatusBadRequest,
+			fmt.Sprintf("There was an error reading the request body: %s", err))
+		return
+	}
+	var st ConsoleState
+	if err = json.Unmarshal(reqBody, &st); err != nil {
+		sendJSONError(w, http.StatusBadRequest,
+			fmt.Sprintf("There was an error decoding the state: %s", err))
+		return
+	}
+	if err = validateConsoleState(st); err != nil {
+		sendJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	log.Printf("Importing state exported at %s with %d nodes", st.Exported, len(st.Nodes))
+
+	// restore the inventory in console-data
+	if len(st.Nodes) > 0 {
+		if ok := sm.dataService.dataAddNodes(st.Nodes); !ok {
+			sendJSONError(w, http.StatusInternalServerError, "Unable to restore the nodes in console-data")
+			return
+		}
+	}
+	newCache := make(map[string]nodeConsoleInfo)
+	for _, n := range st.Nodes {
+		newCache[n.NodeName] = n
+	}
+	nodeCache = newCache
+
+	// restore the settings and targets
+	maxMtnNodesPerPod = st.Settings.MaxMtnNodesPerPod
+	maxRvrNodesPerPod = st.Settings.MaxRvrNodesPerPod
+	setConfigSource("MAX_MTN_NODES_PER_POD", configSourceAPI)
+	setConfigSource("MAX_RVR_NODES_PER_POD", configSourceAPI)
+	if st.TargetNumMtnNodes > 0 && st.TargetNumRvrNodes > 0 {
+		sm.k8Service.updateNodesPerPod(st.TargetNumMtnNodes, st.TargetNumRvrNodes)
+	}
+	if capacityDistribution && len(st.PodTargets) > 0 {
+		sm.k8Service.updatePodTargets(cordonPodTargets(st.PodTargets, getCordonedPod()))
+		podTargetsMutex.Lock()
+		podTargets = st.PodTargets
+		podTargetsMutex.Unlock()
+	}
+
+	SendResponseJSON(w, http.StatusOK, ImportStateResponse{
+		NumNodes:       len(st.Nodes),
+		NumPodTargets:  len(st.PodTargets),
+		NumAssignments: len(st.Assignments),
+		Message:        "State restored, console-node pods will re-acquire their nodes",
+	})
+}
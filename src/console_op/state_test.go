@@ -0,0 +1,164 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestValidateConsoleState(t *testing.T) {
+	good := ConsoleState{
+		Version: consoleStateVersion,
+		Nodes: []nodeConsoleInfo{
+			{NodeName: "x3000c0s1b0n0", BmcName: "x3000c0s1b0", BmcFqdn: "x3000c0s1b0", Class: "River", NID: 1, Role: "Compute"},
+		},
+		Settings: ConsoleStateSettings{MaxMtnNodesPerPod: 750, MaxRvrNodesPerPod: 2000},
+	}
+	if err := validateConsoleState(good); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+
+	badVersion := good
+	badVersion.Version = consoleStateVersion + 1
+	if err := validateConsoleState(badVersion); err == nil {
+		t.Errorf("Expected an error for version %d", badVersion.Version)
+	}
+
+	badNode := good
+	badNode.Nodes = []nodeConsoleInfo{{NodeName: "x3000c0s1b0n0"}}
+	if err := validateConsoleState(badNode); err == nil {
+		t.Errorf("Expected an error for a node without a bmc")
+	}
+
+	badMax := good
+	badMax.Settings.MaxRvrNodesPerPod = 1
+	if err := validateConsoleState(badMax); err == nil {
+		t.Errorf("Expected an error for max river nodes per pod %d", badMax.Settings.MaxRvrNodesPerPod)
+	}
+
+	// the same bounds as the env settings
+	atMax := good
+	atMax.Settings = ConsoleStateSettings{MaxMtnNodesPerPod: maxMtnNodesPerPodLimit, MaxRvrNodesPerPod: maxRvrNodesPerPodLimit}
+	if err := validateConsoleState(atMax); err != nil {
+		t.Errorf("Unexpected error at the max nodes per pod: %s", err)
+	}
+	overMax := atMax
+	overMax.Settings.MaxMtnNodesPerPod++
+	if err := validateConsoleState(overMax); err == nil {
+		t.Errorf("Expected an error for max mountain nodes per pod %d", overMax.Settings.MaxMtnNodesPerPod)
+	}
+}
+
+func TestSeedConsoleState(t *testing.T) {
+	st := ConsoleState{
+		Version: consoleStateVersion,
+		Nodes: []nodeConsoleInfo{
+			{NodeName: "x3000c0s1b0n0", BmcName: "x3000c0s1b0", Class: "River"},
+			{NodeName: "x1000c0s0b0n0", BmcName: "x1000c0s0b0", Class: "Mountain"},
+		},
+		NumNodePods:       2,
+		TargetNumMtnNodes: 1,
+		TargetNumRvrNodes: 1,
+		Settings:          ConsoleStateSettings{MaxMtnNodesPerPod: 750, MaxRvrNodesPerPod: 2000},
+	}
+	// restore the globals when done
+	oldCache, oldPods, oldMtn, oldRvr := nodeCache, numNodePods, numMtnNodesPerPod, numRvrNodesPerPod
+	defer func() {
+		nodeCache, numNodePods, numMtnNodesPerPod, numRvrNodesPerPod = oldCache, oldPods, oldMtn, oldRvr
+	}()
+
+	seedConsoleState(st)
+	if len(nodeCache) != 2 {
+		t.Errorf("Expected: 2. Got: %d.", len(nodeCache))
+	}
+	if _, found := nodeCache["x1000c0s0b0n0"]; !found {
+		t.Errorf("Expected x1000c0s0b0n0 in the node cache")
+	}
+	if numNodePods != 2 {
+		t.Errorf("Expected: 2. Got: %d.", numNodePods)
+	}
+	if numMtnNodesPerPod != 1 || numRvrNodesPerPod != 1 {
+		t.Errorf("Expected: 1, 1. Got: %d, %d.", numMtnNodesPerPod, numRvrNodesPerPod)
+	}
+}
+
+func TestHandoffAuthorization(t *testing.T) {
+	oldToken, oldUntil := handoffToken, handedOffUntil
+	defer func() { handoffToken, handedOffUntil = oldToken, oldUntil }()
+	sts := StateManager{}
+
+	// no token configured - the handoff is disabled
+	handoffToken = ""
+	req := httptest.NewRequest(http.MethodPost, "/console-operator/v1/handoff", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	rr := httptest.NewRecorder()
+	sts.doHandoff(rr, req)
+	if rr.Code != http.StatusUnauthorized || handoffActive() {
+		t.Errorf("Expected an unauthorized handoff without a token, got %d", rr.Code)
+	}
+
+	// wrong token
+	handoffToken = "secret"
+	req = httptest.NewRequest(http.MethodPost, "/console-operator/v1/handoff", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	rr = httptest.NewRecorder()
+	sts.doHandoff(rr, req)
+	if rr.Code != http.StatusUnauthorized || handoffActive() {
+		t.Errorf("Expected an unauthorized handoff with the wrong token, got %d", rr.Code)
+	}
+
+	// right token stops reconciling until released
+	req = httptest.NewRequest(http.MethodPost, "/console-operator/v1/handoff", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rr = httptest.NewRecorder()
+	sts.doHandoff(rr, req)
+	if rr.Code != http.StatusOK || !handoffActive() {
+		t.Errorf("Expected the handoff to be active, got %d", rr.Code)
+	}
+	req = httptest.NewRequest(http.MethodDelete, "/console-operator/v1/handoff", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rr = httptest.NewRecorder()
+	sts.doReleaseHandoff(rr, req)
+	if rr.Code != http.StatusNoContent || handoffActive() {
+		t.Errorf("Expected the handoff to be released, got %d", rr.Code)
+	}
+}
+
+func TestHandoffExpires(t *testing.T) {
+	oldUntil := handedOffUntil
+	defer func() { handedOffUntil = oldUntil }()
+
+	handedOffUntil = time.Now().Add(time.Minute).Unix()
+	if !handoffActive() {
+		t.Errorf("Expected the handoff to be active")
+	}
+	handedOffUntil = time.Now().Add(-time.Second).Unix()
+	if handoffActive() {
+		t.Errorf("Expected the handoff to have expired")
+	}
+}
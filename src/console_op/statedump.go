@@ -0,0 +1,160 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to dump the internal state of the operator
+//  for looking into a live problem after the fact
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"syscall"
+	"time"
+)
+
+// Directory the dumps triggered by SIGUSR1 are written to
+const stateDumpDir string = "/var/log/console/dumps"
+
+// StateDump - a snapshot of the internal state of the operator
+type StateDump struct {
+	Time             string           `json:"time"`
+	Health           HealthResponse   `json:"health"`
+	NumNodes         int              `json:"numnodes"`
+	NodesByClass     map[string]int   `json:"nodesbyclass"`
+	ReconcileStopped bool             `json:"reconcilestopped"`
+	ScalingFrozen    string           `json:"scalingfrozen,omitempty"`
+	SessionCheck     string           `json:"sessioncheck"`
+	SessionProblems  []sessionProblem `json:"sessionproblems"`
+	ExecFailures     int              `json:"execfailures"`
+	PendingKeyNodes  []string         `json:"pendingkeynodes"`
+	FailedKeyNodes   []string         `json:"failedkeynodes"`
+	NumGoroutines    int              `json:"numgoroutines"`
+}
+
+// Gather the state of the operator
+func buildStateDump(hs HealthService, now time.Time) StateDump {
+	sd := StateDump{Time: formatTime(now), NodesByClass: make(map[string]int)}
+	sd.Health = hs.getCurrentHealth()
+
+	// NOTE - not thread safe, but should be ok
+	for _, n := range nodeCache {
+		sd.NumNodes++
+		sd.NodesByClass[n.Class]++
+	}
+
+	sd.ReconcileStopped = reconcileStopped()
+	if frozen, reason := scalingFrozen(now); frozen {
+		sd.ScalingFrozen = reason
+	}
+
+	sessionsMutex.Lock()
+	sd.SessionCheck = sessionCheckTime
+	sd.SessionProblems = append([]sessionProblem{}, sessionProblems...)
+	sd.ExecFailures = numExecFailures
+	sessionsMutex.Unlock()
+
+	for _, n := range getPendingKeyNodes() {
+		sd.PendingKeyNodes = append(sd.PendingKeyNodes, n.NodeName)
+	}
+	sort.Strings(sd.PendingKeyNodes)
+	for _, ks := range getNodeKeyStatuses(keyDeployFailed).Nodes {
+		sd.FailedKeyNodes = append(sd.FailedKeyNodes, ks.Xname)
+	}
+	sort.Strings(sd.FailedKeyNodes)
+
+	sd.NumGoroutines = runtime.NumGoroutine()
+	return sd
+}
+
+// Write the state followed by the stacks of every goroutine
+func writeStateDump(out io.Writer, sd StateDump) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(sd); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\n")
+	return pprof.Lookup("goroutine").WriteTo(out, 2)
+}
+
+// Write a state dump to a new file in the dump directory
+func dumpStateToFile(hs HealthService, now time.Time) (string, error) {
+	if err := os.MkdirAll(stateDumpDir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(stateDumpDir, fmt.Sprintf("statedump-%s.txt", now.UTC().Format("20060102T150405Z")))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return path, writeStateDump(f, buildStateDump(hs, now))
+}
+
+// Write a state dump each time the process gets SIGUSR1
+func watchStateDumpSignal(ctx context.Context, hs HealthService) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGUSR1)
+	defer signal.Stop(sigs)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigs:
+			if path, err := dumpStateToFile(hs, time.Now()); err != nil {
+				log.Printf("Unable to write the state dump: %s", err)
+			} else {
+				log.Printf("Wrote the state dump to %s", path)
+			}
+		}
+	}
+}
+
+// Debugging only - return a state dump
+func (dm DebugManager) doStateDump(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	if err := writeStateDump(w, buildStateDump(dm.healthService, time.Now())); err != nil {
+		logError(errFileSystem, "Error writing the state dump: %s", err)
+	}
+}
@@ -0,0 +1,64 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildStateDump(t *testing.T) {
+	defer func(nc map[string]nodeConsoleInfo) { nodeCache = nc }(nodeCache)
+	nodeCache = map[string]nodeConsoleInfo{
+		"x3000c0s1b0n0": {NodeName: "x3000c0s1b0n0", Class: "River"},
+		"x3000c0s2b0n0": {NodeName: "x3000c0s2b0n0", Class: "River"},
+		"x1000c0s0b0n0": {NodeName: "x1000c0s0b0n0", Class: "Mountain"},
+	}
+	setPendingKeyNodes(map[string]nodeConsoleInfo{"x1000c0s0b0n0": nodeCache["x1000c0s0b0n0"]})
+	defer setPendingKeyNodes(nil)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	sd := buildStateDump(HealthManager{}, now)
+	if sd.Time != "2026-03-01T12:00:00Z" {
+		t.Errorf("Expected: 2026-03-01T12:00:00Z. Got: %s.", sd.Time)
+	}
+	if sd.NumNodes != 3 || sd.NodesByClass["River"] != 2 || sd.NodesByClass["Mountain"] != 1 {
+		t.Errorf("Unexpected node counts: %d %v", sd.NumNodes, sd.NodesByClass)
+	}
+	if len(sd.PendingKeyNodes) != 1 || sd.PendingKeyNodes[0] != "x1000c0s0b0n0" {
+		t.Errorf("Unexpected pending key nodes: %v", sd.PendingKeyNodes)
+	}
+
+	// the dump ends with the goroutine stacks
+	var out bytes.Buffer
+	if err := writeStateDump(&out, sd); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !strings.Contains(out.String(), "\"numnodes\": 3") || !strings.Contains(out.String(), "goroutine ") {
+		t.Errorf("Unexpected dump: %s", out.String())
+	}
+}
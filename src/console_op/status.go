@@ -0,0 +1,192 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to sum up the state of the service as a
+//  single OK/Degraded/Failed status for alerting
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Overall states
+const statusOK string = "OK"
+const statusDegraded string = "Degraded"
+const statusFailed string = "Failed"
+
+// StatusCheck - the result of one of the checks behind the overall status
+type StatusCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// StatusResponse - the overall state of the service
+type StatusResponse struct {
+	Status  string        `json:"status"`
+	Healthy bool          `json:"healthy"`
+	Checks  []StatusCheck `json:"checks"`
+}
+
+// What the status is worked out from
+type statusInputs struct {
+	dataErr          error
+	hsmErr           error
+	podsErr          error
+	numNodes         int
+	numUnassigned    int
+	numOnStalePods   int
+	lastHwUpdate     time.Time
+	numExecFailures  int
+	numStuckSessions int
+	numKeyFailures   int
+}
+
+// Work out the overall status from the checks, the worst check wins
+func classifyStatus(in statusInputs, now time.Time) StatusResponse {
+	resp := StatusResponse{Status: statusOK, Checks: []StatusCheck{}}
+	add := func(name, status, msg string) {
+		resp.Checks = append(resp.Checks, StatusCheck{Name: name, Status: status, Message: msg})
+		if status == statusFailed || (status == statusDegraded && resp.Status == statusOK) {
+			resp.Status = status
+		}
+	}
+
+	// NOTE: without console-data no nodes can be assigned to pods
+	if in.dataErr != nil {
+		add("consoledata", statusFailed, fmt.Sprintf("Unable to reach console-data: %s", in.dataErr))
+	} else {
+		add("consoledata", statusOK, "")
+		if in.numUnassigned > 0 {
+			add("unassignednodes", statusDegraded, fmt.Sprintf("%d of %d nodes are not assigned to a console-node pod", in.numUnassigned, in.numNodes))
+		} else {
+			add("unassignednodes", statusOK, "")
+		}
+		if in.podsErr != nil {
+			add("staleheartbeats", statusDegraded, fmt.Sprintf("Unable to get the console-node pods: %s", in.podsErr))
+		} else if in.numOnStalePods > 0 {
+			add("staleheartbeats", statusDegraded, fmt.Sprintf("%d nodes are held by console-node pods that are not running", in.numOnStalePods))
+		} else {
+			add("staleheartbeats", statusOK, "")
+		}
+	}
+	if in.hsmErr != nil {
+		add("hsm", statusDegraded, fmt.Sprintf("Unable to reach HSM: %s", in.hsmErr))
+	} else {
+		add("hsm", statusOK, "")
+	}
+
+	// the hardware update loop only runs on the leader
+	hwStale := time.Duration(3*newHardwareCheckPeriodSec) * time.Second
+	if amLeader() && !in.lastHwUpdate.IsZero() && now.Sub(in.lastHwUpdate) > hwStale {
+		add("hardwareupdate", statusDegraded, fmt.Sprintf("No hardware update since %s", formatTime(in.lastHwUpdate)))
+	} else {
+		add("hardwareupdate", statusOK, "")
+	}
+
+	// recent errors
+	if in.numExecFailures >= alertExecFailureThreshold {
+		add("execfailures", statusDegraded, fmt.Sprintf("%d consecutive failures to exec into console-node pods", in.numExecFailures))
+	} else {
+		add("execfailures", statusOK, "")
+	}
+	if in.numStuckSessions > 0 {
+		add("stucksessions", statusDegraded, fmt.Sprintf("%d stuck or duplicate console sessions", in.numStuckSessions))
+	} else {
+		add("stucksessions", statusOK, "")
+	}
+	if in.numKeyFailures > 0 {
+		add("keydeployment", statusDegraded, fmt.Sprintf("Console key deployment failed for %d nodes", in.numKeyFailures))
+	} else {
+		add("keydeployment", statusOK, "")
+	}
+
+	resp.Healthy = resp.Status == statusOK
+	return resp
+}
+
+// Gather what the status is worked out from
+func (hm HealthManager) getStatusInputs(reqID string) statusInputs {
+	var in statusInputs
+	inv, err := getDataInventory(reqID)
+	in.dataErr = err
+	if err == nil {
+		running := make(map[string]bool)
+		var podNames []string
+		if podNames, in.podsErr = hm.k8Service.getConsoleNodePods(); in.podsErr == nil {
+			for _, p := range podNames {
+				running[p] = true
+			}
+		}
+		in.numNodes = len(inv)
+		for _, n := range inv {
+			if n.NodeConsoleName == "" {
+				in.numUnassigned++
+			} else if in.podsErr == nil && !running[fmt.Sprintf("%s-%s", consoleNodeName, n.NodeConsoleName)] {
+				in.numOnStalePods++
+			}
+		}
+	}
+	_, rc, err := getURL(hsmSources[0].URL+"/service/ready", requestIDHeaders(reqID))
+	if err == nil && rc != http.StatusOK {
+		err = fmt.Errorf("HSM readiness returned status: %d", rc)
+	}
+	in.hsmErr = err
+	if t, err := time.Parse(time.RFC3339, hardwareUpdateTime); err == nil {
+		in.lastHwUpdate = t
+	}
+	sessionsMutex.Lock()
+	in.numExecFailures = numExecFailures
+	in.numStuckSessions = len(sessionProblems)
+	sessionsMutex.Unlock()
+	in.numKeyFailures = getNodeKeyStatuses(keyDeployFailed).NumFailed
+	return in
+}
+
+// Report the overall state of the service
+// NOTE: a Failed status is returned with a 503 so a plain http check can
+// alert on it
+func (hm HealthManager) doStatus(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	resp := classifyStatus(hm.getStatusInputs(getRequestID(r)), time.Now())
+	if resp.Status != statusOK {
+		log.Printf("Service status %s", resp.Status)
+	}
+	code := http.StatusOK
+	if resp.Status == statusFailed {
+		code = http.StatusServiceUnavailable
+	}
+	SendResponseJSON(w, code, resp)
+}
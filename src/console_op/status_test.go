@@ -0,0 +1,63 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestClassifyStatus(t *testing.T) {
+	now := time.Now()
+	ok := statusInputs{numNodes: 10, lastHwUpdate: now}
+	if resp := classifyStatus(ok, now); resp.Status != statusOK || !resp.Healthy {
+		t.Errorf("Expected: %s. Got: %s.", statusOK, resp.Status)
+	}
+
+	degraded := ok
+	degraded.numUnassigned = 2
+	degraded.hsmErr = fmt.Errorf("connection refused")
+	resp := classifyStatus(degraded, now)
+	if resp.Status != statusDegraded || resp.Healthy {
+		t.Errorf("Expected: %s. Got: %s.", statusDegraded, resp.Status)
+	}
+	numDegraded := 0
+	for _, c := range resp.Checks {
+		if c.Status == statusDegraded {
+			numDegraded++
+		}
+	}
+	if numDegraded != 2 {
+		t.Errorf("Expected: 2. Got: %d.", numDegraded)
+	}
+
+	// console-data being down wins over everything else
+	failed := degraded
+	failed.dataErr = fmt.Errorf("connection refused")
+	if resp := classifyStatus(failed, now); resp.Status != statusFailed {
+		t.Errorf("Expected: %s. Got: %s.", statusFailed, resp.Status)
+	}
+}
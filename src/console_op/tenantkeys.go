@@ -0,0 +1,261 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to give the mountain nodes of each tenant
+//  their own console key so a key from one tenant can not reach the
+//  consoles of another
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Use a separate console key for the nodes of each tenant
+// NOTE: requires a console-node that reads the tenant key map, an older
+// console-node only has the default key and loses the consoles of every
+// node moved to a tenant key
+var tenantKeys bool = false
+
+// File listing the key each tenant node uses so console-node can pick the
+// right key - nodes that are not listed use the default key
+const tenantKeyMapFile string = "/var/log/console/TenantKeys.txt"
+
+// Tenant names are used in file and vault key names
+var tenantNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Tenant that owns each mountain node with its own key
+var nodeTenants map[string]string = make(map[string]string)
+var nodeTenantsMutex sync.Mutex
+
+// Key files for a tenant
+func tenantKeyFiles(tenant string) (string, string) {
+	keyFile := fmt.Sprintf("/var/log/console/conman-%s.key", tenant)
+	return keyFile, keyFile + ".pub"
+}
+
+// Name of the vault key for a tenant
+func tenantVaultKeyName(tenant string) string {
+	return vaultBmcKeyName + "-" + tenant
+}
+
+// Key files a node should use
+func keyFilesForNode(node nodeConsoleInfo) (string, string) {
+	if tenantKeys {
+		nodeTenantsMutex.Lock()
+		tenant, found := nodeTenants[node.NodeName]
+		nodeTenantsMutex.Unlock()
+		if found {
+			return tenantKeyFiles(tenant)
+		}
+	}
+	return mountainConsoleKey, mountainConsoleKeyPub
+}
+
+// Work out which tenant key each mountain node should use
+// NOTE: the key is set on the bmc, so if the nodes of a bmc are not all in
+// the same single tenant they stay on the default key
+func calcNodeTenants(tenants []tapmsTenant, nodes map[string]nodeConsoleInfo) map[string]string {
+	owners := make(map[string][]string)
+	for _, t := range tenants {
+		name := t.Spec.TenantName
+		if name == "" {
+			name = t.Name
+		}
+		if !tenantNameRegex.MatchString(name) {
+			log.Printf("Tenant name %s can not be used for a console key", name)
+			continue
+		}
+		for _, tr := range t.Spec.TenantResources {
+			for _, x := range tr.Xnames {
+				owners[strings.ToLower(x)] = append(owners[strings.ToLower(x)], name)
+			}
+		}
+	}
+
+	// group the mountain nodes by bmc
+	bmcs := make(map[string][]nodeConsoleInfo)
+	for _, n := range nodes {
+		if n.isMountain() {
+			bmcs[n.BmcName] = append(bmcs[n.BmcName], n)
+		}
+	}
+
+	nt := make(map[string]string)
+	for bmc, bmcNodes := range bmcs {
+		tenant := ""
+		shared := false
+		for _, n := range bmcNodes {
+			o := owners[strings.ToLower(n.NodeName)]
+			if len(o) != 1 || (tenant != "" && o[0] != tenant) {
+				shared = true
+				break
+			}
+			tenant = o[0]
+		}
+		if shared {
+			if tenant != "" {
+				log.Printf("Nodes of bmc %s are not all in tenant %s, using the default key", bmc, tenant)
+			}
+			continue
+		}
+		for _, n := range bmcNodes {
+			nt[n.NodeName] = tenant
+		}
+	}
+	return nt
+}
+
+// Make sure the key files of a tenant exist, getting the key from vault
+func ensureTenantKey(vaultToken, tenant string) error {
+	keyFile, pubKeyFile := tenantKeyFiles(tenant)
+	_, errKey := os.Stat(keyFile)
+	_, errPub := os.Stat(pubKeyFile)
+	if errKey == nil && errPub == nil {
+		return nil
+	}
+	pvtKey, err := vaultGetPrivateKey(vaultToken, tenantVaultKeyName(tenant))
+	if err != nil {
+		return err
+	}
+	log.Printf("Obtained console key for tenant %s from vault", tenant)
+	return writeConsoleKeyFiles(pvtKey, keyFile, pubKeyFile)
+}
+
+// Write the file telling console-node which key each tenant node uses
+func writeTenantKeyMap(nt map[string]string) error {
+	xnames := make([]string, 0, len(nt))
+	for x := range nt {
+		xnames = append(xnames, x)
+	}
+	sort.Strings(xnames)
+	var sb strings.Builder
+	for _, x := range xnames {
+		keyFile, _ := tenantKeyFiles(nt[x])
+		fmt.Fprintf(&sb, "%s %s\n", x, keyFile)
+	}
+	return ioutil.WriteFile(tenantKeyMapFile, []byte(sb.String()), 0644)
+}
+
+// Refresh the tenant of each mountain node and redeploy the keys of the
+// nodes that moved to or from a tenant
+func (km KeyManager) refreshTenantKeys() {
+	tenants, err := km.tapmsService.getTenants("")
+	if err != nil {
+		log.Printf("Unable to get the tenants for console keys: %s", err)
+		return
+	}
+
+	// NOTE - not thread safe, but should be ok
+	nt := calcNodeTenants(tenants, nodeCache)
+
+	// nodes of a tenant without a usable key stay on the default key
+	vaultToken, err := vaultLogin()
+	if err != nil {
+		log.Printf("Unable to get the tenant console keys: %s", err)
+		return
+	}
+	badTenants := make(map[string]struct{})
+	for _, tenant := range nt {
+		if _, bad := badTenants[tenant]; bad {
+			continue
+		}
+		if err := ensureTenantKey(vaultToken, tenant); err != nil {
+			log.Printf("Unable to get the console key for tenant %s: %s", tenant, err)
+			badTenants[tenant] = struct{}{}
+		}
+	}
+	for x, tenant := range nt {
+		if _, bad := badTenants[tenant]; bad {
+			delete(nt, x)
+		}
+	}
+
+	// find the nodes that changed keys
+	changed := make(map[string]nodeConsoleInfo)
+	nodeTenantsMutex.Lock()
+	for x, tenant := range nt {
+		if nodeTenants[x] != tenant {
+			changed[x] = nodeCache[x]
+		}
+	}
+	for x := range nodeTenants {
+		if _, found := nt[x]; !found {
+			if n, ok := nodeCache[x]; ok {
+				changed[x] = n
+			}
+		}
+	}
+	nodeTenants = nt
+	nodeTenantsMutex.Unlock()
+
+	if err := writeTenantKeyMap(nt); err != nil {
+		log.Printf("Unable to write the tenant key map: %s", err)
+	}
+	if len(changed) > 0 {
+		log.Printf("Deploying console keys for %d nodes that changed tenant", len(changed))
+		remaining := doMountainCredsUpdate(changed)
+		keyRotationMutex.Lock()
+		for x, n := range remaining {
+			keyRotationPending[x] = n
+		}
+		keyRotationMutex.Unlock()
+	}
+}
+
+// Rotate the key of each tenant with nodes on its own key and stage it next
+// to the current one
+// NOTE: a tenant that can not be rotated keeps its current key
+func rotateTenantKeys(vaultToken string) {
+	tenants := make(map[string]struct{})
+	nodeTenantsMutex.Lock()
+	for _, tenant := range nodeTenants {
+		tenants[tenant] = struct{}{}
+	}
+	nodeTenantsMutex.Unlock()
+	for tenant := range tenants {
+		keyName := tenantVaultKeyName(tenant)
+		if err := vaultRotateKey(vaultToken, keyName); err != nil {
+			logError(errKeyRotation, "Unable to rotate the console key for tenant %s: %s", tenant, err)
+			continue
+		}
+		pvtKey, err := vaultGetPrivateKey(vaultToken, keyName)
+		if err != nil {
+			logError(errKeyRotation, "Unable to get the rotated console key for tenant %s: %s", tenant, err)
+			continue
+		}
+		keyFile, _ := tenantKeyFiles(tenant)
+		nextKey, nextKeyPub := nextKeyFiles(keyFile)
+		if err = writeConsoleKeyFiles(pvtKey, nextKey, nextKeyPub); err != nil {
+			logError(errKeyRotation, "Unable to stage the rotated console key for tenant %s: %s", tenant, err)
+		}
+	}
+}
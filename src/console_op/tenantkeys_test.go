@@ -0,0 +1,61 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"testing"
+)
+
+func TestCalcNodeTenants(t *testing.T) {
+	var tenants []tapmsTenant
+	blue := tapmsTenant{Name: "blue"}
+	blue.Spec.TenantName = "vcluster-blue"
+	blue.Spec.TenantResources = []tapmsTenantResource{{Xnames: []string{"x1000c0s0b0n0", "x1000c0s0b0n1", "x1000c0s1b0n0"}}}
+	red := tapmsTenant{Name: "red"}
+	red.Spec.TenantResources = []tapmsTenantResource{{Xnames: []string{"x1000c0s1b0n1", "x3000c0s1b0n0"}}}
+	tenants = append(tenants, blue, red)
+
+	nodes := map[string]nodeConsoleInfo{
+		"x1000c0s0b0n0": {NodeName: "x1000c0s0b0n0", BmcName: "x1000c0s0b0", Class: "Mountain"},
+		"x1000c0s0b0n1": {NodeName: "x1000c0s0b0n1", BmcName: "x1000c0s0b0", Class: "Mountain"},
+		"x1000c0s1b0n0": {NodeName: "x1000c0s1b0n0", BmcName: "x1000c0s1b0", Class: "Mountain"},
+		"x1000c0s1b0n1": {NodeName: "x1000c0s1b0n1", BmcName: "x1000c0s1b0", Class: "Mountain"},
+		"x3000c0s1b0n0": {NodeName: "x3000c0s1b0n0", BmcName: "x3000c0s1b0", Class: "River"},
+	}
+	nt := calcNodeTenants(tenants, nodes)
+
+	// only the bmc with both nodes in one tenant gets the tenant key
+	if len(nt) != 2 {
+		t.Errorf("Expected: 2. Got: %d.", len(nt))
+	}
+	for _, x := range []string{"x1000c0s0b0n0", "x1000c0s0b0n1"} {
+		if nt[x] != "vcluster-blue" {
+			t.Errorf("%s: Expected: vcluster-blue. Got: %s.", x, nt[x])
+		}
+	}
+	if _, found := nt["x1000c0s1b0n0"]; found {
+		t.Errorf("Expected the nodes of a shared bmc to use the default key")
+	}
+}
@@ -0,0 +1,228 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to look up which tenants own a node through
+//  the tenant and partition management service (TAPMS)
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Base url of the TAPMS api
+var tapmsAddrBase string = "http://cray-tapms/apis/tapms/v1"
+
+// Header naming the tenant a request is made for
+const tenantHeader string = "Cray-Tenant-Name"
+
+// https://github.com/Cray-HPE/cray-tapms-operator - only the fields needed here
+type tapmsTenantResource struct {
+	Type          string   `json:"type"`
+	HsmGroupLabel string   `json:"hsmgrouplabel"`
+	Xnames        []string `json:"xnames"`
+}
+
+type tapmsTenant struct {
+	Name string `json:"name"`
+	Spec struct {
+		TenantName      string                `json:"tenantname"`
+		State           string                `json:"state"`
+		TenantResources []tapmsTenantResource `json:"tenantresources"`
+	} `json:"spec"`
+}
+
+// NodeTenant - a tenant that owns a node
+type NodeTenant struct {
+	Tenant        string `json:"tenant"`
+	State         string `json:"state"`
+	Type          string `json:"type"`
+	HsmGroupLabel string `json:"hsmgrouplabel"`
+}
+
+// GetNodeTenantsResponse - the tenants that own a node
+type GetNodeTenantsResponse struct {
+	Xname   string       `json:"xname"`
+	Tenants []NodeTenant `json:"tenants"`
+}
+
+type TapmsService interface {
+	getTenants(reqID string) ([]tapmsTenant, error)
+}
+
+// implements TapmsService
+type TapmsManager struct {
+	baseUrl string
+}
+
+func NewTapmsManager() TapmsService {
+	readSingleEnvVarString("TAPMS_URL", &tapmsAddrBase)
+	tapmsAddrBase = strings.TrimSuffix(tapmsAddrBase, "/")
+	return &TapmsManager{baseUrl: tapmsAddrBase}
+}
+
+// Get all the tenants from TAPMS
+func (tm TapmsManager) getTenants(reqID string) ([]tapmsTenant, error) {
+	URL := tm.baseUrl + "/tenants"
+	data, sc, err := getURL(URL, requestIDHeaders(reqID))
+	if err != nil {
+		logError(errTAPMS, "Error: GET %s to tapms failed %s", URL, err)
+		return nil, err
+	}
+	if sc != http.StatusOK {
+		return nil, fmt.Errorf("GET %s to tapms returned status: %d", URL, sc)
+	}
+
+	var tenants []tapmsTenant
+	if err = json.Unmarshal(data, &tenants); err != nil {
+		logError(errTAPMS, "Error unmarshalling tapms tenants: %s", err)
+		return nil, err
+	}
+	return tenants, nil
+}
+
+// Find the tenants that own a node
+func tenantsForNode(tenants []tapmsTenant, xname string) []NodeTenant {
+	nodeTenants := []NodeTenant{}
+	for _, t := range tenants {
+		name := t.Spec.TenantName
+		if name == "" {
+			name = t.Name
+		}
+		for _, tr := range t.Spec.TenantResources {
+			for _, x := range tr.Xnames {
+				if strings.EqualFold(x, xname) {
+					nodeTenants = append(nodeTenants, NodeTenant{
+						Tenant:        name,
+						State:         t.Spec.State,
+						Type:          tr.Type,
+						HsmGroupLabel: tr.HsmGroupLabel,
+					})
+				}
+			}
+		}
+	}
+	sort.Slice(nodeTenants, func(i, j int) bool { return nodeTenants[i].Tenant < nodeTenants[j].Tenant })
+	return nodeTenants
+}
+
+// Check a tenant, by name or tenant name, owns a node
+func tenantOwnsNode(tenants []tapmsTenant, tenant, xname string) bool {
+	for _, t := range tenants {
+		if t.Name != tenant && t.Spec.TenantName != tenant {
+			continue
+		}
+		for _, tr := range t.Spec.TenantResources {
+			for _, x := range tr.Xnames {
+				if strings.EqualFold(x, xname) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+type TenantService interface {
+	doGetNodeTenants(w http.ResponseWriter, r *http.Request)
+	doFederationProxy(w http.ResponseWriter, r *http.Request)
+	tenantNodeAccess(next http.Handler) http.Handler
+}
+
+// Implements TenantService
+type TenantManager struct {
+	tapmsService TapmsService
+}
+
+// Constructor injection for dependencies
+func NewTenantManager(ts TapmsService) TenantService {
+	return &TenantManager{tapmsService: ts}
+}
+
+// Find which tenants own a node
+// NOTE: a node with no tenants is not owned by any tenant, not an error
+func (tm TenantManager) doGetNodeTenants(w http.ResponseWriter, r *http.Request) {
+	// only allow 'GET' calls
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		sendJSONError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("(%s) Not Allowed", r.Method))
+		return
+	}
+
+	xname := chi.URLParam(r, "xname")
+	if xname == "" {
+		sendJSONError(w, http.StatusBadRequest, "Missing node xname")
+		return
+	}
+
+	tenants, err := tm.tapmsService.getTenants(getRequestID(r))
+	if err != nil {
+		sendJSONError(w, http.StatusServiceUnavailable,
+			fmt.Sprintf("Unable to get tenants from tapms: %s", err))
+		return
+	}
+
+	SendResponseJSON(w, http.StatusOK, GetNodeTenantsResponse{
+		Xname:   xname,
+		Tenants: tenantsForNode(tenants, xname),
+	})
+}
+
+// Limit a caller acting for a tenant to the consoles of that tenant's nodes
+// NOTE: this is also what checks requests forwarded by the federation proxy
+// of another system, which can not know the tenants of the nodes here
+func (tm TenantManager) tenantNodeAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant := r.Header.Get(tenantHeader)
+		if tenant == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		xname := chi.URLParam(r, "xname")
+		if xname == "" {
+			sendJSONError(w, http.StatusForbidden,
+				fmt.Sprintf("Tenant %s may only access node logs", tenant))
+			return
+		}
+		tenants, err := tm.tapmsService.getTenants(getRequestID(r))
+		if err != nil {
+			sendJSONError(w, http.StatusServiceUnavailable,
+				fmt.Sprintf("Unable to get tenants from tapms: %s", err))
+			return
+		}
+		if !tenantOwnsNode(tenants, tenant, xname) {
+			sendJSONError(w, http.StatusForbidden,
+				fmt.Sprintf("Node %s is not owned by tenant %s", xname, tenant))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
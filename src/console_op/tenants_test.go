@@ -0,0 +1,120 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type TapmsGetTenantsMock struct {
+	TapmsManager
+}
+
+func (TapmsGetTenantsMock) getTenants(reqID string) ([]tapmsTenant, error) {
+	var tenants []tapmsTenant
+	json.Unmarshal([]byte(`[
+		{"name":"blue","spec":{"tenantname":"vcluster-blue","state":"Deployed","tenantresources":[
+			{"type":"compute","hsmgrouplabel":"blue","xnames":["x1000c0s0b0n0","x1000c0s0b0n1"]}]}},
+		{"name":"red","spec":{"tenantname":"vcluster-red","state":"Deploying","tenantresources":[
+			{"type":"application","hsmgrouplabel":"red","xnames":["x1000c0s0b0n1"]}]}}
+	]`), &tenants)
+	return tenants, nil
+}
+
+func TestDoGetNodeTenants(t *testing.T) {
+	tests := []struct {
+		xname    string
+		expected []string
+	}{
+		{"x1000c0s0b0n0", []string{"vcluster-blue"}},
+		{"x1000c0s0b0n1", []string{"vcluster-blue", "vcluster-red"}},
+		{"x3000c0s1b0n0", []string{}},
+	}
+	tm := NewTenantManager(TapmsGetTenantsMock{})
+	for _, tc := range tests {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/console-operator/nodes/{xname}/tenants", nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("xname", tc.xname)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		http.HandlerFunc(tm.doGetNodeTenants).ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("Handler returned incorrect status code. Expected: %d Got: %d", http.StatusOK, status)
+		}
+		var resp GetNodeTenantsResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Errorf("Error decoding response body: %v", err)
+		}
+		if len(resp.Tenants) != len(tc.expected) {
+			t.Errorf("%s: Expected: %d. Got: %d.", tc.xname, len(tc.expected), len(resp.Tenants))
+			continue
+		}
+		for i, name := range tc.expected {
+			if resp.Tenants[i].Tenant != name {
+				t.Errorf("%s: Expected: %s. Got: %s.", tc.xname, name, resp.Tenants[i].Tenant)
+			}
+		}
+	}
+}
+
+func TestTenantNodeAccess(t *testing.T) {
+	tm := TenantManager{tapmsService: TapmsGetTenantsMock{}}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	r := chi.NewRouter()
+	r.With(tm.tenantNodeAccess).Get("/console-operator/v1/logs/{xname}/tail", ok)
+	r.With(tm.tenantNodeAccess).Get("/console-operator/v1/location/{podID}", ok)
+
+	tests := []struct {
+		path     string
+		tenant   string
+		expected int
+	}{
+		{"/console-operator/v1/logs/x1000c0s0b0n0/tail", "", http.StatusOK},
+		{"/console-operator/v1/logs/x1000c0s0b0n0/tail", "vcluster-blue", http.StatusOK},
+		{"/console-operator/v1/logs/x1000c0s0b0n0/tail", "red", http.StatusForbidden},
+		{"/console-operator/v1/location/cray-console-node-0", "", http.StatusOK},
+		{"/console-operator/v1/location/cray-console-node-0", "vcluster-blue", http.StatusForbidden},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if tc.tenant != "" {
+			req.Header.Set(tenantHeader, tc.tenant)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != tc.expected {
+			t.Errorf("%s (%s): Expected: %d. Got: %d.", tc.path, tc.tenant, tc.expected, rr.Code)
+		}
+	}
+}
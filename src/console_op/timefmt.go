@@ -0,0 +1,62 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the shared formatting of the times reported by the
+//  apis, events, and logs
+
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// Format a time as RFC3339 in UTC, a zero time is reported as empty
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// Writer for the log that puts an RFC3339 UTC time in front of each line
+type utcLogWriter struct {
+	w io.Writer
+}
+
+func (lw utcLogWriter) Write(p []byte) (int, error) {
+	line := append([]byte(formatTime(time.Now())+" "), p...)
+	if _, err := lw.w.Write(line); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Time stamp the log lines the same way as the api times
+func setupLogTimes() {
+	log.SetFlags(0)
+	log.SetOutput(utcLogWriter{w: os.Stderr})
+}
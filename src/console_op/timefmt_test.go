@@ -0,0 +1,51 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatTime(t *testing.T) {
+	loc := time.FixedZone("CST", -6*60*60)
+	if got := formatTime(time.Date(2026, 1, 2, 3, 4, 5, 6, loc)); got != "2026-01-02T09:04:05Z" {
+		t.Errorf("Expected: 2026-01-02T09:04:05Z. Got: %s.", got)
+	}
+	if got := formatTime(time.Time{}); got != "" {
+		t.Errorf("Expected an empty time. Got: %s.", got)
+	}
+
+	var buf bytes.Buffer
+	lw := utcLogWriter{w: &buf}
+	if n, err := lw.Write([]byte("message\n")); n != 8 || err != nil {
+		t.Errorf("Expected: 8. Got: %d %v.", n, err)
+	}
+	if _, err := time.Parse(time.RFC3339, strings.Fields(buf.String())[0]); err != nil {
+		t.Errorf("Expected an RFC3339 time. Got: %s.", buf.String())
+	}
+}
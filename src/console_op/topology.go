@@ -0,0 +1,164 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+// This file contains the code to give the scheduler hints on where to place
+//  the console-node pods based on where the hardware they serve is located
+
+package main
+
+import (
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Global var to enable setting placement hints on the console-node workload
+// NOTE: changing the hints changes the pod template so the pods are
+// restarted, the hints are only pushed when they change
+var topologyHints bool = false
+
+// Prefix of the aliases of the k8s worker nodes in sls
+const workerAliasPrefix string = "ncn-w"
+
+// Find the preferred worker nodes for the console-node pods.  Workers in a
+// cabinet holding nodes to be served are preferred, weighted by how many
+// nodes are in that cabinet, since the BMCs are reached through the network
+// equipment of their cabinet.
+func calcPlacementHints(nodes map[string]nodeConsoleInfo, aliases []XnameNodeAlias) []corev1.PreferredSchedulingTerm {
+	// count the nodes in each cabinet
+	cabCounts := make(map[string]int)
+	maxCount := 0
+	for _, n := range nodes {
+		cab := cabinetRegex.FindString(n.NodeName)
+		if cab == "" {
+			continue
+		}
+		cabCounts[cab]++
+		if cabCounts[cab] > maxCount {
+			maxCount = cabCounts[cab]
+		}
+	}
+	if maxCount == 0 {
+		return nil
+	}
+
+	// weight the workers by the nodes in their cabinet
+	var terms []corev1.PreferredSchedulingTerm = nil
+	for _, xa := range aliases {
+		if !strings.HasPrefix(xa.alias, workerAliasPrefix) {
+			continue
+		}
+		cnt := cabCounts[cabinetRegex.FindString(xa.xname)]
+		if cnt == 0 {
+			continue
+		}
+		weight := int32(cnt * 100 / maxCount)
+		if weight < 1 {
+			weight = 1
+		}
+		terms = append(terms, corev1.PreferredSchedulingTerm{
+			Weight: weight,
+			Preference: corev1.NodeSelectorTerm{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key:      "kubernetes.io/hostname",
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{xa.alias},
+				}},
+			},
+		})
+	}
+
+	// keep a stable order so unchanged hints are not pushed again
+	sort.Slice(terms, func(i, j int) bool {
+		return terms[i].Preference.MatchExpressions[0].Values[0] < terms[j].Preference.MatchExpressions[0].Values[0]
+	})
+	return terms
+}
+
+// Set the placement hints on the pod template of every console-node pool
+func (k8s K8Manager) updatePlacementHints(terms []corev1.PreferredSchedulingTerm) {
+	for _, pool := range getConsoleNodePools() {
+		k8s.updatePoolPlacementHints(pool, terms)
+	}
+}
+
+// Set the placement hints on the pod template of a pool if they changed
+func (k8s K8Manager) updatePoolPlacementHints(pool consoleNodePool, terms []corev1.PreferredSchedulingTerm) {
+	wl, err := k8s.getPoolWorkload(pool)
+	if err != nil {
+		return
+	}
+	var spec *corev1.PodSpec
+	if wl.deployment != nil {
+		spec = &wl.deployment.Spec.Template.Spec
+	} else {
+		spec = &wl.statefulSet.Spec.Template.Spec
+	}
+
+	// spread the pods over the workers as well as preferring the ones
+	// close to the hardware
+	spread := []corev1.TopologySpreadConstraint{{
+		MaxSkew:           1,
+		TopologyKey:       "kubernetes.io/hostname",
+		WhenUnsatisfiable: corev1.ScheduleAnyway,
+		LabelSelector:     wl.Selector,
+	}}
+
+	var currTerms []corev1.PreferredSchedulingTerm = nil
+	if spec.Affinity != nil && spec.Affinity.NodeAffinity != nil {
+		currTerms = spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	}
+	if reflect.DeepEqual(currTerms, terms) && reflect.DeepEqual(spec.TopologySpreadConstraints, spread) {
+		return
+	}
+
+	log.Printf("Updating %s placement hints for %d workers", pool.Name, len(terms))
+	if spec.Affinity == nil {
+		spec.Affinity = &corev1.Affinity{}
+	}
+	if spec.Affinity.NodeAffinity == nil {
+		spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = terms
+	spec.TopologySpreadConstraints = spread
+	if err := k8s.updateConsoleNodeWorkload(wl); err != nil {
+		logError(errK8s, "Error updating %s placement hints: %s", pool.Name, err)
+		return
+	}
+	k8s.recordEvent(eventOnConsoleNode, corev1.EventTypeNormal, "PlacementHintsUpdated",
+		"Updated the preferred workers based on the location of the hardware")
+}
+
+// Recalculate and push the placement hints for the given nodes
+func (nm NodeManager) updatePlacementHints(nodes map[string]nodeConsoleInfo) {
+	aliases, err := nm.slsService.getXnameAlias()
+	if err != nil {
+		log.Printf("Unable to get worker locations from sls, skipping placement hints: %s", err)
+		return
+	}
+	nm.k8Service.updatePlacementHints(calcPlacementHints(nodes, aliases))
+}
@@ -0,0 +1,65 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"testing"
+)
+
+func TestCalcPlacementHints(t *testing.T) {
+	nodes := map[string]nodeConsoleInfo{
+		"x3000c0s1b0n0": {NodeName: "x3000c0s1b0n0"},
+		"x3000c0s2b0n0": {NodeName: "x3000c0s2b0n0"},
+		"x3000c0s3b0n0": {NodeName: "x3000c0s3b0n0"},
+		"x3000c0s4b0n0": {NodeName: "x3000c0s4b0n0"},
+		"x3001c0s1b0n0": {NodeName: "x3001c0s1b0n0"},
+	}
+	aliases := []XnameNodeAlias{
+		{xname: "x3001c0s9b0n0", alias: "ncn-w002"},
+		{xname: "x3000c0s9b0n0", alias: "ncn-w001"},
+		{xname: "x3002c0s9b0n0", alias: "ncn-w003"}, // no hardware in this cabinet
+		{xname: "x3000c0s8b0n0", alias: "ncn-m001"}, // not a worker
+	}
+
+	terms := calcPlacementHints(nodes, aliases)
+	expected := map[string]int32{"ncn-w001": 100, "ncn-w002": 25}
+	if len(terms) != len(expected) {
+		t.Fatalf("Expected: %d terms. Got: %d.", len(expected), len(terms))
+	}
+	if terms[0].Preference.MatchExpressions[0].Values[0] != "ncn-w001" {
+		t.Errorf("Expected: ncn-w001 first. Got: %s.", terms[0].Preference.MatchExpressions[0].Values[0])
+	}
+	for _, term := range terms {
+		worker := term.Preference.MatchExpressions[0].Values[0]
+		if term.Weight != expected[worker] {
+			t.Errorf("%s: Expected: %d. Got: %d.", worker, expected[worker], term.Weight)
+		}
+	}
+
+	// no hardware means no hints
+	if terms := calcPlacementHints(nil, aliases); terms != nil {
+		t.Errorf("Expected: nil. Got: %v.", terms)
+	}
+}
@@ -1,7 +1,7 @@
 //
 //  MIT License
 //
-//  (C) Copyright 2021-2022 Hewlett Packard Enterprise Development LP
+//  (C) Copyright 2021-2022, 2026 Hewlett Packard Enterprise Development LP
 //
 //  Permission is hereby granted, free of charge, to any person obtaining a
 //  copy of this software and associated documentation files (the "Software"),
@@ -27,89 +27,323 @@
 package main
 
 import (
-	"bytes"
-	"io"
+	"context"
+	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
-	"os/exec"
+	"os/signal"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 )
 
 // NOTE:  Any time a container is started with a particular application running
 //  as nid0, that process is required to handle any zombie processes that are
-//  orphaned in the pod.  This is a process running in the background that will
-//  find zombie processes and terminate them cleanly.
+//  orphaned in the pod.  This process registers itself as a child subreaper so
+//  orphaned descendants are re-parented here, then waits for the kernel to
+//  signal (SIGCHLD) that a child has exited and cleans up any zombies.
 
-// Function to scan the process table for zombie processes
-func watchForZombies() {
-	for {
-		// get the process information from the system
-		zombies := findZombies()
-		// look for zombies and terminate them
-		for _, zombie := range zombies {
-			// kill each zombie in a separate thread
-			go killZombie(zombie)
+// Linux prctl option to mark a process as a child subreaper
+const prSetChildSubreaper = 36
+
+// Time to let children started through os/exec be collected by their own
+// Wait call before scanning for zombies
+const zombieSettleTime = 1 * time.Second
+
+// Number of worker threads waiting on zombie processes - this bounds the
+// number of threads used even if a large number of zombies show up at once
+const numZombieWorkers int = 4
+
+// Queue of zombie processes waiting to be cleaned up
+var zombieQueue = make(chan zombieProcess, 100)
+
+// Set of pids that are queued or being waited on so a zombie found by more
+// than one scan is only waited on once
+var zombiesInFlight = make(map[int]struct{})
+var zombiesInFlightMutex sync.Mutex
+
+// Maximum number of reaped zombie records kept for diagnostics
+const maxZombieHistory int = 100
+
+// Parent of each process seen by the last scan so an orphan re-parented
+// here can still report where it came from
+// NOTE: only used by the zombie watch thread
+var zombieScanParents map[int]int = make(map[int]int)
+
+// History of reaped zombies - oldest first
+var zombieHistory []zombieProcess = nil
+var zombieHistoryMutex sync.Mutex
+
+// Interface to the process table so the zombie handling can be tested
+// without real processes
+type ProcessService interface {
+	listPids() ([]int, error)
+	readStat(pid int) (string, error)
+	readCmdLine(pid int) (string, error)
+	waitPid(pid int) error
+}
+
+// Implements ProcessService using the /proc file system
+type ProcessManager struct{}
+
+// Constructor for the process table access
+func NewProcessManager() ProcessService {
+	return &ProcessManager{}
+}
+
+// List the pids of all current processes
+func (ProcessManager) listPids() ([]int, error) {
+	procDirs, err := ioutil.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	var pids []int = nil
+	for _, pd := range procDirs {
+		// only the numeric entries are processes
+		if pid, err := strconv.Atoi(pd.Name()); err == nil {
+			pids = append(pids, pid)
 		}
-		// wait for a bit before looking again
-		time.Sleep(30 * time.Second)
 	}
+	return pids, nil
+}
+
+// Read the stat information of a process
+func (ProcessManager) readStat(pid int) (string, error) {
+	stat, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	return string(stat), err
 }
 
-// Find all the current zombie processes
-func findZombies() []int {
-	var zombies []int = nil
-	var outBuf bytes.Buffer
-	// Use a 'ps -eo' style command as the basis to search for zombie processes
-	// and put the output in outBuf.
-	cmd := exec.Command("ps", "-eo", "pid,stat")
-	cmd.Stderr = &outBuf
-	cmd.Stdout = &outBuf
-	err := cmd.Run()
+// Read the command line of a process with the arguments space separated
+func (ProcessManager) readCmdLine(pid int) (string, error) {
+	cmdLine, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	return strings.TrimSpace(strings.ReplaceAll(string(cmdLine), "\x00", " ")), err
+}
+
+// Wait for (reap) a child process
+func (ProcessManager) waitPid(pid int) error {
+	p, err := os.FindProcess(pid)
 	if err != nil {
-		log.Printf("Error getting current processes: %s", err)
+		return err
+	}
+	_, err = p.Wait()
+	return err
+}
+
+// Struct to hold the information captured about a zombie process
+// NOTE: the kernel releases the command line of a process when it exits so
+// CmdLine is usually empty for a zombie - Command is the name the kernel
+// keeps in the stat information and is always present.  PPid is the parent
+// read from the stat information before the zombie is reaped - an orphan is
+// re-parented to this process when its parent exits, so if it was seen with
+// a different parent by an earlier scan that original parent is kept.  The
+// process group and session are also kept from the original parent so are
+// the best clue to where an orphaned process came from.
+type zombieProcess struct {
+	Pid     int    `json:"pid"`
+	PPid    int    `json:"ppid"`
+	PGrp    int    `json:"pgrp"`
+	Session int    `json:"session"`
+	Command string `json:"command"`
+	CmdLine string `json:"cmdline"`
+	Found   string `json:"found"`
+	Reaped  string `json:"reaped"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Provide a function to convert struct to string
+func (zp zombieProcess) String() string {
+	return fmt.Sprintf("Pid:%d, PPid:%d, PGrp:%d, Session:%d, Command:%s, CmdLine:%s",
+		zp.Pid, zp.PPid, zp.PGrp, zp.Session, zp.Command, zp.CmdLine)
+}
+
+// Function to reap zombie processes as child processes exit
+func watchForZombies(ctx context.Context, ps ProcessService) {
+	// become a subreaper so orphans end up as our children and can be reaped
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prSetChildSubreaper, 1, 0); errno != 0 {
+		logError(errZombie, "Error setting process as a child subreaper: %s", errno)
 	}
-	// process the output buffer to find zombies
-	var readLine string
+
+	// register for child exit notifications before the first scan so
+	// nothing exiting in between is missed
+	sigChld := make(chan os.Signal, 1)
+	signal.Notify(sigChld, syscall.SIGCHLD)
+	defer signal.Stop(sigChld)
+
+	// start the workers that do the actual clean up
+	for i := 0; i < numZombieWorkers; i++ {
+		go zombieWorker(ctx, ps)
+	}
+
+	// clean up anything left from before we started watching
+	reapZombies(ctx, ps)
+
+	// NOTE: signals are coalesced so one notification may cover several
+	//  exited children - each scan reaps everything currently present
 	for {
-		// pull off a line of output and
-		if readLine, err = outBuf.ReadString('\n'); err == io.EOF {
-			break
-		} else if err != nil {
-			log.Printf("Error reading current process output: %s", err)
-			break
+		select {
+		case <-ctx.Done():
+			log.Printf("Stopping zombie process watch")
+			return
+		case <-sigChld:
+			if !sleepCtx(ctx, zombieSettleTime) {
+				log.Printf("Stopping zombie process watch")
+				return
+			}
+			reapZombies(ctx, ps)
 		}
-		// NOTE: a 'STATUS' of "Z" denotes a zombie process
-		cols := strings.Fields(readLine)
-		if len(cols) >= 2 && cols[1] == "Z" {
-			// found a zombie
-			zPid, err := strconv.Atoi(cols[0])
-			if err == nil {
-				log.Printf("Found a zombie process: %d", zPid)
-				zombies = append(zombies, zPid)
-			} else {
-				// atoi did not like our process "number"
-				log.Printf("Thought we had a zombie, couldn't get pid:%s", readLine)
+	}
+}
+
+// Find all current zombie child processes and queue them for clean up
+func reapZombies(ctx context.Context, ps ProcessService) {
+	zombies := findZombies(ps)
+	for _, zombie := range zombies {
+		// skip any zombie that is already being handled
+		zombiesInFlightMutex.Lock()
+		_, found := zombiesInFlight[zombie.Pid]
+		if !found {
+			zombiesInFlight[zombie.Pid] = struct{}{}
+		}
+		zombiesInFlightMutex.Unlock()
+		if found {
+			continue
+		}
+
+		// NOTE: this blocks if the queue is full, that is ok since further
+		//  SIGCHLD notifications are coalesced while the workers catch up
+		select {
+		case zombieQueue <- zombie:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Worker thread to clean up queued zombie processes
+func zombieWorker(ctx context.Context, ps ProcessService) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case zombie := <-zombieQueue:
+			cleanupZombie(ps, zombie)
+		}
+	}
+}
+
+// Clean up a single queued zombie and record the result
+func cleanupZombie(ps ProcessService, zombie zombieProcess) {
+	if err := killZombie(ps, zombie.Pid); err != nil {
+		zombie.Error = err.Error()
+	}
+	zombie.Reaped = formatTime(time.Now())
+	recordZombie(zombie)
+
+	// done with this pid - it may be reused by a new process
+	zombiesInFlightMutex.Lock()
+	delete(zombiesInFlight, zombie.Pid)
+	zombiesInFlightMutex.Unlock()
+}
+
+// Add a reaped zombie to the history, dropping the oldest if full
+func recordZombie(zombie zombieProcess) {
+	zombieHistoryMutex.Lock()
+	defer zombieHistoryMutex.Unlock()
+	zombieHistory = append(zombieHistory, zombie)
+	if len(zombieHistory) > maxZombieHistory {
+		zombieHistory = zombieHistory[len(zombieHistory)-maxZombieHistory:]
+	}
+}
+
+// Get a copy of the reaped zombie history
+func getZombieHistory() []zombieProcess {
+	zombieHistoryMutex.Lock()
+	defer zombieHistoryMutex.Unlock()
+	history := make([]zombieProcess, len(zombieHistory))
+	copy(history, zombieHistory)
+	return history
+}
+
+// Find all the current zombie processes that are children of this process
+func findZombies(ps ProcessService) []zombieProcess {
+	var zombies []zombieProcess = nil
+	// NOTE: read the process table directly rather than running 'ps' - the
+	//  exit of a 'ps' child would itself trigger another SIGCHLD scan
+	pids, err := ps.listPids()
+	if err != nil {
+		logError(errZombie, "Error getting current processes: %s", err)
+		return nil
+	}
+	myPid := os.Getpid()
+	parents := make(map[int]int, len(pids))
+	defer func() { zombieScanParents = parents }()
+	for _, pid := range pids {
+		// process may have exited since the list was read
+		stat, err := ps.readStat(pid)
+		if err != nil {
+			continue
+		}
+		zp, isZombie, err := parseProcStat(pid, stat)
+		if err != nil {
+			log.Printf("%s", err)
+			continue
+		}
+		parents[pid] = zp.PPid
+		// only our own children can be reaped from here
+		if isZombie && zp.PPid == myPid {
+			if origPPid, found := zombieScanParents[pid]; found && origPPid != myPid {
+				zp.PPid = origPPid
+			}
+			// NOTE: the zombie still holds the pid so this can not be
+			//  picking up information from a different process
+			if cmdLine, err := ps.readCmdLine(pid); err == nil {
+				zp.CmdLine = cmdLine
 			}
+			zp.Found = formatTime(time.Now())
+			log.Printf("Found a zombie process: %s", zp)
+			zombies = append(zombies, zp)
 		}
 	}
 	return zombies
 }
 
+// Parse the contents of a /proc/<pid>/stat file
+func parseProcStat(pid int, stat string) (zp zombieProcess, isZombie bool, err error) {
+	// NOTE: stat format is 'pid (comm) state ppid pgrp session ...' and comm
+	//  may contain spaces so split after the closing paren.  A state of
+	//  "Z" denotes a zombie process.
+	zp.Pid = pid
+	start := strings.Index(stat, "(")
+	end := strings.LastIndex(stat, ")")
+	if start < 0 || end < start {
+		return zp, false, fmt.Errorf("Unexpected stat format for process %d: %s", pid, stat)
+	}
+	zp.Command = stat[start+1 : end]
+	cols := strings.Fields(stat[end+1:])
+	if len(cols) < 4 {
+		return zp, false, fmt.Errorf("Unexpected stat format for process %d: %s", pid, stat)
+	}
+	if zp.PPid, err = strconv.Atoi(cols[1]); err != nil {
+		return zp, false, fmt.Errorf("Unable to parse parent pid for process %d: %s", pid, err)
+	}
+	// the process group and session are only informational
+	zp.PGrp, _ = strconv.Atoi(cols[2])
+	zp.Session, _ = strconv.Atoi(cols[3])
+	return zp, cols[0] == "Z", nil
+}
+
 // Kill (wait for) the zombie process with the given pid
-func killZombie(pid int) {
+func killZombie(ps ProcessService, pid int) error {
 	log.Printf("Killing zombie process: %d", pid)
-	p, err := os.FindProcess(pid)
-	if err != nil {
-		log.Printf("Error attaching to zombie process %d, err:%s", pid, err)
-		return
-	}
 	// should just need to get the exit state to clean up process
-	_, err = p.Wait()
+	err := ps.waitPid(pid)
 	if err != nil {
-		log.Printf("Error waiting for zombie process %d, err:%s", pid, err)
-		return
+		logError(errZombie, "Error waiting for zombie process %d, err:%s", pid, err)
+		return err
 	}
 	log.Printf("Cleaned up zombie process: %d", pid)
+	return nil
 }
@@ -0,0 +1,223 @@
+//
+//  MIT License
+//
+//  (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+//  Permission is hereby granted, free of charge, to any person obtaining a
+//  copy of this software and associated documentation files (the "Software"),
+//  to deal in the Software without restriction, including without limitation
+//  the rights to use, copy, modify, merge, publish, distribute, sublicense,
+//  and/or sell copies of the Software, and to permit persons to whom the
+//  Software is furnished to do so, subject to the following conditions:
+//
+//  The above copyright notice and this permission notice shall be included
+//  in all copies or substantial portions of the Software.
+//
+//  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+//  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+//  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+//  THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+//  OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+//  ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+//  OTHER DEALINGS IN THE SOFTWARE.
+//
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// Fake process table for testing the zombie handling
+type ProcessMock struct {
+	pids     []int
+	listErr  error
+	stats    map[int]string
+	statErrs map[int]error
+	waitErrs map[int]error
+	waited   []int
+}
+
+func (pm *ProcessMock) listPids() ([]int, error) {
+	return pm.pids, pm.listErr
+}
+
+func (pm *ProcessMock) readStat(pid int) (string, error) {
+	if err, ok := pm.statErrs[pid]; ok {
+		return "", err
+	}
+	return pm.stats[pid], nil
+}
+
+func (pm *ProcessMock) readCmdLine(pid int) (string, error) {
+	// the kernel releases the command line of an exited process
+	return "", nil
+}
+
+func (pm *ProcessMock) waitPid(pid int) error {
+	pm.waited = append(pm.waited, pid)
+	return pm.waitErrs[pid]
+}
+
+func TestFindZombies(t *testing.T) {
+	myPid := os.Getpid()
+	pm := &ProcessMock{
+		pids: []int{10, 11, 12, 13, 14, 15, 16, 17},
+		stats: map[int]string{
+			// zombie child - comm with spaces and parens
+			10: fmt.Sprintf("10 (conman (ssh) x) Z %d 10 1 0 -1", myPid),
+			// zombie with a different parent can not be reaped here
+			11: "11 (sh) Z 1 11 1 0 -1",
+			// running child
+			12: fmt.Sprintf("12 (sleep) S %d 12 1 0 -1", myPid),
+			// malformed entries
+			13: "13 sh Z",
+			14: fmt.Sprintf("14 (sh) Z %d", myPid),
+			15: "15 (sh) Z notapid 15 1 0 -1",
+			16: "",
+		},
+		statErrs: map[int]error{
+			// permission denied and exited before the stat was read
+			17: os.ErrPermission,
+		},
+	}
+
+	zombies := findZombies(pm)
+	if len(zombies) != 1 {
+		t.Fatalf("Expected 1 zombie. Got: %d: %v", len(zombies), zombies)
+	}
+	zp := zombies[0]
+	if zp.Pid != 10 || zp.PPid != myPid || zp.PGrp != 10 || zp.Session != 1 {
+		t.Errorf("Unexpected zombie process information: %s", zp)
+	}
+	if zp.Command != "conman (ssh) x" {
+		t.Errorf("Expected: conman (ssh) x. Got: %s.", zp.Command)
+	}
+	if zp.Found == "" {
+		t.Errorf("Expected the found time to be set")
+	}
+}
+
+func TestFindZombiesReparented(t *testing.T) {
+	myPid := os.Getpid()
+	pm := &ProcessMock{
+		pids:  []int{40},
+		stats: map[int]string{40: "40 (ssh) S 99 40 1 0 -1"},
+	}
+	if zombies := findZombies(pm); len(zombies) != 0 {
+		t.Fatalf("Expected no zombies. Got: %v", zombies)
+	}
+
+	// the parent exited and the orphan was re-parented here before exiting
+	pm.stats[40] = fmt.Sprintf("40 (ssh) Z %d 40 1 0 -1", myPid)
+	zombies := findZombies(pm)
+	if len(zombies) != 1 || zombies[0].PPid != 99 {
+		t.Errorf("Expected the original parent 99. Got: %v", zombies)
+	}
+}
+
+func TestFindZombiesListError(t *testing.T) {
+	pm := &ProcessMock{listErr: os.ErrPermission}
+	if zombies := findZombies(pm); zombies != nil {
+		t.Errorf("Expected no zombies. Got: %v", zombies)
+	}
+}
+
+func TestParseProcStat(t *testing.T) {
+	tests := []struct {
+		stat     string
+		isZombie bool
+		isErr    bool
+	}{
+		{"5 (ipmitool) Z 1 5 5 0 -1", true, false},
+		{"5 (ipmitool) S 1 5 5 0 -1", false, false},
+		{"5 (ipmitool Z 1 5 5", false, true},
+		{"5 ipmitool) Z 1 5 5", false, true},
+		{"5 (ipmitool) Z", false, true},
+		{"5 (ipmitool) Z x 5 5 0", false, true},
+	}
+	for _, tc := range tests {
+		_, isZombie, err := parseProcStat(5, tc.stat)
+		if isZombie != tc.isZombie {
+			t.Errorf("Stat %q expected zombie: %v. Got: %v.", tc.stat, tc.isZombie, isZombie)
+		}
+		if (err != nil) != tc.isErr {
+			t.Errorf("Stat %q expected error: %v. Got: %v.", tc.stat, tc.isErr, err)
+		}
+	}
+}
+
+func TestCleanupZombieAlreadyReaped(t *testing.T) {
+	// something else already waited on the process
+	pm := &ProcessMock{waitErrs: map[int]error{20: syscall.ECHILD}}
+	zombiesInFlight[20] = struct{}{}
+	zombiesInFlight[21] = struct{}{}
+
+	cleanupZombie(pm, zombieProcess{Pid: 20})
+	cleanupZombie(pm, zombieProcess{Pid: 21})
+
+	if len(pm.waited) != 2 {
+		t.Errorf("Expected 2 waits. Got: %d.", len(pm.waited))
+	}
+	history := getZombieHistory()
+	if len(history) < 2 {
+		t.Fatalf("Expected at least 2 history records. Got: %d.", len(history))
+	}
+	reaped := history[len(history)-2]
+	if reaped.Pid != 20 || reaped.Error == "" || reaped.Reaped == "" {
+		t.Errorf("Expected an error recorded for the already reaped process. Got: %+v", reaped)
+	}
+	reaped = history[len(history)-1]
+	if reaped.Pid != 21 || reaped.Error != "" {
+		t.Errorf("Expected a clean reap. Got: %+v", reaped)
+	}
+	if len(zombiesInFlight) != 0 {
+		t.Errorf("Expected no pids in flight. Got: %v", zombiesInFlight)
+	}
+}
+
+func TestReapZombiesDedup(t *testing.T) {
+	myPid := os.Getpid()
+	pm := &ProcessMock{
+		pids:  []int{30},
+		stats: map[int]string{30: fmt.Sprintf("30 (sh) Z %d 30 1 0 -1", myPid)},
+	}
+
+	// a zombie already being waited on is not queued again
+	zombiesInFlight[30] = struct{}{}
+	reapZombies(context.Background(), pm)
+	if len(zombieQueue) != 0 {
+		t.Errorf("Expected no zombies queued. Got: %d.", len(zombieQueue))
+	}
+
+	delete(zombiesInFlight, 30)
+	reapZombies(context.Background(), pm)
+	if len(zombieQueue) != 1 {
+		t.Fatalf("Expected 1 zombie queued. Got: %d.", len(zombieQueue))
+	}
+	zp := <-zombieQueue
+	if zp.Pid != 30 {
+		t.Errorf("Expected: 30. Got: %d.", zp.Pid)
+	}
+	delete(zombiesInFlight, 30)
+}
+
+func TestZombieWorkerStops(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		zombieWorker(ctx, &ProcessMock{})
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Errorf("Expected the zombie worker to stop when cancelled")
+	}
+}